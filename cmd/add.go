@@ -1,9 +1,11 @@
 package cmd
 
 import (
+	"context"
 	"os"
-	"path/filepath"
 
+	"github.com/clouddley/clouddley/internal/authorizedkeys"
+	"github.com/clouddley/clouddley/internal/clouddleykey"
 	"github.com/clouddley/clouddley/internal/log"
 	"github.com/spf13/cobra"
 )
@@ -21,35 +23,70 @@ var keyCmd = &cobra.Command{
 	Use:     "key",
 	Aliases: []string{"k"},
 	Short:   "Add SSH Public Key",
-	Long:    `Add Clouddley's SSH public key to your server's authorized_keys file to enable secure communication between your server and the Clouddley Platform.`,
-	Example: "clouddley add key",
+	Long:    `Add Clouddley's SSH public key to your server's authorized_keys file to enable secure communication between your server and the Clouddley Platform. Running it again is safe: the key is only added once, the previous file is backed up and ~/.ssh permissions are fixed. With --host or --inventory the key is installed on remote hosts over SSH in parallel, followed by a per-host summary.`,
+	Example: `clouddley add key
+clouddley add key --user deploy
+clouddley add key --authorized-keys /srv/git/.ssh/authorized_keys
+clouddley add key --host ubuntu@203.0.113.10 --host deploy@web-2:2222
+clouddley add key --inventory hosts.txt --parallel 16`,
 	Run: func(cmd *cobra.Command, args []string) {
-		sshPublicKey := `ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAACAQCYDppnNM+F+GtFWaVJXsvobX/i/2uZuLch9386ZEyVtGE1QmRjRkvwEHwFM23STuzbtmqTrYjEnmv3Xkywk7wE0r+OoJxTBIwJP+scg9rAu//3N6CoAKH0Ra1XgdRj8QzqF/1mm4T/Pxtzz3JSpSKwpzW3GtU4NcHuaPAAHavCpahCnZqpPMU90FgRCS9lSmw0EPQcU8kxxeEpFjifip4JBBx/WQuh/8KkBAX/DnWSAO9ynGzPMvOvWPTtQMi7IA7Y8vRWeThfpC/fnU8Tub+99w5h2Y1TnWtUrM49ZMa9WSLtP/+4xKieQPObq0JuX6itNFuuwbb/WHLgOYeZqQTdSeMc6GlSkqniYiAUAv7olBUERHf7QkD7hPOlaw9S/0MCU8DcuujZG2i6UvIkQ60dikvsX8rCiPvfN4Nw1mWh0a1rf9vUxTyCCb+7hh1iPV6RwMx6T4nBjFNjBglHFkYIE5kevLyX2vREJJen+GfZO2GVcnHaNRHBvXZVVEbwt1xRWhAOS+FFtcKUNV+54JsKTaZUEYvfwe/KNjEeOxucljkiK9IYw0IGXB9dtueOTKcirLhpGE9t6LqDhWE05kr0fl/hmnT/g9fHeZDm4jOF71iHogsrZtU5pH8QtTNhaffMkW4EJc+4W0a+boE+/S5Xracbr7D1WBhGC2epXkUWHw== "clouddley-triggr-public-key"`
+		if len(keyHostsFlag) > 0 || keyInventoryFlag != "" {
+			runRemoteKeyInstall(cmd.Context())
+			return
+		}
 
-		homeDir, err := os.UserHomeDir()
+		authKeyFile, err := resolveAuthorizedKeysPath(cmd)
 		if err != nil {
-			log.Error("Error getting your home directory", "error", err)
+			log.Error("Error locating authorized_keys file", "error", err)
 			os.Exit(1)
 		}
 
-		authKeyFile := filepath.Join(homeDir, ".ssh", "authorized_keys")
-
-		file, err := os.OpenFile(authKeyFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		result, err := authorizedkeys.Add(authKeyFile, clouddleyKeysToInstall(cmd.Context())...)
 		if err != nil {
-			log.Error("Error opening authorized_keys file", "error", err)
+			log.Error("Error updating authorized_keys file", "error", err)
 			os.Exit(1)
 		}
 
-		defer file.Close()
+		reportKeyResult(result)
+	},
+}
 
-		// Write the public key to the authorized_keys file
-		if _, err := file.WriteString(sshPublicKey + "\n"); err != nil {
-			log.Error("Error writing to authorized_keys file", "error", err)
-			os.Exit(1)
-		}
+// reportKeyResult logs what a change to authorized_keys actually did
+func reportKeyResult(result *authorizedkeys.Result) {
+	for _, fix := range result.FixedPermissions {
+		log.Info("Fixed permissions", "change", fix)
+	}
 
-		log.Info("Clouddley's SSH public key has been added successfully")
-	},
+	if !result.Changed {
+		log.Info("Clouddley's SSH public key is already installed, nothing to do", "file", result.Path)
+		return
+	}
+
+	if result.Backup != "" {
+		log.Info("Previous authorized_keys backed up", "backup", result.Backup)
+	}
+	log.Info("Clouddley's SSH public key has been added successfully", "file", result.Path)
+}
+
+// clouddleyKeysToInstall returns the keys trusted now, or the key published at
+// --key-url when it can be fetched and matches a pinned fingerprint
+func clouddleyKeysToInstall(ctx context.Context) []string {
+	if keyURLFlag == "" {
+		return clouddleykey.PublicKeys()
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	key, err := clouddleykey.Fetch(ctx, keyURLFlag)
+	if err != nil {
+		log.Warn("Could not fetch Clouddley's SSH public key, using the built-in key", "url", keyURLFlag, "error", err)
+		return clouddleykey.PublicKeys()
+	}
+
+	log.Debug("Fetched Clouddley's SSH public key", "url", keyURLFlag)
+	return []string{key}
 }
 
 func init() {
@@ -57,6 +94,17 @@ func init() {
 	addCmd.DisableSuggestions = false
 	addCmd.SuggestionsMinimumDistance = 2
 	
+	addAuthorizedKeysFlags(keyCmd)
+	keyCmd.Flags().StringArrayVar(&keyHostsFlag, "host", nil, "Install the key on a remote host over SSH ([user@]host[:port]); repeatable")
+	keyCmd.Flags().StringVar(&keyInventoryFlag, "inventory", "", "File listing remote hosts, one [user@]host[:port] per line")
+	keyCmd.Flags().IntVar(&keyParallelFlag, "parallel", 8, "Number of remote hosts to install on at once")
+	keyCmd.Flags().StringVarP(&keyIdentityFileFlag, "identity", "i", "", "SSH private key used to connect to remote hosts")
+	keyCmd.MarkFlagsMutuallyExclusive("host", "user")
+	keyCmd.MarkFlagsMutuallyExclusive("host", "authorized-keys")
+	keyCmd.MarkFlagsMutuallyExclusive("inventory", "user")
+	keyCmd.MarkFlagsMutuallyExclusive("inventory", "authorized-keys")
+	keyCmd.Flags().StringVar(&keyURLFlag, "key-url", os.Getenv(clouddleykey.URLEnv), "HTTPS URL to fetch the current Clouddley key from; it must match a pinned fingerprint")
+
 	rootCmd.AddCommand(addCmd)
 	addCmd.AddCommand(keyCmd)
 }
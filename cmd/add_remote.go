@@ -0,0 +1,297 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/clouddley/clouddley/internal/authorizedkeys"
+	"github.com/clouddley/clouddley/internal/log"
+)
+
+// remoteInstallTimeout bounds a single host, including connection setup
+const remoteInstallTimeout = 2 * time.Minute
+
+var (
+	keyHostsFlag        []string
+	keyInventoryFlag    string
+	keyParallelFlag     int
+	keyIdentityFileFlag string
+)
+
+// remoteHost is an SSH target parsed from "[user@]host[:port]"
+type remoteHost struct {
+	User string
+	Host string
+	Port int // 0 uses the ssh default
+}
+
+func (h remoteHost) String() string {
+	target := h.Host
+	if h.User != "" {
+		target = h.User + "@" + target
+	}
+	if h.Port != 0 {
+		target = fmt.Sprintf("%s:%d", target, h.Port)
+	}
+	return target
+}
+
+// remoteResult is the outcome of installing the key on one host
+type remoteResult struct {
+	Host    remoteHost
+	Added   int
+	Present int
+	Err     error
+}
+
+// sshRunner runs a script on a host and returns its combined output; tests replace it
+var sshRunner = runSSHScript
+
+// parseHostSpec parses "[user@]host[:port]", accepting "[v6addr]:port" for IPv6
+func parseHostSpec(spec string) (remoteHost, error) {
+	spec = strings.TrimSpace(spec)
+	var h remoteHost
+
+	if i := strings.LastIndex(spec, "@"); i >= 0 {
+		h.User = spec[:i]
+		spec = spec[i+1:]
+		if h.User == "" {
+			return remoteHost{}, fmt.Errorf("invalid host %q: empty user", spec)
+		}
+	}
+
+	host, port, err := net.SplitHostPort(spec)
+	if err != nil {
+		// No port given; a bare IPv6 address has colons but no brackets
+		host = strings.TrimSuffix(strings.TrimPrefix(spec, "["), "]")
+		port = ""
+	}
+	if host == "" || strings.ContainsAny(host, " \t/") {
+		return remoteHost{}, fmt.Errorf("invalid host %q", spec)
+	}
+	h.Host = host
+
+	if port != "" {
+		p, err := strconv.Atoi(port)
+		if err != nil || p < 1 || p > 65535 {
+			return remoteHost{}, fmt.Errorf("invalid port in %q", spec)
+		}
+		h.Port = p
+	}
+
+	return h, nil
+}
+
+// parseInventory reads one host per line, ignoring blank lines and # comments
+func parseInventory(content string) ([]remoteHost, error) {
+	var hosts []remoteHost
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		host, err := parseHostSpec(strings.Fields(line)[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+		hosts = append(hosts, host)
+	}
+	return hosts, scanner.Err()
+}
+
+// resolveRemoteHosts combines --host and --inventory, dropping duplicates
+func resolveRemoteHosts() ([]remoteHost, error) {
+	var hosts []remoteHost
+	for _, spec := range keyHostsFlag {
+		host, err := parseHostSpec(spec)
+		if err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, host)
+	}
+
+	if keyInventoryFlag != "" {
+		content, err := os.ReadFile(keyInventoryFlag)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read inventory: %w", err)
+		}
+		inventory, err := parseInventory(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("invalid inventory %s: %w", keyInventoryFlag, err)
+		}
+		hosts = append(hosts, inventory...)
+	}
+
+	seen := make(map[remoteHost]bool)
+	var unique []remoteHost
+	for _, host := range hosts {
+		if !seen[host] {
+			seen[host] = true
+			unique = append(unique, host)
+		}
+	}
+	return unique, nil
+}
+
+// installKeyOnHosts runs the install script on every host, at most parallel at a time,
+// and returns the results in the order the hosts were given
+func installKeyOnHosts(ctx context.Context, hosts []remoteHost, script string, parallel int) []remoteResult {
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	results := make([]remoteResult, len(hosts))
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+
+	for i, host := range hosts {
+		wg.Add(1)
+		go func(i int, host remoteHost) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			hostCtx, cancel := context.WithTimeout(ctx, remoteInstallTimeout)
+			defer cancel()
+
+			log.Debug("Installing Clouddley key", "host", host.String())
+			output, err := sshRunner(hostCtx, host, script)
+			results[i] = parseRemoteOutput(host, output, err)
+		}(i, host)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// parseRemoteOutput turns the install script's output into a result
+func parseRemoteOutput(host remoteHost, output string, err error) remoteResult {
+	result := remoteResult{Host: host}
+	if err != nil {
+		result.Err = fmt.Errorf("%w: %s", err, lastLine(output))
+		return result
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		switch strings.TrimSpace(line) {
+		case "KEY_ADDED":
+			result.Added++
+		case "KEY_PRESENT":
+			result.Present++
+		}
+	}
+
+	if result.Added == 0 && result.Present == 0 {
+		result.Err = fmt.Errorf("unexpected output: %s", lastLine(output))
+	}
+	return result
+}
+
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+// runSSHScript pipes the script to "sh -s" on the host, so it runs under a POSIX
+// shell whatever the user's login shell is
+func runSSHScript(ctx context.Context, host remoteHost, script string) (string, error) {
+	args := []string{
+		"-o", "BatchMode=yes",
+		"-o", "StrictHostKeyChecking=accept-new",
+		"-o", "ConnectTimeout=15",
+	}
+	if host.Port != 0 {
+		args = append(args, "-p", strconv.Itoa(host.Port))
+	}
+	if keyIdentityFileFlag != "" {
+		args = append(args, "-i", keyIdentityFileFlag)
+	}
+
+	target := host.Host
+	if host.User != "" {
+		target = host.User + "@" + host.Host
+	}
+	args = append(args, target, "sh -s")
+
+	cmd := exec.CommandContext(ctx, "ssh", args...)
+	cmd.Stdin = strings.NewReader(script)
+
+	output, err := cmd.CombinedOutput()
+	return string(output), err
+}
+
+// runRemoteKeyInstall installs the Clouddley keys on the hosts from --host and
+// --inventory, prints a per-host summary and exits non-zero if any host failed
+func runRemoteKeyInstall(ctx context.Context) {
+	hosts, err := resolveRemoteHosts()
+	if err != nil {
+		log.Error("Error reading hosts", "error", err)
+		os.Exit(1)
+	}
+	if len(hosts) == 0 {
+		log.Error("No hosts given")
+		os.Exit(1)
+	}
+
+	script, err := authorizedkeys.RemoteAddScript(clouddleyKeysToInstall(ctx)...)
+	if err != nil {
+		log.Error("Error preparing install script", "error", err)
+		os.Exit(1)
+	}
+
+	log.Info("Installing Clouddley's SSH public key", "hosts", len(hosts), "parallel", keyParallelFlag)
+	results := installKeyOnHosts(ctx, hosts, script, keyParallelFlag)
+
+	failed := printRemoteSummary(results)
+	if failed > 0 {
+		log.Error("Key installation failed on some hosts", "failed", failed, "total", len(results))
+		os.Exit(1)
+	}
+	log.Info("Clouddley's SSH public key is installed on all hosts", "total", len(results))
+}
+
+// printRemoteSummary prints one line per host and returns the number of failures
+func printRemoteSummary(results []remoteResult) int {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "HOST\tSTATUS\tDETAIL")
+
+	failed := 0
+	for _, result := range results {
+		status, detail := formatRemoteResult(result)
+		if result.Err != nil {
+			failed++
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", result.Host, status, detail)
+	}
+	w.Flush()
+
+	return failed
+}
+
+func formatRemoteResult(result remoteResult) (string, string) {
+	switch {
+	case result.Err != nil:
+		return "✗ failed", result.Err.Error()
+	case result.Added > 0:
+		return "✓ added", fmt.Sprintf("%d key(s) added", result.Added)
+	default:
+		return "✓ unchanged", "already installed"
+	}
+}
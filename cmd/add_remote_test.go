@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseHostSpec(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    remoteHost
+		wantErr bool
+	}{
+		{name: "host only", spec: "web-1", want: remoteHost{Host: "web-1"}},
+		{name: "user and host", spec: "ubuntu@203.0.113.10", want: remoteHost{User: "ubuntu", Host: "203.0.113.10"}},
+		{name: "user host and port", spec: "deploy@web-2:2222", want: remoteHost{User: "deploy", Host: "web-2", Port: 2222}},
+		{name: "bracketed ipv6 with port", spec: "root@[2001:db8::1]:22", want: remoteHost{User: "root", Host: "2001:db8::1", Port: 22}},
+		{name: "bare ipv6", spec: "2001:db8::1", want: remoteHost{Host: "2001:db8::1"}},
+		{name: "empty user", spec: "@web-1", wantErr: true},
+		{name: "empty host", spec: "ubuntu@", wantErr: true},
+		{name: "bad port", spec: "web-1:ssh", wantErr: true},
+		{name: "port out of range", spec: "web-1:70000", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseHostSpec(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseHostSpec(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("parseHostSpec(%q) = %+v, want %+v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseInventory(t *testing.T) {
+	content := `# production
+ubuntu@web-1
+deploy@web-2:2222   # custom port
+
+  db-1 extra-field
+`
+	hosts, err := parseInventory(content)
+	if err != nil {
+		t.Fatalf("parseInventory() error = %v", err)
+	}
+
+	want := []remoteHost{
+		{User: "ubuntu", Host: "web-1"},
+		{User: "deploy", Host: "web-2", Port: 2222},
+		{Host: "db-1"},
+	}
+	if len(hosts) != len(want) {
+		t.Fatalf("parseInventory() = %+v, want %+v", hosts, want)
+	}
+	for i := range want {
+		if hosts[i] != want[i] {
+			t.Errorf("host %d = %+v, want %+v", i, hosts[i], want[i])
+		}
+	}
+
+	if _, err := parseInventory("web-1\nweb-2:bad\n"); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("parseInventory() error = %v, want error mentioning line 2", err)
+	}
+}
+
+func TestParseRemoteOutput(t *testing.T) {
+	host := remoteHost{Host: "web-1"}
+	tests := []struct {
+		name        string
+		output      string
+		err         error
+		wantAdded   int
+		wantPresent int
+		wantErr     bool
+	}{
+		{name: "added", output: "KEY_ADDED\n", wantAdded: 1},
+		{name: "present", output: "KEY_PRESENT\nKEY_PRESENT\n", wantPresent: 2},
+		{name: "mixed", output: "KEY_PRESENT\nKEY_ADDED\n", wantAdded: 1, wantPresent: 1},
+		{name: "ssh failure", output: "Permission denied (publickey).\n", err: errors.New("exit status 255"), wantErr: true},
+		{name: "unexpected output", output: "sh: not found\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseRemoteOutput(host, tt.output, tt.err)
+			if (got.Err != nil) != tt.wantErr {
+				t.Fatalf("Err = %v, wantErr %v", got.Err, tt.wantErr)
+			}
+			if got.Added != tt.wantAdded || got.Present != tt.wantPresent {
+				t.Errorf("Added/Present = %d/%d, want %d/%d", got.Added, got.Present, tt.wantAdded, tt.wantPresent)
+			}
+		})
+	}
+}
+
+func TestInstallKeyOnHosts(t *testing.T) {
+	original := sshRunner
+	defer func() { sshRunner = original }()
+
+	var running, maxRunning int32
+	var mu sync.Mutex
+	seenScripts := map[string]bool{}
+	sshRunner = func(ctx context.Context, host remoteHost, script string) (string, error) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		seenScripts[script] = true
+		mu.Unlock()
+
+		switch host.Host {
+		case "down":
+			return "ssh: connect to host down port 22: Connection refused", errors.New("exit status 255")
+		case "present":
+			return "KEY_PRESENT\n", nil
+		}
+		return "KEY_ADDED\n", nil
+	}
+
+	hosts := []remoteHost{{Host: "a"}, {Host: "down"}, {Host: "present"}, {Host: "b"}, {Host: "c"}}
+	results := installKeyOnHosts(context.Background(), hosts, "script", 2)
+
+	if len(results) != len(hosts) {
+		t.Fatalf("got %d results, want %d", len(results), len(hosts))
+	}
+	for i, result := range results {
+		if result.Host != hosts[i] {
+			t.Errorf("result %d is for %s, want %s", i, result.Host, hosts[i])
+		}
+	}
+	if results[1].Err == nil || !strings.Contains(results[1].Err.Error(), "Connection refused") {
+		t.Errorf("down host error = %v, want connection refused", results[1].Err)
+	}
+	if status, _ := formatRemoteResult(results[2]); status != "✓ unchanged" {
+		t.Errorf("present host status = %q, want unchanged", status)
+	}
+	if status, _ := formatRemoteResult(results[0]); status != "✓ added" {
+		t.Errorf("new host status = %q, want added", status)
+	}
+	if maxRunning > 2 {
+		t.Errorf("ran %d hosts at once, want at most 2", maxRunning)
+	}
+	if !seenScripts["script"] || len(seenScripts) != 1 {
+		t.Errorf("scripts passed to runner = %v", seenScripts)
+	}
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"time"
+
+	"github.com/clouddley/clouddley/internal/authorizedkeys"
+	"github.com/clouddley/clouddley/internal/clouddleykey"
+	"github.com/clouddley/clouddley/internal/log"
+	"github.com/spf13/cobra"
+)
+
+var keyURLFlag string
+
+// clouddleyKeyCmd represents the key command
+var clouddleyKeyCmd = &cobra.Command{
+	Use:     "key",
+	Short:   "Inspect the Clouddley SSH public key on this server",
+	Long:    `Use this command to check whether Clouddley's SSH public key is installed on this server.`,
+	Example: "clouddley key status",
+}
+
+// keyStatusCmd represents the key status command
+var keyStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether the Clouddley SSH public key is installed",
+	Long:  `Report whether Clouddley's SSH public key is in authorized_keys, its fingerprint, and permission problems that would stop sshd from accepting it.`,
+	Example: `clouddley key status
+clouddley key status --user deploy`,
+	Run: func(cmd *cobra.Command, args []string) {
+		authKeyFile, err := resolveAuthorizedKeysPath(cmd)
+		if err != nil {
+			log.Error("Error locating authorized_keys file", "error", err)
+			os.Exit(1)
+		}
+
+		active := clouddleykey.Active(time.Now())
+		var fingerprints []string
+		for _, key := range active {
+			fingerprints = append(fingerprints, key.Fingerprint())
+		}
+
+		status, err := authorizedkeys.Inspect(authKeyFile, fingerprints...)
+		if err != nil {
+			log.Error("Error inspecting authorized_keys file", "error", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("File:        %s\n", status.Path)
+		fmt.Printf("Installed:   %s\n", formatInstalled(status, len(fingerprints)))
+		for _, key := range active {
+			fmt.Printf("Fingerprint: %s%s\n", key.Fingerprint(), formatValidity(key))
+		}
+
+		for _, problem := range status.Problems {
+			log.Warn("Permission problem", "detail", problem)
+		}
+		if len(status.Problems) > 0 {
+			log.Warn("Run 'clouddley add key' to fix permissions")
+		}
+	},
+}
+
+// addAuthorizedKeysFlags adds the flags selecting which authorized_keys file to use
+func addAuthorizedKeysFlags(c *cobra.Command) {
+	c.Flags().StringP("user", "u", "", "Use the authorized_keys file of this user instead of the current one")
+	c.Flags().String("authorized-keys", "", "Path to the authorized_keys file to use")
+	c.MarkFlagsMutuallyExclusive("user", "authorized-keys")
+}
+
+// resolveAuthorizedKeysPath returns the authorized_keys file selected by --user or
+// --authorized-keys on cmd, defaulting to the current user's
+func resolveAuthorizedKeysPath(cmd *cobra.Command) (string, error) {
+	userName, _ := cmd.Flags().GetString("user")
+	authorizedKeys, _ := cmd.Flags().GetString("authorized-keys")
+
+	if authorizedKeys != "" {
+		return filepath.Abs(authorizedKeys)
+	}
+
+	var homeDir string
+	if userName != "" {
+		u, err := user.Lookup(userName)
+		if err != nil {
+			return "", fmt.Errorf("failed to look up user %s: %w", userName, err)
+		}
+		homeDir = u.HomeDir
+	} else {
+		dir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		homeDir = dir
+	}
+
+	return filepath.Join(homeDir, ".ssh", "authorized_keys"), nil
+}
+
+func formatInstalled(status *authorizedkeys.Status, trusted int) string {
+	switch {
+	case !status.Exists:
+		return "no (file does not exist)"
+	case len(status.Installed) == 0:
+		return "no"
+	case len(status.Installed) < trusted:
+		return fmt.Sprintf("partially (%d of %d current keys)", len(status.Installed), trusted)
+	default:
+		return "yes"
+	}
+}
+
+// formatValidity describes the rotation window of a key, if it has one
+func formatValidity(key clouddleykey.Key) string {
+	if key.NotAfter.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf(" (trusted until %s)", key.NotAfter.Format("2006-01-02"))
+}
+
+func init() {
+	addAuthorizedKeysFlags(keyStatusCmd)
+
+	rootCmd.AddCommand(clouddleyKeyCmd)
+	clouddleyKeyCmd.AddCommand(keyStatusCmd)
+}
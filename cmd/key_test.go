@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/clouddley/clouddley/internal/authorizedkeys"
+	"github.com/clouddley/clouddley/internal/clouddleykey"
+	"github.com/spf13/cobra"
+)
+
+func TestAddAndRemoveKey(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+
+	authKeyFile := filepath.Join(tempDir, ".ssh", "authorized_keys")
+	otherKey := "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAICmyl5Coa4PmyE2iaauW/gTEXsGHXdExi8Ij0Ak89xnf alice"
+
+	if err := os.MkdirAll(filepath.Dir(authKeyFile), 0700); err != nil {
+		t.Fatalf("Error creating .ssh directory: %v", err)
+	}
+	if err := os.WriteFile(authKeyFile, []byte("# team keys\n"+otherKey+"\n"), 0600); err != nil {
+		t.Fatalf("Error writing authorized_keys file: %v", err)
+	}
+
+	// Running add twice must not duplicate the key
+	keyCmd.Run(keyCmd, nil)
+	keyCmd.Run(keyCmd, nil)
+
+	content, err := os.ReadFile(authKeyFile)
+	if err != nil {
+		t.Fatalf("Error reading authorized_keys file: %v", err)
+	}
+	if count := strings.Count(string(content), "clouddley-triggr-public-key"); count != 1 {
+		t.Fatalf("Expected the Clouddley key once, found %d times", count)
+	}
+
+	status, err := authorizedkeys.Inspect(authKeyFile, clouddleykey.Fingerprints()...)
+	if err != nil {
+		t.Fatalf("Error inspecting authorized_keys file: %v", err)
+	}
+	if len(status.Installed) == 0 || len(status.Problems) != 0 {
+		t.Fatalf("Expected key installed without problems, got %+v", status)
+	}
+
+	removeKeyCmd.Run(removeKeyCmd, nil)
+
+	content, err = os.ReadFile(authKeyFile)
+	if err != nil {
+		t.Fatalf("Error reading authorized_keys file: %v", err)
+	}
+	if expected := "# team keys\n" + otherKey + "\n"; string(content) != expected {
+		t.Fatalf("Expected other entries to be preserved, got:\n%s", content)
+	}
+}
+
+func TestResolveAuthorizedKeysPath(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+
+	newCmd := func(args ...string) *cobra.Command {
+		c := &cobra.Command{Use: "test"}
+		addAuthorizedKeysFlags(c)
+		if err := c.ParseFlags(args); err != nil {
+			t.Fatalf("Error parsing flags: %v", err)
+		}
+		return c
+	}
+
+	path, err := resolveAuthorizedKeysPath(newCmd())
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if expected := filepath.Join(tempDir, ".ssh", "authorized_keys"); path != expected {
+		t.Errorf("Expected %s, got %s", expected, path)
+	}
+
+	expected := "/srv/git/.ssh/authorized_keys"
+	path, err = resolveAuthorizedKeysPath(newCmd("--authorized-keys", expected))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if path != expected {
+		t.Errorf("Expected %s, got %s", expected, path)
+	}
+
+	if _, err := resolveAuthorizedKeysPath(newCmd("--user", "clouddley-no-such-user")); err == nil {
+		t.Error("Expected error for unknown user")
+	}
+}
+
+func TestAuthorizedKeysFlagsArePerCommand(t *testing.T) {
+	if err := keyStatusCmd.Flags().Set("authorized-keys", "/tmp/status_keys"); err != nil {
+		t.Fatal(err)
+	}
+	defer keyStatusCmd.Flags().Set("authorized-keys", "")
+
+	if value, _ := removeKeyCmd.Flags().GetString("authorized-keys"); value != "" {
+		t.Errorf("Expected remove key to keep its own --authorized-keys, got %q", value)
+	}
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"os"
+
+	awsinternal "github.com/clouddley/clouddley/internal/aws"
+	"github.com/clouddley/clouddley/internal/log"
+	"github.com/spf13/cobra"
+)
+
+// pricingCmd represents the pricing command
+var pricingCmd = &cobra.Command{
+	Use:     "pricing",
+	Short:   "Inspect cloud pricing data",
+	Long:    `Use this command to inspect and manage the pricing data the CLI uses to estimate costs.`,
+	Example: "clouddley pricing cache clear",
+}
+
+// pricingCacheCmd represents the pricing cache command
+var pricingCacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the local pricing cache",
+	Long: `Prices fetched from the AWS Pricing API are cached under your user cache directory
+so commands like 'clouddley vm aws create' do not query AWS every time. Entries expire
+after 7 days; set CLOUDDLEY_PRICING_TTL (e.g. 12h) to change that.`,
+	Example: "clouddley pricing cache clear",
+}
+
+// pricingCacheClearCmd represents the pricing cache clear command
+var pricingCacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete all cached prices",
+	Long:  `Delete the local pricing cache so the next command fetches current prices from AWS.`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := awsinternal.PricingCachePath()
+		if err != nil {
+			log.Error("Error locating pricing cache", "error", err)
+			os.Exit(1)
+		}
+
+		cache := &awsinternal.PricingCache{Path: path}
+		removed, err := cache.Clear()
+		if err != nil {
+			log.Error("Error clearing pricing cache", "error", err)
+			os.Exit(1)
+		}
+
+		if removed == 0 {
+			log.Info("Pricing cache is already empty", "file", path)
+			return
+		}
+		log.Info("Pricing cache cleared", "file", path, "entries", removed)
+	},
+}
+
+// pricingSnapshotCmd regenerates the price snapshot embedded in the binary. It is
+// run by maintainers through go generate, so it is hidden from help
+var pricingSnapshotCmd = &cobra.Command{
+	Use:    "snapshot",
+	Short:  "Generate the offline pricing snapshot",
+	Long:   `Fetch current prices from the AWS Pricing API for the regions and instance types in the offline snapshot and write them as JSON. Run 'go generate ./internal/aws' to refresh the snapshot compiled into the CLI.`,
+	Hidden: true,
+	Args:   cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		snapshot, err := awsinternal.GeneratePricingSnapshot(cmd.Context(), awsinternal.DefaultPricingParallelism)
+		if err != nil {
+			log.Error("Error generating pricing snapshot", "error", err)
+			os.Exit(1)
+		}
+
+		content, err := snapshot.MarshalIndent()
+		if err != nil {
+			log.Error("Error encoding pricing snapshot", "error", err)
+			os.Exit(1)
+		}
+
+		if pricingSnapshotOutput == "" || pricingSnapshotOutput == "-" {
+			os.Stdout.Write(content)
+			return
+		}
+		if err := os.WriteFile(pricingSnapshotOutput, content, 0o644); err != nil {
+			log.Error("Error writing pricing snapshot", "error", err)
+			os.Exit(1)
+		}
+		log.Info("Pricing snapshot written", "file", pricingSnapshotOutput, "date", snapshot.GeneratedAt)
+	},
+}
+
+var pricingSnapshotOutput string
+
+func init() {
+	// Enable command suggestions for misspelled commands
+	pricingCmd.DisableSuggestions = false
+	pricingCmd.SuggestionsMinimumDistance = 2
+
+	rootCmd.AddCommand(pricingCmd)
+	pricingCmd.AddCommand(pricingCacheCmd)
+	pricingCacheCmd.AddCommand(pricingCacheClearCmd)
+
+	pricingSnapshotCmd.Flags().StringVarP(&pricingSnapshotOutput, "output", "o", "", "File to write the snapshot to (defaults to stdout)")
+	pricingCmd.AddCommand(pricingSnapshotCmd)
+}
@@ -0,0 +1,263 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	awsinternal "github.com/clouddley/clouddley/internal/aws"
+	"github.com/clouddley/clouddley/internal/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pricingTypesFlag      []string
+	pricingRegionsFlag    []string
+	pricingOutputFlag     string
+	pricingVolumeTypeFlag string
+	pricingVolumeSizeFlag int32
+	pricingRefreshFlag    bool
+	pricingHoursFlag      float64
+)
+
+// priceRate is a price per hour and the same price over a month
+type priceRate struct {
+	Hourly  float64 `json:"hourly"`
+	Monthly float64 `json:"monthly"`
+}
+
+// storageCost is the monthly cost of the root volume priced with an instance
+type storageCost struct {
+	Volume  string  `json:"volume"`
+	Monthly float64 `json:"monthly"`
+}
+
+// ec2PriceRow holds every rate of one instance type in one region. Rates that could
+// not be fetched are nil and the reason is listed in Errors
+type ec2PriceRow struct {
+	Region         string       `json:"region"`
+	InstanceType   string       `json:"instance_type"`
+	OnDemand       *priceRate   `json:"on_demand"`
+	Spot           *priceRate   `json:"spot"`
+	Reserved1Yr    *priceRate   `json:"reserved_1yr"`
+	Reserved3Yr    *priceRate   `json:"reserved_3yr"`
+	SavingsPlan1Yr *priceRate   `json:"savings_plan_1yr"`
+	SavingsPlan3Yr *priceRate   `json:"savings_plan_3yr"`
+	Storage        *storageCost `json:"storage"`
+	Estimated      bool         `json:"estimated,omitempty"`
+	Errors         []string     `json:"errors,omitempty"`
+}
+
+// pricingEC2Cmd represents the pricing ec2 command
+var pricingEC2Cmd = &cobra.Command{
+	Use:   "ec2",
+	Short: "Compare EC2 instance prices",
+	Long: `Compare the hourly and monthly cost of EC2 instance types across regions.
+
+For each type and region this shows Linux on-demand, current spot, 1-year and
+3-year standard reserved instances (no upfront), 1-year and 3-year Compute
+Savings Plans (no upfront) and the monthly cost of the root volume.`,
+	Example: `clouddley pricing ec2 --type m5.large,c5.large
+clouddley pricing ec2 --type t3.micro --region us-east-1,eu-west-1
+clouddley pricing ec2 --type m5.large --volume-type gp2 --volume-size 50 -o json
+clouddley pricing ec2 --type t3.large --hours-per-month 220`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if pricingOutputFlag != "table" && pricingOutputFlag != "json" {
+			log.Error("Invalid output format, use table or json", "output", pricingOutputFlag)
+			os.Exit(1)
+		}
+
+		volume := awsinternal.EBSVolume{Type: pricingVolumeTypeFlag, SizeGB: pricingVolumeSizeFlag}
+		if err := volume.Validate(); err != nil {
+			log.Error("Invalid volume", "error", err)
+			os.Exit(1)
+		}
+
+		if err := awsinternal.ConfigurePricingCache(0, pricingRefreshFlag); err != nil {
+			log.Error("Error configuring pricing cache", "error", err)
+			os.Exit(1)
+		}
+		if err := awsinternal.ConfigureHoursPerMonth(pricingHoursFlag); err != nil {
+			log.Error("Invalid hours per month", "error", err)
+			os.Exit(1)
+		}
+
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		rows, err := fetchEC2PriceRows(ctx, pricingTypesFlag, pricingRegionsFlag, volume)
+		if err != nil {
+			log.Error("Error fetching prices", "error", err)
+			os.Exit(1)
+		}
+
+		if pricingOutputFlag == "json" {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(rows); err != nil {
+				log.Error("Error encoding prices", "error", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		printEC2PriceTable(os.Stdout, rows, volume)
+		for _, row := range rows {
+			for _, problem := range row.Errors {
+				log.Debug("Price unavailable", "region", row.Region, "type", row.InstanceType, "error", problem)
+			}
+		}
+	},
+}
+
+// fetchEC2PriceRows prices every instance type in every region, defaulting to the
+// profile's region, with a bounded number of requests in flight
+func fetchEC2PriceRows(ctx context.Context, instanceTypes, regions []string, volume awsinternal.EBSVolume) ([]ec2PriceRow, error) {
+	pricer, err := awsinternal.NewPricer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(regions) == 0 {
+		regions = []string{pricer.Region}
+	}
+
+	ec2Clients := make(map[string]*ec2.Client)
+	for _, region := range regions {
+		if ec2Clients[region], err = awsinternal.GetEC2ClientForRegion(ctx, region); err != nil {
+			return nil, err
+		}
+	}
+
+	var rows []ec2PriceRow
+	for _, region := range regions {
+		for _, instanceType := range instanceTypes {
+			rows = append(rows, ec2PriceRow{Region: region, InstanceType: instanceType})
+		}
+	}
+
+	awsinternal.ForEachLimited(len(rows), awsinternal.DefaultPricingParallelism, func(i int) {
+		row := &rows[i]
+		fillEC2PriceRow(ctx, pricer.ForRegion(row.Region), ec2Clients[row.Region], row, volume)
+	})
+	return rows, nil
+}
+
+// fillEC2PriceRow fetches each rate independently so one missing rate does not hide the rest
+func fillEC2PriceRow(ctx context.Context, pricer *awsinternal.Pricer, ec2Client *ec2.Client, row *ec2PriceRow, volume awsinternal.EBSVolume) {
+	fail := func(rate string, err error) {
+		row.Errors = append(row.Errors, fmt.Sprintf("%s: %v", rate, err))
+	}
+
+	if info, err := pricer.InstancePricing(ctx, row.InstanceType, volume); err != nil {
+		fail("on-demand", err)
+	} else {
+		row.OnDemand = usageRate(info.HourlyPrice)
+		row.Storage = &storageCost{Volume: volume.String(), Monthly: info.EBSPrice}
+		row.Estimated = info.Estimated
+	}
+
+	if spot, err := awsinternal.GetSpotPriceWithClient(ctx, ec2Client, row.InstanceType); err != nil {
+		fail("spot", err)
+	} else {
+		row.Spot = usageRate(spot.HourlyPrice)
+	}
+
+	for lease, target := range map[string]**priceRate{"1yr": &row.Reserved1Yr, "3yr": &row.Reserved3Yr} {
+		if price, err := pricer.ReservedPricing(ctx, row.InstanceType, lease); err != nil {
+			fail("reserved "+lease, err)
+		} else {
+			*target = commitmentRate(price)
+		}
+	}
+
+	if rates, err := pricer.SavingsPlanRates(ctx, row.InstanceType); err != nil {
+		fail("savings plan", err)
+	} else {
+		if price, ok := rates[awsinternal.SavingsPlanTerm1Year]; ok {
+			row.SavingsPlan1Yr = commitmentRate(price)
+		}
+		if price, ok := rates[awsinternal.SavingsPlanTerm3Year]; ok {
+			row.SavingsPlan3Yr = commitmentRate(price)
+		}
+	}
+}
+
+// usageRate prices an on-demand or spot rate, which is billed only for the hours an
+// instance runs
+func usageRate(hourly float64) *priceRate {
+	return &priceRate{Hourly: hourly, Monthly: hourly * awsinternal.BillingHoursPerMonth()}
+}
+
+// commitmentRate prices a reserved or savings plan rate, which is billed for every
+// hour of the term whether the instance runs or not
+func commitmentRate(hourly float64) *priceRate {
+	return &priceRate{Hourly: hourly, Monthly: hourly * awsinternal.HoursPerMonth}
+}
+
+// printEC2PriceTable writes one line per region and instance type
+func printEC2PriceTable(out io.Writer, rows []ec2PriceRow, volume awsinternal.EBSVolume) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "REGION\tTYPE\tON-DEMAND\tSPOT\tRESERVED 1YR\tRESERVED 3YR\tSAVINGS PLAN 1YR\tSAVINGS PLAN 3YR\tSTORAGE")
+
+	estimated := false
+	for _, row := range rows {
+		storage := "unavailable"
+		if row.Storage != nil {
+			storage = fmt.Sprintf("$%.2f/mo", row.Storage.Monthly)
+		}
+		onDemand := formatRate(row.OnDemand)
+		if row.Estimated {
+			estimated = true
+			onDemand = "~" + onDemand
+			storage = "~" + storage
+		}
+
+		fmt.Fprintln(w, strings.Join([]string{
+			row.Region,
+			row.InstanceType,
+			onDemand,
+			formatRate(row.Spot),
+			formatRate(row.Reserved1Yr),
+			formatRate(row.Reserved3Yr),
+			formatRate(row.SavingsPlan1Yr),
+			formatRate(row.SavingsPlan3Yr),
+			storage,
+		}, "\t"))
+	}
+	w.Flush()
+
+	fmt.Fprintf(out, "\nMonthly on-demand and spot prices assume %g hours; reserved and savings plan rates are no upfront and billed for all %g hours of the month. Storage is a %s root volume.\n",
+		awsinternal.BillingHoursPerMonth(), awsinternal.HoursPerMonth, volume)
+	if estimated {
+		fmt.Fprintf(out, "~ Estimated from the %s price snapshot; live pricing was unavailable.\n", awsinternal.EmbeddedPricingSnapshot().GeneratedAt)
+	}
+}
+
+// formatRate shows a rate as "$0.0960/h ($70.08/mo)"
+func formatRate(rate *priceRate) string {
+	if rate == nil {
+		return "unavailable"
+	}
+	return fmt.Sprintf("$%.4f/h ($%.2f/mo)", rate.Hourly, rate.Monthly)
+}
+
+func init() {
+	pricingEC2Cmd.Flags().StringSliceVarP(&pricingTypesFlag, "type", "t", nil, "Instance types to price, comma separated or repeated")
+	pricingEC2Cmd.Flags().StringSliceVarP(&pricingRegionsFlag, "region", "r", nil, "Regions to price in (defaults to your AWS profile's region)")
+	pricingEC2Cmd.Flags().StringVarP(&pricingOutputFlag, "output", "o", "table", "Output format (table|json)")
+	pricingEC2Cmd.Flags().StringVar(&pricingVolumeTypeFlag, "volume-type", awsinternal.DefaultEBSVolume.Type, "Root volume type used for the storage cost")
+	pricingEC2Cmd.Flags().Int32Var(&pricingVolumeSizeFlag, "volume-size", awsinternal.DefaultEBSVolume.SizeGB, "Root volume size in GB used for the storage cost")
+	pricingEC2Cmd.Flags().Float64Var(&pricingHoursFlag, "hours-per-month", 0, "Hours a month instances run, for monthly prices (defaults to $CLOUDDLEY_HOURS_PER_MONTH or a full month)")
+	pricingEC2Cmd.Flags().BoolVar(&pricingRefreshFlag, "refresh-pricing", false, "Fetch prices from AWS instead of the local pricing cache")
+	pricingEC2Cmd.MarkFlagRequired("type")
+
+	pricingCmd.AddCommand(pricingEC2Cmd)
+}
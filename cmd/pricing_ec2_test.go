@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	awsinternal "github.com/clouddley/clouddley/internal/aws"
+)
+
+func TestFormatRate(t *testing.T) {
+	tests := []struct {
+		name string
+		rate *priceRate
+		want string
+	}{
+		{name: "unavailable", rate: nil, want: "unavailable"},
+		{name: "hourly and monthly", rate: &priceRate{Hourly: 0.096, Monthly: 70.13}, want: "$0.0960/h ($70.13/mo)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatRate(tt.rate); got != tt.want {
+				t.Errorf("formatRate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUsageRate(t *testing.T) {
+	rate := usageRate(0.1)
+	if rate.Hourly != 0.1 || rate.Monthly != 0.1*awsinternal.HoursPerMonth {
+		t.Errorf("usageRate(0.1) = %+v", rate)
+	}
+}
+
+func TestCommitmentRate_IgnoresBillingHours(t *testing.T) {
+	if err := awsinternal.ConfigureHoursPerMonth(220); err != nil {
+		t.Fatalf("ConfigureHoursPerMonth() error = %v", err)
+	}
+	t.Cleanup(func() { awsinternal.ConfigureHoursPerMonth(awsinternal.HoursPerMonth) })
+
+	if rate := usageRate(0.1); rate.Monthly != 0.1*220 {
+		t.Errorf("usageRate(0.1) = %+v, want 220 billed hours", rate)
+	}
+	// Reserved instances and savings plans are billed for every hour of the term
+	if rate := commitmentRate(0.1); rate.Monthly != 0.1*awsinternal.HoursPerMonth {
+		t.Errorf("commitmentRate(0.1) = %+v, want a full month", rate)
+	}
+}
+
+func TestPrintEC2PriceTable(t *testing.T) {
+	rows := []ec2PriceRow{
+		{
+			Region:       "us-east-1",
+			InstanceType: "m5.large",
+			OnDemand:     usageRate(0.096),
+			Spot:         usageRate(0.04),
+			Reserved1Yr:  commitmentRate(0.06),
+			Storage:      &storageCost{Volume: "100 GB gp3", Monthly: 8},
+		},
+		{
+			Region:       "eu-west-1",
+			InstanceType: "m5.large",
+			OnDemand:     usageRate(0.107),
+			Storage:      &storageCost{Volume: "100 GB gp3", Monthly: 8.8},
+			Estimated:    true,
+		},
+	}
+
+	var out bytes.Buffer
+	printEC2PriceTable(&out, rows, awsinternal.DefaultEBSVolume)
+	lines := strings.Split(out.String(), "\n")
+
+	for _, want := range []string{"REGION", "ON-DEMAND", "SAVINGS PLAN 3YR", "STORAGE"} {
+		if !strings.Contains(lines[0], want) {
+			t.Errorf("header %q missing %s", lines[0], want)
+		}
+	}
+	if !strings.Contains(lines[1], "$0.0960/h") || !strings.Contains(lines[1], "$8.00/mo") || strings.Contains(lines[1], "~") {
+		t.Errorf("live row = %q", lines[1])
+	}
+	if !strings.Contains(lines[2], "~$0.1070/h") || !strings.Contains(lines[2], "~$8.80/mo") || !strings.Contains(lines[2], "unavailable") {
+		t.Errorf("estimated row = %q", lines[2])
+	}
+	if !strings.Contains(out.String(), "~ Estimated from the") {
+		t.Error("estimated note missing")
+	}
+}
+
+func TestEC2PriceRowJSON(t *testing.T) {
+	row := ec2PriceRow{Region: "us-east-1", InstanceType: "t3.micro", OnDemand: usageRate(0.0104), Errors: []string{"spot: denied"}}
+	content, err := json.Marshal(row)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+
+	var decoded map[string]any
+	if err := json.Unmarshal(content, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if decoded["spot"] != nil {
+		t.Errorf("spot = %v, want null", decoded["spot"])
+	}
+	if _, ok := decoded["reserved_3yr"]; !ok {
+		t.Error("unavailable rates should be present as null")
+	}
+	if onDemand, ok := decoded["on_demand"].(map[string]any); !ok || onDemand["hourly"] != 0.0104 {
+		t.Errorf("on_demand = %v", decoded["on_demand"])
+	}
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/clouddley/clouddley/internal/authorizedkeys"
+	"github.com/clouddley/clouddley/internal/clouddleykey"
+	"github.com/clouddley/clouddley/internal/log"
+	"github.com/spf13/cobra"
+)
+
+// removeCmd represents the remove command
+var removeCmd = &cobra.Command{
+	Use:     "remove",
+	Aliases: []string{"rm"},
+	Short:   "Remove resources from your system",
+	Long:    `Use this command to remove resources previously added to your system.`,
+	Example: "clouddley remove key",
+}
+
+// removeKeyCmd represents the remove key command
+var removeKeyCmd = &cobra.Command{
+	Use:     "key",
+	Aliases: []string{"k"},
+	Short:   "Remove SSH Public Key",
+	Long:    `Remove Clouddley's SSH public key from your server's authorized_keys file to revoke the Clouddley Platform's access. Other keys and comments are kept and the previous file is backed up.`,
+	Example: `clouddley remove key
+clouddley remove key --user deploy`,
+	Run: func(cmd *cobra.Command, args []string) {
+		authKeyFile, err := resolveAuthorizedKeysPath(cmd)
+		if err != nil {
+			log.Error("Error locating authorized_keys file", "error", err)
+			os.Exit(1)
+		}
+
+		// Remove rotated-out keys too so no Clouddley access is left behind
+		result, err := authorizedkeys.Remove(authKeyFile, clouddleykey.Fingerprints()...)
+		if err != nil {
+			log.Error("Error updating authorized_keys file", "error", err)
+			os.Exit(1)
+		}
+
+		if !result.Changed {
+			log.Info("Clouddley's SSH public key is not installed, nothing to do", "file", result.Path)
+			return
+		}
+
+		log.Info("Previous authorized_keys backed up", "backup", result.Backup)
+		log.Info("Clouddley's SSH public key has been removed successfully", "file", result.Path, "entries", result.Removed)
+	},
+}
+
+func init() {
+	// Enable command suggestions for misspelled commands
+	removeCmd.DisableSuggestions = false
+	removeCmd.SuggestionsMinimumDistance = 2
+
+	addAuthorizedKeysFlags(removeKeyCmd)
+
+	rootCmd.AddCommand(removeCmd)
+	removeCmd.AddCommand(removeKeyCmd)
+}
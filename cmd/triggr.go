@@ -4,6 +4,8 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/clouddley/clouddley/internal/authorizedkeys"
+	"github.com/clouddley/clouddley/internal/clouddleykey"
 	"github.com/clouddley/clouddley/internal/log"
 	"github.com/spf13/cobra"
 )
@@ -27,8 +29,6 @@ var installCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		log.Warn("DEPRECATION WARNING: 'clouddley triggr install' is deprecated. Please use 'clouddley add key' instead.")
 		
-		sshPublicKey := `ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAACAQCYDppnNM+F+GtFWaVJXsvobX/i/2uZuLch9386ZEyVtGE1QmRjRkvwEHwFM23STuzbtmqTrYjEnmv3Xkywk7wE0r+OoJxTBIwJP+scg9rAu//3N6CoAKH0Ra1XgdRj8QzqF/1mm4T/Pxtzz3JSpSKwpzW3GtU4NcHuaPAAHavCpahCnZqpPMU90FgRCS9lSmw0EPQcU8kxxeEpFjifip4JBBx/WQuh/8KkBAX/DnWSAO9ynGzPMvOvWPTtQMi7IA7Y8vRWeThfpC/fnU8Tub+99w5h2Y1TnWtUrM49ZMa9WSLtP/+4xKieQPObq0JuX6itNFuuwbb/WHLgOYeZqQTdSeMc6GlSkqniYiAUAv7olBUERHf7QkD7hPOlaw9S/0MCU8DcuujZG2i6UvIkQ60dikvsX8rCiPvfN4Nw1mWh0a1rf9vUxTyCCb+7hh1iPV6RwMx6T4nBjFNjBglHFkYIE5kevLyX2vREJJen+GfZO2GVcnHaNRHBvXZVVEbwt1xRWhAOS+FFtcKUNV+54JsKTaZUEYvfwe/KNjEeOxucljkiK9IYw0IGXB9dtueOTKcirLhpGE9t6LqDhWE05kr0fl/hmnT/g9fHeZDm4jOF71iHogsrZtU5pH8QtTNhaffMkW4EJc+4W0a+boE+/S5Xracbr7D1WBhGC2epXkUWHw== "clouddley-triggr-public-key"`
-
 		homeDir, err := os.UserHomeDir()
 		if err != nil {
 			log.Error("Error getting your home directory", "error", err)
@@ -37,21 +37,13 @@ var installCmd = &cobra.Command{
 
 		authKeyFile := filepath.Join(homeDir, ".ssh", "authorized_keys")
 
-		file, err := os.OpenFile(authKeyFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		result, err := authorizedkeys.Add(authKeyFile, clouddleykey.PublicKeys()...)
 		if err != nil {
-			log.Error("Error opening authorized_keys file", "error", err)
-			os.Exit(1)
-		}
-
-		defer file.Close()
-
-		// Write the public key to the authorized_keys file
-		if _, err := file.WriteString(sshPublicKey + "\n"); err != nil {
-			log.Error("Error writing to authorized_keys file", "error", err)
+			log.Error("Error updating authorized_keys file", "error", err)
 			os.Exit(1)
 		}
 
-		log.Info("Triggr's SSH public key has been installed successfully")
+		reportKeyResult(result)
 	},
 }
 
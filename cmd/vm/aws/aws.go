@@ -13,7 +13,12 @@ var AwsCmd = &cobra.Command{
   clouddley vm aws list      # List AWS instances
   clouddley vm aws start --id i-1234567890abcdef0
   clouddley vm aws stop --id i-1234567890abcdef0
-  clouddley vm aws delete --id i-1234567890abcdef0`,
+  clouddley vm aws delete --id i-1234567890abcdef0
+  clouddley vm aws network init
+  clouddley vm aws firewall list
+  clouddley vm aws ip attach --id i-1234567890abcdef0
+  clouddley vm aws keys list
+  clouddley vm aws budget set --monthly 500`,
 }
 
 func init() {
@@ -27,4 +32,9 @@ func init() {
 	AwsCmd.AddCommand(startCmd)
 	AwsCmd.AddCommand(stopCmd)
 	AwsCmd.AddCommand(deleteCmd)
+	AwsCmd.AddCommand(networkCmd)
+	AwsCmd.AddCommand(firewallCmd)
+	AwsCmd.AddCommand(ipCmd)
+	AwsCmd.AddCommand(keysCmd)
+	AwsCmd.AddCommand(budgetCmd)
 }
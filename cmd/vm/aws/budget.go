@@ -0,0 +1,303 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	tea "github.com/charmbracelet/bubbletea"
+	awsinternal "github.com/clouddley/clouddley/internal/aws"
+	"github.com/clouddley/clouddley/internal/log"
+	"github.com/clouddley/clouddley/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var budgetCmd = &cobra.Command{
+	Use:   "budget",
+	Short: "Manage cost limits for new instances",
+	Long: `Set a monthly budget and a per-instance cost limit for a context, the AWS
+profile and region instances are launched in (e.g. corp/us-east-1).
+
+Before launching, 'create' adds the monthly cost of the selected instance to the
+running cost of your Clouddley instances in the context. When a limit would be
+exceeded it asks for confirmation, or refuses with --action refuse.`,
+	Example: `  clouddley vm aws budget set --monthly 500 --per-instance 150
+  clouddley vm aws budget set --context prod/eu-west-1 --monthly 2000 --action refuse
+  clouddley vm aws budget show
+  clouddley vm aws budget clear`,
+}
+
+var budgetSetCmd = &cobra.Command{
+	Use:   "set [--context <profile/region>] [--monthly <usd>] [--per-instance <usd>] [--action confirm|refuse]",
+	Short: "Set the budget of a context",
+	Long:  `Set the budget of a context, keeping limits that are not passed. A limit of 0 removes it, and removing both limits removes the budget.`,
+	Args:  cobra.NoArgs,
+	Run:   runBudgetSet,
+}
+
+var budgetShowCmd = &cobra.Command{
+	Use:     "show",
+	Aliases: []string{"list", "ls"},
+	Short:   "Show configured budgets",
+	Args:    cobra.NoArgs,
+	Run:     runBudgetShow,
+}
+
+var budgetClearCmd = &cobra.Command{
+	Use:   "clear [--context <profile/region>]",
+	Short: "Remove the budget of a context",
+	Args:  cobra.NoArgs,
+	Run:   runBudgetClear,
+}
+
+func init() {
+	budgetSetCmd.Flags().String("context", "", "Context as profile/region (defaults to the current AWS profile and region)")
+	budgetSetCmd.Flags().Float64("monthly", 0, "Monthly budget in USD for all Clouddley instances in the context")
+	budgetSetCmd.Flags().Float64("per-instance", 0, "Maximum monthly cost in USD of a single new instance")
+	budgetSetCmd.Flags().String("action", awsinternal.BudgetActionConfirm, "What create does over budget (confirm|refuse)")
+
+	budgetClearCmd.Flags().String("context", "", "Context as profile/region (defaults to the current AWS profile and region)")
+
+	budgetCmd.AddCommand(budgetSetCmd)
+	budgetCmd.AddCommand(budgetShowCmd)
+	budgetCmd.AddCommand(budgetClearCmd)
+}
+
+// resolveBudgetContext returns the --context flag or the current context
+func resolveBudgetContext(ctx context.Context, cmd *cobra.Command) (string, error) {
+	if name, _ := cmd.Flags().GetString("context"); name != "" {
+		if err := awsinternal.ValidateBudgetContext(name); err != nil {
+			return "", err
+		}
+		return name, nil
+	}
+	return awsinternal.BudgetContext(ctx)
+}
+
+func runBudgetSet(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+
+	name, err := resolveBudgetContext(ctx, cmd)
+	if err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+
+	path, err := awsinternal.BudgetsPath()
+	if err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+	budgets, err := awsinternal.LoadBudgets(path)
+	if err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+
+	budget, exists := budgets[name]
+	if budget.Action == "" {
+		budget.Action = awsinternal.BudgetActionConfirm
+	}
+	if cmd.Flags().Changed("monthly") {
+		budget.MonthlyLimit, _ = cmd.Flags().GetFloat64("monthly")
+	}
+	if cmd.Flags().Changed("per-instance") {
+		budget.InstanceLimit, _ = cmd.Flags().GetFloat64("per-instance")
+	}
+	if cmd.Flags().Changed("action") {
+		budget.Action, _ = cmd.Flags().GetString("action")
+	}
+
+	// Removing the last limit of an existing budget removes the budget
+	removed := exists && budget.MonthlyLimit == 0 && budget.InstanceLimit == 0
+	if removed {
+		delete(budgets, name)
+	} else {
+		if err := budget.Validate(); err != nil {
+			fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+			return
+		}
+		budgets[name] = budget
+	}
+
+	if err := awsinternal.SaveBudgets(path, budgets); err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+
+	if removed {
+		log.Info("Budget removed", "context", name, "path", path)
+		fmt.Println(ui.FormatOutput("✓ Budget Cleared", fmt.Sprintf("Both limits are 0, removed the budget for %s", name)))
+		return
+	}
+
+	log.Info("Budget saved", "context", name, "path", path)
+	fmt.Println(ui.FormatOutput("✓ Budget Set", fmt.Sprintf("%s: %s", name, formatBudget(budget))))
+}
+
+func runBudgetShow(cmd *cobra.Command, args []string) {
+	path, err := awsinternal.BudgetsPath()
+	if err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+	budgets, err := awsinternal.LoadBudgets(path)
+	if err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+
+	if len(budgets) == 0 {
+		fmt.Println(ui.FormatOutput("✓ Budgets", "No budgets configured. Set one with: clouddley vm aws budget set --monthly <usd>"))
+		return
+	}
+
+	// Mark the current context when AWS is configured; budgets are readable without it
+	current, _ := awsinternal.BudgetContext(context.Background())
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CONTEXT\tMONTHLY\tPER INSTANCE\tACTION")
+	for _, name := range budgets.Contexts() {
+		budget := budgets[name]
+		label := name
+		if name == current {
+			label += " (current)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", label, formatLimit(budget.MonthlyLimit), formatLimit(budget.InstanceLimit), budget.Action)
+	}
+	w.Flush()
+}
+
+func runBudgetClear(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+
+	name, err := resolveBudgetContext(ctx, cmd)
+	if err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+
+	path, err := awsinternal.BudgetsPath()
+	if err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+	budgets, err := awsinternal.LoadBudgets(path)
+	if err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+
+	if _, ok := budgets[name]; !ok {
+		fmt.Println(ui.FormatOutput("✓ Budget", fmt.Sprintf("No budget configured for %s", name)))
+		return
+	}
+
+	delete(budgets, name)
+	if err := awsinternal.SaveBudgets(path, budgets); err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+	fmt.Println(ui.FormatOutput("✓ Budget Cleared", fmt.Sprintf("Removed the budget for %s", name)))
+}
+
+// formatBudget summarizes a budget on one line
+func formatBudget(budget awsinternal.Budget) string {
+	return fmt.Sprintf("monthly %s, per instance %s, %s when exceeded",
+		formatLimit(budget.MonthlyLimit), formatLimit(budget.InstanceLimit), budget.Action)
+}
+
+func formatLimit(limit float64) string {
+	if limit <= 0 {
+		return "none"
+	}
+	return fmt.Sprintf("$%.2f", limit)
+}
+
+// fullMonthlyPrice is the monthly cost of an instance running the whole month. Unlike
+// TotalPrice it ignores the configured billing hours
+func fullMonthlyPrice(pricing *awsinternal.PricingInfo) float64 {
+	return pricing.HourlyPrice*awsinternal.HoursPerMonth + pricing.EBSPrice
+}
+
+// enforceBudget projects the fleet cost with the new instance and checks it against
+// the context's budget. It returns false when the launch must not go ahead
+func enforceBudget(ctx context.Context, instanceType string, volume awsinternal.EBSVolume, overBudget bool) (bool, error) {
+	path, err := awsinternal.BudgetsPath()
+	if err != nil {
+		return false, err
+	}
+	budgets, err := awsinternal.LoadBudgets(path)
+	if err != nil {
+		return false, err
+	}
+	name, err := awsinternal.BudgetContext(ctx)
+	if err != nil {
+		return false, err
+	}
+	budget, ok := budgets[name]
+	if !ok {
+		return true, nil
+	}
+
+	// Budgets are checked against on-demand prices, an upper bound for spot instances
+	pricer, err := awsinternal.NewPricer(ctx)
+	if err != nil {
+		return false, err
+	}
+	pricing, err := pricer.InstancePricing(ctx, instanceType, volume)
+	if err != nil {
+		return false, fmt.Errorf("failed to price %s for the budget check: %w", instanceType, err)
+	}
+
+	var fleetMonthly float64
+	if budget.MonthlyLimit > 0 {
+		client, err := awsinternal.GetEC2Client(ctx)
+		if err != nil {
+			return false, err
+		}
+		instances, err := listCloudleyInstances(ctx, client)
+		if err != nil {
+			return false, err
+		}
+		if err := attachInstanceCosts(ctx, client, instances); err != nil {
+			return false, err
+		}
+		fleet := summarizeFleetCost(instances)
+		if fleet.Unpriced > 0 {
+			log.Warn("Some instances could not be priced and are not counted against the budget", "instances", fleet.Unpriced)
+		}
+		fleetMonthly = fleet.FullMonthly
+	}
+
+	instanceMonthly := fullMonthlyPrice(pricing)
+	violations := budget.Check(fleetMonthly, instanceMonthly)
+	if len(violations) == 0 {
+		log.Debug("Launch is within budget", "context", name, "fleet", fleetMonthly, "instance", instanceMonthly)
+		return true, nil
+	}
+
+	message := fmt.Sprintf("Launching %s in %s costs $%.2f/month.", instanceType, name, instanceMonthly)
+	for _, violation := range violations {
+		message += "\n" + violation.String()
+	}
+	fmt.Println(ui.FormatError(message))
+
+	if budget.Refuses() {
+		fmt.Printf("The %s budget refuses launches over its limits. Change it with: clouddley vm aws budget set --context %s\n", name, name)
+		return false, nil
+	}
+	if overBudget {
+		log.Warn("Launching over budget", "context", name)
+		return true, nil
+	}
+
+	confirmModel := ui.NewConfirmationModel("Launch anyway?")
+	m, err := tea.NewProgram(confirmModel).Run()
+	if err != nil {
+		return false, fmt.Errorf("failed to run confirmation prompt: %w", err)
+	}
+	confirmResult := m.(ui.ConfirmationModel)
+	return confirmResult.Answered() && confirmResult.Selected(), nil
+}
@@ -0,0 +1,87 @@
+package aws
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	awsinternal "github.com/clouddley/clouddley/internal/aws"
+	"github.com/spf13/cobra"
+)
+
+func newBudgetSetTestCmd(t *testing.T, args ...string) *cobra.Command {
+	cmd := &cobra.Command{Use: "set", Run: runBudgetSet}
+	cmd.Flags().String("context", "", "")
+	cmd.Flags().Float64("monthly", 0, "")
+	cmd.Flags().Float64("per-instance", 0, "")
+	cmd.Flags().String("action", awsinternal.BudgetActionConfirm, "")
+	if err := cmd.ParseFlags(args); err != nil {
+		t.Fatalf("Error parsing flags: %v", err)
+	}
+	return cmd
+}
+
+func TestRunBudgetSet_ZeroLimitsRemoveBudget(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	path, err := awsinternal.BudgetsPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := newBudgetSetTestCmd(t, "--context", "corp/us-east-1", "--monthly", "500")
+	cmd.Run(cmd, nil)
+
+	budgets, err := awsinternal.LoadBudgets(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if budgets["corp/us-east-1"].MonthlyLimit != 500 {
+		t.Fatalf("Expected a $500 budget, got %+v", budgets)
+	}
+
+	cmd = newBudgetSetTestCmd(t, "--context", "corp/us-east-1", "--monthly", "0")
+	cmd.Run(cmd, nil)
+
+	budgets, err = awsinternal.LoadBudgets(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := budgets["corp/us-east-1"]; ok {
+		t.Errorf("Expected the budget to be removed, got %+v", budgets)
+	}
+}
+
+func TestResolveBudgetContext_InvalidContext(t *testing.T) {
+	cmd := newBudgetSetTestCmd(t, "--context", "us-east-1")
+	if _, err := resolveBudgetContext(context.Background(), cmd); err == nil {
+		t.Error("Expected an error for a context without a profile")
+	}
+}
+
+func TestBudgetProjection_IgnoresBillingHours(t *testing.T) {
+	if err := awsinternal.ConfigureHoursPerMonth(1); err != nil {
+		t.Fatalf("ConfigureHoursPerMonth() error = %v", err)
+	}
+	t.Cleanup(func() { awsinternal.ConfigureHoursPerMonth(awsinternal.HoursPerMonth) })
+
+	pricing := &awsinternal.PricingInfo{HourlyPrice: 0.2, EBSPrice: 8, TotalPrice: 0.2*1 + 8}
+	instanceMonthly := fullMonthlyPrice(pricing)
+	if want := 0.2*awsinternal.HoursPerMonth + 8; math.Abs(instanceMonthly-want) > 1e-9 {
+		t.Errorf("fullMonthlyPrice() = %v, want %v", instanceMonthly, want)
+	}
+
+	fleet := summarizeFleetCost([]ClouddleyInstance{
+		{InstanceID: "i-1", Cost: newInstanceCost("running", 0.5, 10, false)},
+	})
+	if want := 0.5*awsinternal.HoursPerMonth + 10; math.Abs(fleet.FullMonthly-want) > 1e-9 {
+		t.Errorf("FullMonthly = %v, want %v", fleet.FullMonthly, want)
+	}
+
+	// One billed hour a month must not let a launch slip under a refuse budget
+	budget := awsinternal.Budget{MonthlyLimit: 300, InstanceLimit: 100, Action: awsinternal.BudgetActionRefuse}
+	if violations := budget.Check(fleet.FullMonthly, instanceMonthly); len(violations) != 2 {
+		t.Errorf("Check() = %v, want both limits exceeded", violations)
+	}
+}
@@ -0,0 +1,199 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	awsinternal "github.com/clouddley/clouddley/internal/aws"
+	"github.com/clouddley/clouddley/internal/log"
+)
+
+// InstanceCost is what an instance costs in its current state. Compute is only
+// billed while an instance runs; attached storage is billed until it is deleted
+type InstanceCost struct {
+	ComputeHourly  float64
+	StorageMonthly float64
+	// Estimated is set when a price came from the embedded snapshot
+	Estimated bool
+}
+
+// Hourly returns the cost per running hour, with storage spread over the whole month
+func (c InstanceCost) Hourly() float64 {
+	return c.ComputeHourly + c.StorageMonthly/awsinternal.HoursPerMonth
+}
+
+// Monthly returns the compute cost for BillingHoursPerMonth hours plus storage
+func (c InstanceCost) Monthly() float64 {
+	return c.ComputeHourly*awsinternal.BillingHoursPerMonth() + c.StorageMonthly
+}
+
+// FullMonthly returns the compute cost for a whole month plus storage. Budgets use it
+// so a lower --hours-per-month cannot hide what instances may cost
+func (c InstanceCost) FullMonthly() float64 {
+	return c.ComputeHourly*awsinternal.HoursPerMonth + c.StorageMonthly
+}
+
+// fleetCost is the combined cost of a set of instances
+type fleetCost struct {
+	Hourly      float64
+	Monthly     float64
+	FullMonthly float64
+	Priced      int
+	Unpriced    int
+	Estimated   bool
+}
+
+// newInstanceCost drops the compute price of instances that are not running
+func newInstanceCost(state string, computeHourly, storageMonthly float64, estimated bool) *InstanceCost {
+	if state != string(types.InstanceStateNameRunning) {
+		computeHourly = 0
+	}
+	return &InstanceCost{ComputeHourly: computeHourly, StorageMonthly: storageMonthly, Estimated: estimated}
+}
+
+// summarizeFleetCost totals the priced instances and counts the rest
+func summarizeFleetCost(instances []ClouddleyInstance) fleetCost {
+	var total fleetCost
+	for _, instance := range instances {
+		if instance.Cost == nil {
+			total.Unpriced++
+			continue
+		}
+		total.Priced++
+		total.Hourly += instance.Cost.Hourly()
+		total.Monthly += instance.Cost.Monthly()
+		total.FullMonthly += instance.Cost.FullMonthly()
+		total.Estimated = total.Estimated || instance.Cost.Estimated
+	}
+	return total
+}
+
+// formatInstanceCost returns the compute per hour and monthly cells of the cost
+// columns. Like the instance picker, the hourly cell leaves storage out
+func formatInstanceCost(cost *InstanceCost) (string, string) {
+	if cost == nil {
+		return "N/A", "N/A"
+	}
+	prefix := ""
+	if cost.Estimated {
+		prefix = "~"
+	}
+	return fmt.Sprintf("%s$%.4f", prefix, cost.ComputeHourly), fmt.Sprintf("%s$%.2f", prefix, cost.Monthly())
+}
+
+// attachInstanceCosts prices the compute and attached volumes of each instance.
+// Instances that cannot be priced keep a nil Cost
+func attachInstanceCosts(ctx context.Context, client *ec2.Client, instances []ClouddleyInstance) error {
+	if len(instances) == 0 {
+		return nil
+	}
+
+	pricer, err := awsinternal.NewPricer(ctx)
+	if err != nil {
+		return err
+	}
+
+	instanceIDs := make([]string, len(instances))
+	for i, instance := range instances {
+		instanceIDs[i] = instance.InstanceID
+	}
+	volumes, err := describeInstanceVolumes(ctx, client, instanceIDs)
+	if err != nil {
+		return err
+	}
+
+	awsinternal.ForEachLimited(len(instances), awsinternal.DefaultPricingParallelism, func(i int) {
+		instance := &instances[i]
+		instance.Volumes = volumes[instance.InstanceID]
+		cost, err := priceInstance(ctx, pricer, client, *instance)
+		if err != nil {
+			log.Warn("Failed to price instance", "instance", instance.InstanceID, "error", err)
+			return
+		}
+		instance.Cost = cost
+	})
+	return nil
+}
+
+// priceInstance prices an instance and its volumes for its current state
+func priceInstance(ctx context.Context, pricer *awsinternal.Pricer, spotClient awsinternal.SpotPriceHistoryClient, instance ClouddleyInstance) (*InstanceCost, error) {
+	computeHourly, estimated, err := instanceComputeHourly(ctx, pricer, spotClient, instance)
+	if err != nil {
+		return nil, err
+	}
+
+	var storageMonthly float64
+	for _, volume := range instance.Volumes {
+		price, volumeEstimated, err := pricer.VolumePricing(ctx, volume)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get EBS pricing for %s: %w", volume, err)
+		}
+		storageMonthly += price
+		estimated = estimated || volumeEstimated
+	}
+
+	return newInstanceCost(instance.State, computeHourly, storageMonthly, estimated), nil
+}
+
+// instanceComputeHourly returns the hourly compute price of an instance: the current
+// spot price in its availability zone for spot instances, falling back to on-demand
+// as an upper bound
+func instanceComputeHourly(ctx context.Context, pricer *awsinternal.Pricer, spotClient awsinternal.SpotPriceHistoryClient, instance ClouddleyInstance) (float64, bool, error) {
+	if instance.Spot {
+		spot, err := awsinternal.GetZoneSpotPriceWithClient(ctx, spotClient, instance.InstanceType, instance.AZ)
+		if err == nil {
+			return spot.HourlyPrice, false, nil
+		}
+		log.Warn("Failed to get spot price, using the on-demand price as an upper bound", "instance", instance.InstanceID, "error", err)
+	}
+
+	computeHourly, estimated, err := pricer.OnDemandHourly(ctx, instance.InstanceType)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to get EC2 pricing: %w", err)
+	}
+	return computeHourly, estimated, nil
+}
+
+// describeInstanceVolumes returns the EBS volumes attached to each instance
+func describeInstanceVolumes(ctx context.Context, client *ec2.Client, instanceIDs []string) (map[string][]awsinternal.EBSVolume, error) {
+	paginator := ec2.NewDescribeVolumesPaginator(client, &ec2.DescribeVolumesInput{
+		Filters: []types.Filter{
+			{
+				Name:   aws.String("attachment.instance-id"),
+				Values: instanceIDs,
+			},
+		},
+	})
+
+	var volumes []types.Volume
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe volumes: %w", err)
+		}
+		volumes = append(volumes, page.Volumes...)
+	}
+	return volumesByInstance(volumes), nil
+}
+
+// volumesByInstance groups volumes by the instance they are attached to
+func volumesByInstance(volumes []types.Volume) map[string][]awsinternal.EBSVolume {
+	byInstance := make(map[string][]awsinternal.EBSVolume)
+	for _, volume := range volumes {
+		ebsVolume := awsinternal.EBSVolume{
+			Type:       string(volume.VolumeType),
+			SizeGB:     aws.ToInt32(volume.Size),
+			IOPS:       aws.ToInt32(volume.Iops),
+			Throughput: aws.ToInt32(volume.Throughput),
+		}
+		for _, attachment := range volume.Attachments {
+			if attachment.InstanceId != nil {
+				byInstance[*attachment.InstanceId] = append(byInstance[*attachment.InstanceId], ebsVolume)
+			}
+		}
+	}
+	return byInstance
+}
@@ -0,0 +1,183 @@
+package aws
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	awsinternal "github.com/clouddley/clouddley/internal/aws"
+)
+
+func TestNewInstanceCost(t *testing.T) {
+	tests := []struct {
+		name        string
+		state       string
+		wantHourly  float64
+		wantMonthly float64
+	}{
+		{name: "running pays compute and storage", state: "running", wantHourly: 0.1 + 8/awsinternal.HoursPerMonth, wantMonthly: 0.1*awsinternal.HoursPerMonth + 8},
+		{name: "stopped pays storage only", state: "stopped", wantHourly: 8 / awsinternal.HoursPerMonth, wantMonthly: 8},
+		{name: "stopping pays storage only", state: "stopping", wantHourly: 8 / awsinternal.HoursPerMonth, wantMonthly: 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cost := newInstanceCost(tt.state, 0.1, 8, false)
+			if math.Abs(cost.Hourly()-tt.wantHourly) > 1e-9 {
+				t.Errorf("Hourly() = %v, want %v", cost.Hourly(), tt.wantHourly)
+			}
+			if math.Abs(cost.Monthly()-tt.wantMonthly) > 1e-9 {
+				t.Errorf("Monthly() = %v, want %v", cost.Monthly(), tt.wantMonthly)
+			}
+		})
+	}
+}
+
+func TestSummarizeFleetCost(t *testing.T) {
+	instances := []ClouddleyInstance{
+		{InstanceID: "i-1", Cost: newInstanceCost("running", 0.1, 8, false)},
+		{InstanceID: "i-2", Cost: newInstanceCost("stopped", 0.2, 10, true)},
+		{InstanceID: "i-3"},
+	}
+
+	total := summarizeFleetCost(instances)
+
+	wantMonthly := 0.1*awsinternal.HoursPerMonth + 8 + 10
+	if math.Abs(total.Monthly-wantMonthly) > 1e-9 {
+		t.Errorf("Monthly = %v, want %v", total.Monthly, wantMonthly)
+	}
+	if math.Abs(total.Hourly-wantMonthly/awsinternal.HoursPerMonth) > 1e-9 {
+		t.Errorf("Hourly = %v, want %v", total.Hourly, wantMonthly/awsinternal.HoursPerMonth)
+	}
+	if total.Priced != 2 || total.Unpriced != 1 {
+		t.Errorf("Priced = %d, Unpriced = %d, want 2 and 1", total.Priced, total.Unpriced)
+	}
+	if !total.Estimated {
+		t.Error("Estimated = false, want true")
+	}
+}
+
+func TestFormatInstanceCost(t *testing.T) {
+	tests := []struct {
+		name        string
+		cost        *InstanceCost
+		wantHourly  string
+		wantMonthly string
+	}{
+		{name: "unpriced", cost: nil, wantHourly: "N/A", wantMonthly: "N/A"},
+		{name: "storage only", cost: &InstanceCost{StorageMonthly: 8}, wantHourly: "$0.0000", wantMonthly: "$8.00"},
+		{name: "hourly is compute only", cost: &InstanceCost{ComputeHourly: 0.1, StorageMonthly: 8}, wantHourly: "$0.1000", wantMonthly: "$81.06"},
+		{name: "estimated", cost: &InstanceCost{ComputeHourly: 0.0104, Estimated: true}, wantHourly: "~$0.0104", wantMonthly: "~$7.60"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hourly, monthly := formatInstanceCost(tt.cost)
+			if hourly != tt.wantHourly || monthly != tt.wantMonthly {
+				t.Errorf("formatInstanceCost() = %q, %q, want %q, %q", hourly, monthly, tt.wantHourly, tt.wantMonthly)
+			}
+		})
+	}
+}
+
+func TestFormatFleetCost(t *testing.T) {
+	footer := formatFleetCost(fleetCost{Hourly: 0.1, Monthly: 73.06, Priced: 2, Unpriced: 1})
+	if !strings.Contains(footer, "Monthly burn rate: $73.06 ($0.1000/hour with storage) across 2 instance(s)") {
+		t.Errorf("footer = %q", footer)
+	}
+	if !strings.Contains(footer, "1 instance(s) could not be priced") {
+		t.Errorf("footer does not mention unpriced instances: %q", footer)
+	}
+	if strings.Contains(footer, "~") {
+		t.Errorf("footer marks live prices as estimated: %q", footer)
+	}
+}
+
+func TestVolumesByInstance(t *testing.T) {
+	volumes := []types.Volume{
+		{
+			VolumeType: types.VolumeTypeGp3, Size: aws.Int32(100), Iops: aws.Int32(3000), Throughput: aws.Int32(125),
+			Attachments: []types.VolumeAttachment{{InstanceId: aws.String("i-1")}},
+		},
+		{
+			VolumeType: types.VolumeTypeIo2, Size: aws.Int32(50), Iops: aws.Int32(5000),
+			Attachments: []types.VolumeAttachment{{InstanceId: aws.String("i-1")}},
+		},
+		{
+			VolumeType: types.VolumeTypeGp2, Size: aws.Int32(20), Iops: aws.Int32(100),
+			Attachments: []types.VolumeAttachment{{InstanceId: aws.String("i-2")}},
+		},
+		{VolumeType: types.VolumeTypeGp3, Size: aws.Int32(10)},
+	}
+
+	byInstance := volumesByInstance(volumes)
+
+	if len(byInstance) != 2 {
+		t.Fatalf("got volumes for %d instances, want 2", len(byInstance))
+	}
+	if len(byInstance["i-1"]) != 2 {
+		t.Fatalf("i-1 has %d volumes, want 2", len(byInstance["i-1"]))
+	}
+	want := awsinternal.EBSVolume{Type: "io2", SizeGB: 50, IOPS: 5000}
+	if byInstance["i-1"][1] != want {
+		t.Errorf("i-1 second volume = %+v, want %+v", byInstance["i-1"][1], want)
+	}
+	if byInstance["i-2"][0].SizeGB != 20 {
+		t.Errorf("i-2 volume = %+v, want 20 GB", byInstance["i-2"][0])
+	}
+}
+
+func TestInstanceCost_BillingHours(t *testing.T) {
+	if err := awsinternal.ConfigureHoursPerMonth(220); err != nil {
+		t.Fatalf("ConfigureHoursPerMonth() error = %v", err)
+	}
+	t.Cleanup(func() { awsinternal.ConfigureHoursPerMonth(awsinternal.HoursPerMonth) })
+
+	cost := newInstanceCost("running", 0.1, 8, false)
+	// Compute is billed for the configured hours, storage for the whole month
+	if got, want := cost.Monthly(), 0.1*220+8; math.Abs(got-want) > 1e-9 {
+		t.Errorf("Monthly() = %v, want %v", got, want)
+	}
+	if got, want := cost.Hourly(), 0.1+8/awsinternal.HoursPerMonth; math.Abs(got-want) > 1e-9 {
+		t.Errorf("Hourly() = %v, want %v", got, want)
+	}
+}
+
+type mockSpotPriceClient struct {
+	prices []types.SpotPrice
+	zone   string
+}
+
+func (m *mockSpotPriceClient) DescribeSpotPriceHistory(ctx context.Context, params *ec2.DescribeSpotPriceHistoryInput, optFns ...func(*ec2.Options)) (*ec2.DescribeSpotPriceHistoryOutput, error) {
+	m.zone = aws.ToString(params.AvailabilityZone)
+	return &ec2.DescribeSpotPriceHistoryOutput{SpotPriceHistory: m.prices}, nil
+}
+
+func TestPriceInstance_Spot(t *testing.T) {
+	client := &mockSpotPriceClient{prices: []types.SpotPrice{
+		{AvailabilityZone: aws.String("us-east-1a"), SpotPrice: aws.String("0.0150"), Timestamp: aws.Time(time.Now())},
+		{AvailabilityZone: aws.String("us-east-1b"), SpotPrice: aws.String("0.0120"), Timestamp: aws.Time(time.Now())},
+	}}
+	instance := ClouddleyInstance{InstanceID: "i-1", State: "running", InstanceType: "m5.large", Spot: true, AZ: "us-east-1a"}
+
+	// Spot instances never reach the on-demand pricer, so a nil one is fine here
+	cost, err := priceInstance(context.Background(), nil, client, instance)
+	if err != nil {
+		t.Fatalf("priceInstance() error = %v", err)
+	}
+	if client.zone != "us-east-1a" {
+		t.Errorf("spot price requested for %q, want us-east-1a", client.zone)
+	}
+	// The instance pays its own zone's price, not the cheaper us-east-1b one
+	if cost.ComputeHourly != 0.015 {
+		t.Errorf("ComputeHourly = %v, want the us-east-1a spot price 0.015", cost.ComputeHourly)
+	}
+	if cost.Estimated {
+		t.Error("Estimated = true, want false for a live spot price")
+	}
+}
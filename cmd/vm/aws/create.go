@@ -3,7 +3,7 @@ package aws
 import (
 	"context"
 	"fmt"
-	"os/exec"
+	"os"
 	"strings"
 	"time"
 
@@ -11,6 +11,8 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	awsinternal "github.com/clouddley/clouddley/internal/aws"
+	"github.com/clouddley/clouddley/internal/clouddleykey"
+	"github.com/clouddley/clouddley/internal/cloudinit"
 	"github.com/clouddley/clouddley/internal/log"
 	"github.com/clouddley/clouddley/internal/ui"
 	tea "github.com/charmbracelet/bubbletea"
@@ -20,13 +22,167 @@ import (
 var createCmd = &cobra.Command{
 	Use:   "create",
 	Short: "Create a new AWS EC2 instance",
-	Long:  `Create a new AWS EC2 instance with interactive selection of environment and instance type.`,
-	Run:   runCreate,
+	Long: `Create a new AWS EC2 instance with interactive selection of environment and instance type.
+
+Use --template to provision software at boot with cloud-init, or --user-data to
+pass your own cloud-config or shell script. The Clouddley public key is always
+installed at boot by cloud-init, merged with the template and your user data;
+pass --no-clouddley-key to leave it out.
+
+Use --triggr to get a VM that is immediately usable with 'clouddley triggr logs':
+Docker is installed, a single-node swarm is initialized and the result is
+verified with 'docker info' before the command returns.`,
+	Example: `  clouddley vm aws create
+  clouddley vm aws create --triggr
+  clouddley vm aws create --spot --spot-interruption stop
+  clouddley vm aws create --vpc vpc-0abc123 --az us-east-1b
+  clouddley vm aws create --ssh-cidr 203.0.113.0/24 --open-port 8080/tcp
+  clouddley vm aws create --elastic-ip
+  clouddley vm aws create --key-name alice-laptop
+  clouddley vm aws create --refresh-pricing
+  clouddley vm aws create --volume-size 200 --iops 6000 --throughput 250
+  clouddley vm aws create --over-budget
+  clouddley vm aws create --hours-per-month 220
+  clouddley vm aws create --template docker
+  clouddley vm aws create --user-data ./cloud-config.yaml`,
+	Run: runCreate,
+}
+
+func init() {
+	createCmd.Flags().String("user-data", "", "Path to a cloud-config or shell script passed to the instance as user data")
+	createCmd.Flags().String("template", cloudinit.TemplateNone, fmt.Sprintf("Built-in cloud-init template (%s)", strings.Join(cloudinit.Templates(), "|")))
+	createCmd.Flags().Bool("no-clouddley-key", false, "Do not install the Clouddley public key at boot")
+	createCmd.Flags().Bool("triggr", false, "Provision a Triggr-ready VM with Docker swarm and the Clouddley key")
+	createCmd.Flags().Bool("spot", false, "Launch a spot instance instead of on-demand")
+	createCmd.Flags().String("spot-max-price", "", "Maximum spot price in USD per hour (defaults to the on-demand price)")
+	createCmd.Flags().String("spot-interruption", "terminate", "Spot interruption behavior (stop|hibernate|terminate)")
+	createCmd.Flags().String("vpc", "", "VPC ID to launch into (defaults to the default VPC)")
+	createCmd.Flags().String("subnet", "", "Subnet ID to launch into")
+	createCmd.Flags().String("az", "", "Availability zone to launch into (e.g. us-east-1a)")
+	createCmd.Flags().StringSlice("ssh-cidr", nil, "CIDR allowed to SSH to the instance (repeatable, defaults to your public IP). Without --dedicated-sg it is added to the shared clouddley-default-sg and applies to every instance using it until 'firewall reset'")
+	createCmd.Flags().StringSlice("open-port", nil, "Extra port open to the internet, e.g. 8080/tcp or 8000-8100/udp (repeatable)")
+	createCmd.Flags().Bool("dedicated-sg", false, "Create a security group for this instance instead of using the shared clouddley-default-sg")
+	createCmd.Flags().String("key-name", awsinternal.DefaultKeyPairName, "AWS key pair to launch with, imported from a local key if it does not exist")
+	createCmd.Flags().Bool("elastic-ip", false, "Attach an Elastic IP so the instance keeps its address across stop and start")
+	createCmd.Flags().Bool("refresh-pricing", false, "Fetch prices from AWS instead of the local pricing cache")
+	createCmd.Flags().String("volume-type", awsinternal.DefaultEBSVolume.Type, "Root volume type (gp2|gp3|io1|io2)")
+	createCmd.Flags().Int32("volume-size", awsinternal.DefaultEBSVolume.SizeGB, "Root volume size in GB")
+	createCmd.Flags().Int32("iops", 0, "Provisioned IOPS for gp3, io1 and io2 root volumes (required for io1 and io2)")
+	createCmd.Flags().Int32("throughput", 0, "Provisioned throughput in MiB/s for gp3 root volumes")
+	createCmd.Flags().Float64("hours-per-month", 0, "Hours a month the instance runs, for monthly prices (defaults to $CLOUDDLEY_HOURS_PER_MONTH or a full month)")
+	createCmd.Flags().Bool("over-budget", false, "Launch without confirmation when the budget would be exceeded (budgets set to refuse still block)")
+}
+
+// createOptions holds the launch settings gathered from flags
+type createOptions struct {
+	UserData  string                              // base64 encoded user data, empty when none
+	Triggr    bool                                // verify Docker swarm is ready after launch
+	Spot      *types.InstanceMarketOptionsRequest // nil for on-demand
+	ElasticIP bool                                // attach a Clouddley Elastic IP once running
+	KeyName   string                              // AWS key pair the instance is launched with
+	Volume    awsinternal.EBSVolume               // root volume
+	Placement placementOptions
+	Firewall  firewallOptions
+}
+
+// firewallOptions holds the security group settings for a new instance
+type firewallOptions struct {
+	SSHCIDRs  []string   // sources allowed to SSH, defaults to the caller's public IP
+	OpenPorts []portSpec // extra ports open to the world
+	Dedicated bool       // create a security group for this instance instead of the shared one
 }
 
 func runCreate(cmd *cobra.Command, args []string) {
 	ctx := context.Background()
 
+	userDataPath, _ := cmd.Flags().GetString("user-data")
+	templateName, _ := cmd.Flags().GetString("template")
+	triggr, _ := cmd.Flags().GetBool("triggr")
+	noClouddleyKey, _ := cmd.Flags().GetBool("no-clouddley-key")
+	spot, _ := cmd.Flags().GetBool("spot")
+	spotMaxPrice, _ := cmd.Flags().GetString("spot-max-price")
+	spotInterruption, _ := cmd.Flags().GetString("spot-interruption")
+	vpcID, _ := cmd.Flags().GetString("vpc")
+	subnetID, _ := cmd.Flags().GetString("subnet")
+	az, _ := cmd.Flags().GetString("az")
+	sshCIDRFlags, _ := cmd.Flags().GetStringSlice("ssh-cidr")
+	openPortFlags, _ := cmd.Flags().GetStringSlice("open-port")
+	dedicatedSG, _ := cmd.Flags().GetBool("dedicated-sg")
+	elasticIP, _ := cmd.Flags().GetBool("elastic-ip")
+	keyName, _ := cmd.Flags().GetString("key-name")
+	refreshPricing, _ := cmd.Flags().GetBool("refresh-pricing")
+	volumeType, _ := cmd.Flags().GetString("volume-type")
+	volumeSize, _ := cmd.Flags().GetInt32("volume-size")
+	iops, _ := cmd.Flags().GetInt32("iops")
+	throughput, _ := cmd.Flags().GetInt32("throughput")
+	overBudget, _ := cmd.Flags().GetBool("over-budget")
+	hoursPerMonth, _ := cmd.Flags().GetFloat64("hours-per-month")
+
+	if err := validateKeyPairName(keyName); err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+
+	volume, err := rootVolume(volumeType, volumeSize, iops, throughput)
+	if err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+
+	if err := awsinternal.ConfigurePricingCache(0, refreshPricing); err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+	if err := awsinternal.ConfigureHoursPerMonth(hoursPerMonth); err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+
+	if triggr {
+		if templateName != cloudinit.TemplateNone && templateName != "triggr" {
+			fmt.Println(ui.FormatError(fmt.Sprintf("Error: --triggr cannot be combined with --template %s", templateName)))
+			return
+		}
+		if noClouddleyKey {
+			fmt.Println(ui.FormatError("Error: --triggr needs the Clouddley public key and cannot be combined with --no-clouddley-key"))
+			return
+		}
+		templateName = "triggr"
+	}
+
+	// Render and validate user data before doing any AWS work
+	userData, err := buildUserData(templateName, userDataPath, !noClouddleyKey)
+	if err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+	opts := createOptions{
+		UserData:  userData,
+		Triggr:    triggr,
+		ElasticIP: elasticIP,
+		KeyName:   keyName,
+		Volume:    volume,
+		Placement: placementOptions{VPC: vpcID, Subnet: subnetID, AZ: az},
+		Firewall:  firewallOptions{Dedicated: dedicatedSG},
+	}
+
+	for _, flag := range openPortFlags {
+		port, err := parsePortSpec(flag)
+		if err != nil {
+			fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+			return
+		}
+		opts.Firewall.OpenPorts = append(opts.Firewall.OpenPorts, port)
+	}
+
+	if spot {
+		opts.Spot, err = buildSpotMarketOptions(spotOptions{MaxPrice: spotMaxPrice, Interruption: spotInterruption})
+		if err != nil {
+			fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+			return
+		}
+	}
+
 	// Show banner
 	fmt.Print(ui.ShowBanner())
 
@@ -36,12 +192,20 @@ func runCreate(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	// Decide who can reach the instance over SSH
+	opts.Firewall.SSHCIDRs, err = resolveSSHCIDRs(ctx, sshCIDRFlags)
+	if err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+
 	// Check/handle SSH keys
-	selectedKey, err := handleSSHKeys(ctx)
+	selectedKey, keyName, err := handleSSHKeys(ctx, opts.KeyName)
 	if err != nil {
 		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
 		return
 	}
+	opts.KeyName = keyName
 
 	// Interactive environment selection
 	envModel := ui.NewEnvironmentModel()
@@ -59,7 +223,7 @@ func runCreate(cmd *cobra.Command, args []string) {
 	}
 
 	// Get instance types and pricing
-	instances, err := getInstanceTypes(ctx, envChoice == 0) // 0 = dev/test, 1 = production
+	instances, err := getInstanceTypes(ctx, envChoice == 0, spot, opts.Volume) // 0 = dev/test, 1 = production
 	if err != nil {
 		fmt.Println(ui.FormatError(fmt.Sprintf("Error fetching instance types: %v", err)))
 		return
@@ -70,6 +234,12 @@ func runCreate(cmd *cobra.Command, args []string) {
 	if envChoice == 1 {
 		title = "Production Instances"
 	}
+	if note := billingHoursNote(awsinternal.BillingHoursPerMonth()); note != "" {
+		title += " (" + note + ")"
+	}
+	if note := estimatedPricingNote(instances); note != "" {
+		title += " (" + note + ")"
+	}
 	instanceModel := ui.NewInstanceSelectionModel(instances, title)
 	p = tea.NewProgram(instanceModel)
 	m, err = p.Run()
@@ -85,10 +255,21 @@ func runCreate(cmd *cobra.Command, args []string) {
 	}
 
 	selectedInstance := instances[instanceChoice]
+
+	// Check the launch against the context's budget
+	proceed, err := enforceBudget(ctx, selectedInstance.Type, opts.Volume, overBudget)
+	if err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error checking budget: %v", err)))
+		return
+	}
+	if !proceed {
+		fmt.Println("Operation cancelled")
+		return
+	}
 	
 	// Create the instance
 	fmt.Println("Creating instance...")
-	instanceInfo, err := createInstance(ctx, selectedInstance.Type, selectedKey)
+	instanceInfo, err := createInstance(ctx, selectedInstance.Type, selectedKey, opts)
 	if err != nil {
 		fmt.Println(ui.FormatError(fmt.Sprintf("Error creating instance: %v", err)))
 		return
@@ -103,65 +284,143 @@ func runCreate(cmd *cobra.Command, args []string) {
 	instanceTable.AddRow("Instance ID", instanceInfo.InstanceID)
 	instanceTable.AddRow("Name", instanceInfo.Name)
 	instanceTable.AddRow("Region", instanceInfo.Region)
-	instanceTable.AddRow("Public IP", instanceInfo.PublicIP)
+	if instanceInfo.ElasticIP {
+		instanceTable.AddRow("Public IP", instanceInfo.PublicIP+" (Elastic IP)")
+	} else {
+		instanceTable.AddRow("Public IP", instanceInfo.PublicIP)
+	}
 	instanceTable.AddRow("Instance Type", selectedInstance.Type)
+	instanceTable.AddRow("Root Volume", opts.Volume.String())
+	if opts.Spot != nil {
+		instanceTable.AddRow("Market", fmt.Sprintf("spot (on interruption: %s)", opts.Spot.SpotOptions.InstanceInterruptionBehavior))
+	}
 	instanceTable.AddRow("Username", "ubuntu")
 	instanceTable.AddRow("SSH Port", "22")
 	instanceTable.AddRow("SSH Command", fmt.Sprintf("ssh ubuntu@%s", instanceInfo.PublicIP))
 	
 	fmt.Println(instanceTable.Render())
-	
-	// Post-creation: Ask if user wants to install Clouddley public key
-	fmt.Println()
-	confirmModel := ui.NewConfirmationModel("Install Clouddley public key for dashboard access?")
-	p = tea.NewProgram(confirmModel)
-	m, err = p.Run()
-	if err != nil {
-		log.Error("Error running confirmation prompt", "error", err)
+
+	if opts.Triggr {
+		version, err := waitForTriggrReady(ctx, instanceInfo.PublicIP)
+		if err != nil {
+			log.Error("Triggr provisioning could not be verified", "error", err)
+			fmt.Println(ui.FormatError(fmt.Sprintf("Triggr provisioning could not be verified: %v", err)))
+			fmt.Println("Check progress on the VM with: cloud-init status --long")
+			return
+		}
+
+		fmt.Println(ui.FormatOutput("✓ Triggr Ready", fmt.Sprintf("Docker %s is running with an active swarm and the Clouddley public key is installed.", version)))
+		fmt.Printf("View service logs by running 'clouddley triggr logs <service>' after: ssh ubuntu@%s\n", instanceInfo.PublicIP)
 		return
 	}
 
-	confirmResult := m.(ui.ConfirmationModel)
-	if !confirmResult.Answered() {
-		fmt.Println("Operation cancelled")
-		return
+	if summary := cloudInitSummary(templateName, userDataPath != "", !noClouddleyKey); summary != "" {
+		fmt.Println(ui.FormatOutput("✓ Cloud-init", summary))
 	}
+}
 
-	if confirmResult.Selected() {
-		// User chose yes, install the key
-		fmt.Println()
-		fmt.Println("Installing Clouddley public key on VM...")
-		
-		err = installClouddleyKey(ctx, instanceInfo.PublicIP)
-		if err != nil {
-			log.Error("Failed to install Clouddley public key", "error", err)
-			fmt.Println(ui.FormatError(fmt.Sprintf("Failed to install key: %v", err)))
-		} else {
-			log.Info("Clouddley public key installed successfully")
-			fmt.Println(ui.FormatOutput("✓ Success", "Clouddley public key installed! Your VM is now registered for dashboard authentication."))
-		}
-	} else {
-		fmt.Println("Skipped installing Clouddley public key.")
+// cloudInitSummary describes what cloud-init sets up at boot, or "" when the
+// instance gets no user data
+func cloudInitSummary(templateName string, customUserData, clouddleyKey bool) string {
+	var parts []string
+	if clouddleyKey {
+		parts = append(parts, "the Clouddley public key")
+	}
+	if templateName != cloudinit.TemplateNone {
+		parts = append(parts, fmt.Sprintf("the %s template", templateName))
+	}
+	if customUserData {
+		parts = append(parts, "your user data")
 	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("Cloud-init installs %s at boot. Provisioning may take a few minutes to finish.", strings.Join(parts, " and "))
 }
 
-func handleSSHKeys(ctx context.Context) (*awsinternal.SSHKeyInfo, error) {
+// handleSSHKeys makes sure an AWS key pair usable from this machine exists and returns
+// the imported local key, if any, and the name of the key pair to launch with
+func handleSSHKeys(ctx context.Context, keyName string) (*awsinternal.SSHKeyInfo, string, error) {
 	client, err := awsinternal.GetEC2Client(ctx)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	// Check if AWS key pair already exists
-	keyExists, err := awsinternal.CheckAWSKeyPair(ctx, client)
+	existing, err := awsinternal.GetKeyPair(ctx, client, keyName)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	if keyExists {
-		log.Info("Using existing clouddley-default-key from AWS")
-		return nil, nil // No need to import, key already exists
+	if existing != nil {
+		if keyPairUsableLocally(existing) {
+			return nil, keyName, nil // No need to import, key already exists
+		}
+
+		// The key pair was likely imported by a teammate; offer one of our own instead
+		userKeyName := perUserKeyPairName()
+		if userKeyName == "" || userKeyName == keyName {
+			fmt.Printf("Replace it with one of your keys using: clouddley vm aws keys rotate --name %s\n", keyName)
+			return nil, keyName, nil
+		}
+
+		confirmModel := ui.NewConfirmationModel(fmt.Sprintf("Use key pair %s with one of your local keys instead?", userKeyName))
+		p := tea.NewProgram(confirmModel)
+		m, err := p.Run()
+		if err != nil {
+			return nil, "", fmt.Errorf("error running confirmation prompt: %w", err)
+		}
+
+		if !m.(ui.ConfirmationModel).Selected() {
+			return nil, keyName, nil
+		}
+
+		return handleSSHKeys(ctx, userKeyName)
 	}
 
+	selectedKey, err := selectLocalSSHKey()
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Read and import the key
+	publicKeyContent, err := awsinternal.ReadSSHPublicKey(selectedKey.Path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	fmt.Printf("Importing SSH key to AWS as %s...\n", keyName)
+	err = awsinternal.ImportSSHKeyPair(ctx, client, keyName, publicKeyContent)
+	if err != nil {
+		return nil, "", err
+	}
+
+	log.Info("SSH key imported successfully", "key", keyName)
+	return selectedKey, keyName, nil
+}
+
+// keyPairUsableLocally reports whether the key pair matches a key in ~/.ssh or ssh-agent,
+// warning when it does not
+func keyPairUsableLocally(keyPair *awsinternal.KeyPairInfo) bool {
+	localKeys, err := awsinternal.LocalPublicKeys()
+	if err != nil {
+		// Don't block the launch on an unreadable key directory
+		log.Warn("Could not read local SSH keys to verify the key pair", "key", keyPair.Name, "error", err)
+		return true
+	}
+
+	if match, ok := awsinternal.MatchKeyFingerprint(keyPair.Fingerprint, localKeys); ok {
+		log.Info("Using existing key pair from AWS", "key", keyPair.Name, "local", match.Source)
+		return true
+	}
+
+	log.Warn("Key pair does not match any local SSH key or ssh-agent identity, SSH to the instance may fail",
+		"key", keyPair.Name, "fingerprint", keyPair.Fingerprint)
+	return false
+}
+
+// selectLocalSSHKey finds local SSH public keys and lets the user pick one when there are several
+func selectLocalSSHKey() (*awsinternal.SSHKeyInfo, error) {
 	// Check local SSH keys
 	localKeys, err := awsinternal.CheckLocalSSHKeys()
 	if err != nil {
@@ -169,14 +428,7 @@ func handleSSHKeys(ctx context.Context) (*awsinternal.SSHKeyInfo, error) {
 	}
 
 	if len(localKeys) == 0 {
-		return nil, fmt.Errorf(`no SSH public key found. Generate one using:
-ssh-keygen -t ed25519 -C "your_email@example.com" (recommended)
-or
-ssh-keygen -t rsa -b 4096 -C "your_email@example.com"
-
-See https://docs.github.com/en/authentication/connecting-to-github-with-ssh/generating-a-new-ssh-key-and-adding-it-to-the-ssh-agent for details.
-
-Then retry the command`)
+		return offerKeyGeneration()
 	}
 
 	var selectedKey *awsinternal.SSHKeyInfo
@@ -191,14 +443,14 @@ Then retry the command`)
 			keyChoices[i] = fmt.Sprintf("%s (%s)", key.Path, key.Type)
 		}
 
-		keyModel := ui.NewSSHKeySelectionModel(keyChoices)
+		keyModel := ui.NewListSelectionModel("Multiple SSH Keys Found - Select One", keyChoices)
 		p := tea.NewProgram(keyModel)
 		m, err := p.Run()
 		if err != nil {
 			return nil, fmt.Errorf("error running key selection: %w", err)
 		}
 
-		keyChoice := m.(ui.SSHKeySelectionModel).Selected()
+		keyChoice := m.(ui.ListSelectionModel).Selected()
 		if keyChoice == -1 {
 			return nil, fmt.Errorf("operation cancelled")
 		}
@@ -206,35 +458,24 @@ Then retry the command`)
 		selectedKey = &localKeys[keyChoice]
 	}
 
-	// Read and import the key
-	publicKeyContent, err := awsinternal.ReadSSHPublicKey(selectedKey.Path)
-	if err != nil {
-		return nil, err
-	}
-
-	fmt.Printf("Importing SSH key to AWS...\n")
-	err = awsinternal.ImportSSHKeyPair(ctx, client, publicKeyContent)
-	if err != nil {
-		return nil, err
-	}
-
-	log.Info("SSH key imported successfully")
 	return selectedKey, nil
 }
 
-func getInstanceTypes(ctx context.Context, isDev bool) ([]ui.InstanceType, error) {
-	var instanceSpecs []struct {
-		Type   string
-		CPU    string
-		Memory string
-	}
+// instanceSpec is an instance type offered in the picker
+type instanceSpec struct {
+	Type   string
+	CPU    string
+	Memory string
+}
+
+// priceUnavailable is shown in the picker when a price could not be fetched
+const priceUnavailable = "price unavailable"
+
+func getInstanceTypes(ctx context.Context, isDev bool, spot bool, volume awsinternal.EBSVolume) ([]ui.InstanceType, error) {
+	var instanceSpecs []instanceSpec
 	
 	if isDev {
-		instanceSpecs = []struct {
-			Type   string
-			CPU    string
-			Memory string
-		}{
+		instanceSpecs = []instanceSpec{
 			{"t3.nano", "2", "0.5 GB"},
 			{"t3.micro", "2", "1 GB"},
 			{"t3.small", "2", "2 GB"},
@@ -244,11 +485,7 @@ func getInstanceTypes(ctx context.Context, isDev bool) ([]ui.InstanceType, error
 			{"t3.2xlarge", "8", "32 GB"},
 		}
 	} else {
-		instanceSpecs = []struct {
-			Type   string
-			CPU    string
-			Memory string
-		}{
+		instanceSpecs = []instanceSpec{
 			{"m5.large", "2", "8 GB"},
 			{"m5.xlarge", "4", "16 GB"},
 			{"m5.2xlarge", "8", "32 GB"},
@@ -257,6 +494,18 @@ func getInstanceTypes(ctx context.Context, isDev bool) ([]ui.InstanceType, error
 			{"c5.2xlarge", "8", "16 GB"},
 		}
 	}
+
+	pricer, err := awsinternal.NewPricer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ec2Client *ec2.Client
+	if spot {
+		if ec2Client, err = awsinternal.GetEC2Client(ctx); err != nil {
+			return nil, err
+		}
+	}
 	
 	// Show loading spinner while fetching pricing
 	fmt.Println()
@@ -264,36 +513,31 @@ func getInstanceTypes(ctx context.Context, isDev bool) ([]ui.InstanceType, error
 	
 	// Channel to signal completion
 	done := make(chan []ui.InstanceType, 1)
-	errorChan := make(chan error, 1)
 	
 	// Start the pricing fetch in a goroutine
 	go func() {
-		var instances []ui.InstanceType
-		
-		for _, spec := range instanceSpecs {
-			pricingInfo, err := awsinternal.GetInstancePricing(ctx, spec.Type)
-			if err != nil {
-				log.Error("Failed to get pricing for instance type", "instance", spec.Type, "error", err)
-				continue
+		instances := make([]ui.InstanceType, len(instanceSpecs))
+		awsinternal.ForEachLimited(len(instanceSpecs), awsinternal.DefaultPricingParallelism, func(i int) {
+			instances[i] = priceInstanceSpec(ctx, pricer, ec2Client, instanceSpecs[i], volume, spot)
+		})
+
+		priced, estimated := 0, 0
+		for _, instance := range instances {
+			if instance.MonthlyCost != priceUnavailable {
+				priced++
 			}
-			
-			instances = append(instances, ui.InstanceType{
-				Type:        spec.Type,
-				VCPUs:       spec.CPU,
-				Memory:      spec.Memory,
-				Disk:        "100 GB",
-				MonthlyCost: pricingInfo.FormattedPrice,
-			})
-			
-			log.Debug("Got pricing for instance", "instance", spec.Type, "price", pricingInfo.FormattedPrice)
-		}
-		
-		if len(instances) == 0 {
-			errorChan <- fmt.Errorf("failed to fetch pricing for any instance types")
-			return
+			if instance.Estimated {
+				estimated++
+			}
+		}
+		switch {
+		case priced == 0:
+			log.Warn("Pricing is unavailable for every instance type, you can still choose one")
+		case estimated > 0:
+			log.Warn("Live pricing unavailable, showing estimated prices", "snapshot", awsinternal.EmbeddedPricingSnapshot().GeneratedAt, "instances", estimated)
+		default:
+			log.Info("Successfully fetched pricing data", "instances", priced)
 		}
-		
-		log.Info("Successfully fetched pricing data", "instances", len(instances))
 		done <- instances
 	}()
 	
@@ -304,28 +548,150 @@ func getInstanceTypes(ctx context.Context, isDev bool) ([]ui.InstanceType, error
 	}()
 	
 	// Wait for completion
-	select {
-	case instances := <-done:
-		loadingModel.Finish()
-		p.Quit()
-		fmt.Print("\r\033[K") // Clear the spinner line
-		return instances, nil
-	case err := <-errorChan:
-		loadingModel.Finish()
-		p.Quit()
-		fmt.Print("\r\033[K") // Clear the spinner line
-		return nil, err
+	instances := <-done
+	loadingModel.Finish()
+	p.Quit()
+	fmt.Print("\r\033[K") // Clear the spinner line
+	return instances, nil
+}
+
+// priceInstanceSpec fetches on-demand and, when requested, spot pricing for one
+// instance type. Failures are shown in the picker rather than dropping the type
+func priceInstanceSpec(ctx context.Context, pricer *awsinternal.Pricer, ec2Client *ec2.Client, spec instanceSpec, volume awsinternal.EBSVolume, spot bool) ui.InstanceType {
+	instance := ui.InstanceType{
+		Type:        spec.Type,
+		VCPUs:       spec.CPU,
+		Memory:      spec.Memory,
+		Disk:        fmt.Sprintf("%d GB", volume.SizeGB),
+		HourlyCost:  priceUnavailable,
+		MonthlyCost: priceUnavailable,
+	}
+
+	pricingInfo, err := pricer.InstancePricing(ctx, spec.Type, volume)
+	if err != nil {
+		log.Warn("Failed to get pricing for instance type", "instance", spec.Type, "error", err)
+	} else {
+		instance.HourlyCost = pricingInfo.FormattedHourly
+		instance.MonthlyCost = pricingInfo.FormattedPrice
+		instance.Estimated = pricingInfo.Estimated
+		log.Debug("Got pricing for instance", "instance", spec.Type, "price", pricingInfo.FormattedPrice)
+	}
+
+	if spot {
+		spotPrice, err := awsinternal.GetSpotPriceWithClient(ctx, ec2Client, spec.Type)
+		switch {
+		case err != nil:
+			log.Debug("Failed to get spot price for instance type", "instance", spec.Type, "error", err)
+			instance.SpotCost = "unavailable"
+		case pricingInfo == nil:
+			// Spot compute is known but the storage price is not
+			instance.SpotCost = fmt.Sprintf("$%.4f/hour", spotPrice.HourlyPrice)
+		default:
+			// Spot compute plus the same EBS storage as on-demand
+			spotMonthly := spotPrice.HourlyPrice*pricingInfo.HoursPerMonth + pricingInfo.EBSPrice
+			instance.SpotCost = fmt.Sprintf("$%.2f/month", spotMonthly)
+		}
+	}
+
+	return instance
+}
+
+// rootVolume builds and validates the root volume from the --volume-* flags
+func rootVolume(volumeType string, sizeGB, iops, throughput int32) (awsinternal.EBSVolume, error) {
+	volume := awsinternal.EBSVolume{Type: volumeType, SizeGB: sizeGB, IOPS: iops, Throughput: throughput}
+	// Throughput optimized and cold HDD volumes cannot be boot volumes
+	if volumeType == awsinternal.VolumeTypeST1 || volumeType == awsinternal.VolumeTypeSC1 {
+		return volume, fmt.Errorf("%s volumes cannot be used as a root volume", volumeType)
+	}
+	if err := volume.Validate(); err != nil {
+		return volume, fmt.Errorf("invalid root volume: %w", err)
+	}
+	return volume, nil
+}
+
+// rootBlockDevice returns the EBS settings for the root volume
+func rootBlockDevice(volume awsinternal.EBSVolume) *types.EbsBlockDevice {
+	device := &types.EbsBlockDevice{
+		VolumeSize:          aws.Int32(volume.SizeGB),
+		VolumeType:          types.VolumeType(volume.Type),
+		DeleteOnTermination: aws.Bool(true),
+		Encrypted:           aws.Bool(true),
+	}
+	if volume.IOPS > 0 {
+		device.Iops = aws.Int32(volume.IOPS)
+	}
+	if volume.Throughput > 0 {
+		device.Throughput = aws.Int32(volume.Throughput)
+	}
+	return device
+}
+
+// billingHoursNote tells users when monthly prices assume less than a full month of use
+func billingHoursNote(hours float64) string {
+	if hours == awsinternal.HoursPerMonth {
+		return ""
+	}
+	return fmt.Sprintf("monthly prices assume %g hours of use", hours)
+}
+
+// estimatedPricingNote explains "~" prices in the picker when any came from the
+// offline snapshot, or returns "" when all prices are live
+func estimatedPricingNote(instances []ui.InstanceType) string {
+	for _, instance := range instances {
+		if instance.Estimated {
+			return fmt.Sprintf("~ estimated from %s price snapshot, live pricing unavailable", awsinternal.EmbeddedPricingSnapshot().GeneratedAt)
+		}
+	}
+	return ""
+}
+
+// buildUserData renders the cloud-init document for the selected template, the
+// optional user data file and the Clouddley key, returning it base64 encoded
+func buildUserData(templateName, userDataPath string, clouddleyKey bool) (string, error) {
+	var custom []byte
+	if userDataPath != "" {
+		content, err := os.ReadFile(userDataPath)
+		if err != nil {
+			return "", fmt.Errorf("failed to read user data from %s: %w", userDataPath, err)
+		}
+		custom = content
+	}
+
+	var keys []string
+	if clouddleyKey {
+		keys = clouddleykey.PublicKeys()
+	}
+
+	rendered, err := cloudinit.Render(cloudinit.Options{
+		Template:      templateName,
+		UserData:      custom,
+		ClouddleyKeys: keys,
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(rendered) == 0 {
+		return "", nil
+	}
+
+	encoded, err := cloudinit.Encode(rendered)
+	if err != nil {
+		return "", err
 	}
+
+	log.Debug("Rendered user data", "template", templateName, "bytes", len(rendered))
+	return encoded, nil
 }
 
 type InstanceInfo struct {
 	InstanceID string
 	Name       string
 	PublicIP   string
+	ElasticIP  bool
 	Region     string
 }
 
-func createInstance(ctx context.Context, instanceType string, sshKey *awsinternal.SSHKeyInfo) (*InstanceInfo, error) {
+func createInstance(ctx context.Context, instanceType string, sshKey *awsinternal.SSHKeyInfo, opts createOptions) (*InstanceInfo, error) {
 	client, err := awsinternal.GetEC2Client(ctx)
 	if err != nil {
 		return nil, err
@@ -337,14 +703,27 @@ func createInstance(ctx context.Context, instanceType string, sshKey *awsinterna
 		return nil, err
 	}
 
-	// Get default VPC and subnet
-	vpc, subnet, err := getDefaultVPCAndSubnet(ctx, client)
+	// Pick the VPC and subnet, defaulting to the default VPC
+	vpc, subnet, err := resolvePlacement(ctx, client, instanceType, opts.Placement)
+	if err != nil {
+		return nil, err
+	}
+
+	// Generate instance name
+	instanceName := fmt.Sprintf("clouddley-vm-%d", time.Now().Unix())
+
+	ipv6, err := subnetHasIPv6(ctx, client, subnet)
 	if err != nil {
 		return nil, err
 	}
 
 	// Create security group if needed
-	sgID, err := createOrGetSecurityGroup(ctx, client, vpc)
+	sgName := sharedSecurityGroupName
+	if opts.Firewall.Dedicated {
+		sgName = instanceName + "-sg"
+	}
+	rules := defaultIngressRules(opts.Firewall.SSHCIDRs, opts.Firewall.OpenPorts, ipv6)
+	sgID, err := createOrGetSecurityGroup(ctx, client, vpc, sgName, rules)
 	if err != nil {
 		return nil, err
 	}
@@ -355,27 +734,19 @@ func createInstance(ctx context.Context, instanceType string, sshKey *awsinterna
 		return nil, err
 	}
 
-	// Generate instance name
-	instanceName := fmt.Sprintf("clouddley-vm-%d", time.Now().Unix())
-
 	// Create instance
-	runResult, err := client.RunInstances(ctx, &ec2.RunInstancesInput{
+	runInput := &ec2.RunInstancesInput{
 		ImageId:      aws.String(amiID),
 		InstanceType: types.InstanceType(instanceType),
 		MinCount:     aws.Int32(1),
 		MaxCount:     aws.Int32(1),
-		KeyName:      aws.String("clouddley-default-key"),
-		SecurityGroupIds: []string{sgID},
-		SubnetId:     aws.String(subnet),
+		KeyName:      aws.String(opts.KeyName),
+		// The key install and readiness checks need a public IP, whatever the subnet's default
+		NetworkInterfaces: publicNetworkInterface(subnet, sgID),
 		BlockDeviceMappings: []types.BlockDeviceMapping{
 			{
 				DeviceName: aws.String("/dev/sda1"), // Root device for Ubuntu
-				Ebs: &types.EbsBlockDevice{
-					VolumeSize:          aws.Int32(100), // 100GB
-					VolumeType:          types.VolumeTypeGp3,
-					DeleteOnTermination: aws.Bool(true),
-					Encrypted:           aws.Bool(true),
-				},
+				Ebs:        rootBlockDevice(opts.Volume),
 			},
 		},
 		TagSpecifications: []types.TagSpecification{
@@ -393,7 +764,18 @@ func createInstance(ctx context.Context, instanceType string, sshKey *awsinterna
 				},
 			},
 		},
-	})
+	}
+	if opts.UserData != "" {
+		runInput.UserData = aws.String(opts.UserData)
+	}
+	if opts.Spot != nil {
+		runInput.InstanceMarketOptions = opts.Spot
+		if opts.Spot.SpotOptions.InstanceInterruptionBehavior == types.InstanceInterruptionBehaviorHibernate {
+			runInput.HibernationOptions = &types.HibernationOptionsRequest{Configured: aws.Bool(true)}
+		}
+	}
+
+	runResult, err := client.RunInstances(ctx, runInput)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create instance: %w", err)
 	}
@@ -447,15 +829,31 @@ func createInstance(ctx context.Context, instanceType string, sshKey *awsinterna
 		publicIP = *instance.PublicIpAddress
 	}
 
+	elastic := false
+	if opts.ElasticIP {
+		address, err := attachNewElasticIP(ctx, client, instanceName+"-eip", instanceID)
+		if err != nil {
+			// The instance is usable with its auto-assigned address, so don't fail the launch
+			log.Warn("Could not attach Elastic IP, keeping the auto-assigned address", "error", err)
+		} else {
+			publicIP = aws.ToString(address.PublicIp)
+			elastic = true
+		}
+	}
+
 	return &InstanceInfo{
 		InstanceID: instanceID,
 		Name:       instanceName,
 		PublicIP:   publicIP,
+		ElasticIP:  elastic,
 		Region:     cfg.Region,
 	}, nil
 }
 
-func getDefaultVPCAndSubnet(ctx context.Context, client *ec2.Client) (string, string, error) {
+// getDefaultVPCAndSubnet returns the default VPC, or the Clouddley-managed VPC when
+// the region has no default VPC, and the first subnet with free IPs whose AZ offers
+// the instance type, optionally restricted to one AZ
+func getDefaultVPCAndSubnet(ctx context.Context, client *ec2.Client, az string, offered map[string]bool) (string, string, error) {
 	// Get default VPC
 	vpcResult, err := client.DescribeVpcs(ctx, &ec2.DescribeVpcsInput{
 		Filters: []types.Filter{
@@ -469,41 +867,69 @@ func getDefaultVPCAndSubnet(ctx context.Context, client *ec2.Client) (string, st
 		return "", "", fmt.Errorf("failed to describe VPCs: %w", err)
 	}
 
-	if len(vpcResult.Vpcs) == 0 {
-		return "", "", fmt.Errorf("no default VPC found")
+	var vpcID string
+	var subnetFilter types.Filter
+
+	if len(vpcResult.Vpcs) > 0 {
+		vpcID = *vpcResult.Vpcs[0].VpcId
+		subnetFilter = types.Filter{
+			Name:   aws.String("default-for-az"),
+			Values: []string{"true"},
+		}
+	} else {
+		// Fall back to the network created by 'clouddley vm aws network init'
+		vpcID, err = getManagedVPC(ctx, client)
+		if err != nil {
+			return "", "", err
+		}
+		if vpcID == "" {
+			return "", "", errNoDefaultVPC
+		}
+		log.Info("No default VPC found, using Clouddley-managed VPC", "vpc", vpcID)
+		subnetFilter = managedNetworkFilter()
 	}
 
-	vpcID := *vpcResult.Vpcs[0].VpcId
+	filters := []types.Filter{
+		{
+			Name:   aws.String("vpc-id"),
+			Values: []string{vpcID},
+		},
+		subnetFilter,
+	}
+	if az != "" {
+		filters = append(filters, types.Filter{
+			Name:   aws.String("availability-zone"),
+			Values: []string{az},
+		})
+	}
 
-	// Get first available subnet in default VPC
 	subnetResult, err := client.DescribeSubnets(ctx, &ec2.DescribeSubnetsInput{
-		Filters: []types.Filter{
-			{
-				Name:   aws.String("vpc-id"),
-				Values: []string{vpcID},
-			},
-			{
-				Name:   aws.String("default-for-az"),
-				Values: []string{"true"},
-			},
-		},
+		Filters: filters,
 	})
 	if err != nil {
 		return "", "", fmt.Errorf("failed to describe subnets: %w", err)
 	}
 
 	if len(subnetResult.Subnets) == 0 {
+		if az != "" {
+			return "", "", fmt.Errorf("no default subnet found in %s", az)
+		}
 		return "", "", fmt.Errorf("no default subnet found")
 	}
 
-	subnetID := *subnetResult.Subnets[0].SubnetId
+	candidates := eligibleSubnets(subnetResult.Subnets, offered)
+	if len(candidates) == 0 {
+		return "", "", fmt.Errorf("no default subnet with free IPs is in an availability zone offering this instance type")
+	}
+
+	subnetID := *candidates[0].SubnetId
 
 	return vpcID, subnetID, nil
 }
 
-func createOrGetSecurityGroup(ctx context.Context, client *ec2.Client, vpcID string) (string, error) {
-	sgName := "clouddley-default-sg"
-	
+// createOrGetSecurityGroup finds or creates the named security group in the VPC
+// and adds any of the given ingress rules it is missing
+func createOrGetSecurityGroup(ctx context.Context, client *ec2.Client, vpcID, sgName string, rules []ingressRule) (string, error) {
 	// Check if security group exists
 	result, err := client.DescribeSecurityGroups(ctx, &ec2.DescribeSecurityGroupsInput{
 		Filters: []types.Filter{
@@ -522,148 +948,122 @@ func createOrGetSecurityGroup(ctx context.Context, client *ec2.Client, vpcID str
 	}
 
 	var sgID string
-	var needsRules bool
+	var existing []types.IpPermission
 
 	if len(result.SecurityGroups) > 0 {
 		sgID = *result.SecurityGroups[0].GroupId
-		
-		// Check if all required ports are already open
-		sg := result.SecurityGroups[0]
-		hasSSH := false
-		hasHTTP := false
-		hasHTTPS := false
-		
-		for _, rule := range sg.IpPermissions {
-			if rule.IpProtocol != nil && *rule.IpProtocol == "tcp" {
-				if rule.FromPort != nil && rule.ToPort != nil {
-					port := *rule.FromPort
-					// Check if rule allows 0.0.0.0/0
-					for _, ipRange := range rule.IpRanges {
-						if ipRange.CidrIp != nil && *ipRange.CidrIp == "0.0.0.0/0" {
-							switch port {
-							case 22:
-								hasSSH = true
-							case 80:
-								hasHTTP = true
-							case 443:
-								hasHTTPS = true
-							}
-						}
-					}
-				}
-			}
+		existing = result.SecurityGroups[0].IpPermissions
+
+		if hasWorldSSH(existing) {
+			log.Warn("Security group allows SSH from anywhere, run 'clouddley vm aws firewall reset' to restrict it", "group", sgName, "id", sgID)
 		}
-		
-		needsRules = !hasSSH || !hasHTTP || !hasHTTPS
 	} else {
 		// Create security group
 		createResult, err := client.CreateSecurityGroup(ctx, &ec2.CreateSecurityGroupInput{
 			GroupName:   aws.String(sgName),
-			Description: aws.String("Clouddley CLI default security group"),
+			Description: aws.String("Clouddley CLI security group"),
 			VpcId:       aws.String(vpcID),
+			TagSpecifications: []types.TagSpecification{
+				{
+					ResourceType: types.ResourceTypeSecurityGroup,
+					Tags: []types.Tag{
+						{
+							Key:   aws.String("Name"),
+							Value: aws.String(sgName),
+						},
+						{
+							Key:   aws.String("CreatedBy"),
+							Value: aws.String("Clouddley"),
+						},
+					},
+				},
+			},
 		})
 		if err != nil {
 			return "", fmt.Errorf("failed to create security group: %w", err)
 		}
 		sgID = *createResult.GroupId
-		needsRules = true
 	}
 
-	// Add missing rules if needed
-	if needsRules {
-		// Build permissions for missing rules only
-		var permissions []types.IpPermission
-		
-		// Get current rules to avoid duplicates
-		currentSG, err := client.DescribeSecurityGroups(ctx, &ec2.DescribeSecurityGroupsInput{
-			GroupIds: []string{sgID},
+	// Only authorize the rules that are not already present
+	missing := missingRules(existing, rules)
+	if sgName == sharedSecurityGroupName && len(existing) > 0 {
+		for _, rule := range missing {
+			if rule.FromPort == 22 {
+				log.Warn("Adding SSH source to the shared security group; it stays open for every instance using it until 'clouddley vm aws firewall reset'", "group", sgName, "cidr", rule.CIDR)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		_, err = client.AuthorizeSecurityGroupIngress(ctx, &ec2.AuthorizeSecurityGroupIngressInput{
+			GroupId:       aws.String(sgID),
+			IpPermissions: toPermissions(missing),
 		})
 		if err != nil {
-			return "", fmt.Errorf("failed to describe security group: %w", err)
-		}
-		
-		hasSSH := false
-		hasHTTP := false
-		hasHTTPS := false
-		
-		if len(currentSG.SecurityGroups) > 0 {
-			for _, rule := range currentSG.SecurityGroups[0].IpPermissions {
-				if rule.IpProtocol != nil && *rule.IpProtocol == "tcp" && rule.FromPort != nil {
-					port := *rule.FromPort
-					for _, ipRange := range rule.IpRanges {
-						if ipRange.CidrIp != nil && *ipRange.CidrIp == "0.0.0.0/0" {
-							switch port {
-							case 22:
-								hasSSH = true
-							case 80:
-								hasHTTP = true
-							case 443:
-								hasHTTPS = true
-							}
-						}
-					}
-				}
-			}
+			return "", fmt.Errorf("failed to add security group rules: %w", err)
 		}
-		
-		// Add SSH rule if missing
-		if !hasSSH {
-			permissions = append(permissions, types.IpPermission{
-				IpProtocol: aws.String("tcp"),
-				FromPort:   aws.Int32(22),
-				ToPort:     aws.Int32(22),
-				IpRanges: []types.IpRange{
-					{
-						CidrIp:      aws.String("0.0.0.0/0"),
-						Description: aws.String("SSH access"),
-					},
-				},
-			})
-		}
-		
-		// Add HTTP rule if missing
-		if !hasHTTP {
-			permissions = append(permissions, types.IpPermission{
-				IpProtocol: aws.String("tcp"),
-				FromPort:   aws.Int32(80),
-				ToPort:     aws.Int32(80),
-				IpRanges: []types.IpRange{
-					{
-						CidrIp:      aws.String("0.0.0.0/0"),
-						Description: aws.String("HTTP access"),
-					},
-				},
-			})
-		}
-		
-		// Add HTTPS rule if missing
-		if !hasHTTPS {
-			permissions = append(permissions, types.IpPermission{
-				IpProtocol: aws.String("tcp"),
-				FromPort:   aws.Int32(443),
-				ToPort:     aws.Int32(443),
-				IpRanges: []types.IpRange{
-					{
-						CidrIp:      aws.String("0.0.0.0/0"),
-						Description: aws.String("HTTPS access"),
-					},
-				},
-			})
-		}
-		
-		// Only authorize if we have permissions to add
-		if len(permissions) > 0 {
-			_, err = client.AuthorizeSecurityGroupIngress(ctx, &ec2.AuthorizeSecurityGroupIngressInput{
-				GroupId:       aws.String(sgID),
-				IpPermissions: permissions,
-			})
-			if err != nil {
-				return "", fmt.Errorf("failed to add security group rules: %w", err)
+	}
+
+	return sgID, nil
+}
+
+// publicNetworkInterface is the primary network interface of a new instance, with a
+// public IP even in subnets that do not assign one on launch
+func publicNetworkInterface(subnetID, sgID string) []types.InstanceNetworkInterfaceSpecification {
+	return []types.InstanceNetworkInterfaceSpecification{
+		{
+			DeviceIndex:              aws.Int32(0),
+			SubnetId:                 aws.String(subnetID),
+			Groups:                   []string{sgID},
+			AssociatePublicIpAddress: aws.Bool(true),
+		},
+	}
+}
+
+// subnetHasIPv6 reports whether the subnet has an associated IPv6 CIDR block
+func subnetHasIPv6(ctx context.Context, client *ec2.Client, subnetID string) (bool, error) {
+	result, err := client.DescribeSubnets(ctx, &ec2.DescribeSubnetsInput{
+		SubnetIds: []string{subnetID},
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to describe subnet: %w", err)
+	}
+
+	for _, subnet := range result.Subnets {
+		for _, assoc := range subnet.Ipv6CidrBlockAssociationSet {
+			if assoc.Ipv6CidrBlockState != nil && assoc.Ipv6CidrBlockState.State == types.SubnetCidrBlockStateCodeAssociated {
+				return true, nil
 			}
 		}
 	}
 
-	return sgID, nil
+	return false, nil
+}
+
+// resolveSSHCIDRs validates the --ssh-cidr values, or detects the caller's public IP when none are given
+func resolveSSHCIDRs(ctx context.Context, flags []string) ([]string, error) {
+	if len(flags) == 0 {
+		ip, err := awsinternal.DetectPublicIP(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("%w; pass --ssh-cidr to choose who can SSH to the instance", err)
+		}
+		cidr := awsinternal.HostCIDR(ip)
+		log.Info("Restricting SSH to your public IP", "cidr", cidr)
+		return []string{cidr}, nil
+	}
+
+	var cidrs []string
+	for _, flag := range flags {
+		cidr, err := normalizeCIDR(flag)
+		if err != nil {
+			return nil, err
+		}
+		if cidr == anyIPv4 || cidr == anyIPv6 {
+			log.Warn("SSH will be open to the whole internet", "cidr", cidr)
+		}
+		cidrs = append(cidrs, cidr)
+	}
+	return cidrs, nil
 }
 
 func getLatestUbuntuAMI(ctx context.Context, client *ec2.Client) (string, error) {
@@ -705,66 +1105,3 @@ func getLatestUbuntuAMI(ctx context.Context, client *ec2.Client) (string, error)
 
 	return "", fmt.Errorf("no Ubuntu AMI found")
 }
-
-// installClouddleyKey installs the Clouddley public key on the VM via SSH
-func installClouddleyKey(ctx context.Context, publicIP string) error {
-	// The Clouddley triggr public key (same as in cmd/triggr.go)
-	sshPublicKey := `ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAACAQCYDppnNM+F+GtFWaVJXsvobX/i/2uZuLch9386ZEyVtGE1QmRjRkvwEHwFM23STuzbtmqTrYjEnmv3Xkywk7wE0r+OoJxTBIwJP+scg9rAu//3N6CoAKH0Ra1XgdRj8QzqF/1mm4T/Pxtzz3JSpSKwpzW3GtU4NcHuaPAAHavCpahCnZqpPMU90FgRCS9lSmw0EPQcU8kxxeEpFjifip4JBBx/WQuh/8KkBAX/DnWSAO9ynGzPMvOvWPTtQMi7IA7Y8vRWeThfpC/fnU8Tub+99w5h2Y1TnWtUrM49ZMa9WSLtP/+4xKieQPObq0JuX6itNFuuwbb/WHLgOYeZqQTdSeMc6GlSkqniYiAUAv7olBUERHf7QkD7hPOlaw9S/0MCU8DcuujZG2i6UvIkQ60dikvsX8rCiPvfN4Nw1mWh0a1rf9vUxTyCCb+7hh1iPV6RwMx6T4nBjFNjBglHFkYIE5kevLyX2vREJJen+GfZO2GVcnHaNRHBvXZVVEbwt1xRWhAOS+FFtcKUNV+54JsKTaZUEYvfwe/KNjEeOxucljkiK9IYw0IGXB9dtueOTKcirLhpGE9t6LqDhWE05kr0fl/hmnT/g9fHeZDm4jOF71iHogsrZtU5pH8QtTNhaffMkW4EJc+4W0a+boE+/S5Xracbr7D1WBhGC2epXkUWHw== "clouddley-triggr-public-key"`
-
-	log.Debug("Installing Clouddley public key", "host", publicIP)
-
-	// SSH command to install the key
-	sshCommand := fmt.Sprintf(`
-		# Create .ssh directory if it doesn't exist
-		mkdir -p ~/.ssh
-		chmod 700 ~/.ssh
-		
-		# Add the public key to authorized_keys if not already present
-		if ! grep -q "clouddley-triggr-public-key" ~/.ssh/authorized_keys 2>/dev/null; then
-			echo '%s' >> ~/.ssh/authorized_keys
-			chmod 600 ~/.ssh/authorized_keys
-			echo "Clouddley public key installed successfully"
-		else
-			echo "Clouddley public key already exists"
-		fi
-	`, sshPublicKey)
-
-	// Execute SSH command with timeout
-	cmd := exec.CommandContext(ctx, "ssh", 
-		"-o", "StrictHostKeyChecking=no",
-		"-o", "UserKnownHostsFile=/dev/null",
-		"-o", "ConnectTimeout=30",
-		"-o", "ServerAliveInterval=5",
-		"-o", "ServerAliveCountMax=3",
-		fmt.Sprintf("ubuntu@%s", publicIP),
-		sshCommand)
-
-	log.Debug("Executing SSH command", "command", cmd.String())
-
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("SSH command failed: %w, output: %s", err, string(output))
-	}
-
-	log.Debug("SSH command output", "output", string(output))
-
-	// Verify the key was installed by checking if it exists
-	verifyCmd := exec.CommandContext(ctx, "ssh",
-		"-o", "StrictHostKeyChecking=no",
-		"-o", "UserKnownHostsFile=/dev/null",
-		"-o", "ConnectTimeout=30",
-		fmt.Sprintf("ubuntu@%s", publicIP),
-		"grep -q 'clouddley-triggr-public-key' ~/.ssh/authorized_keys && echo 'KEY_FOUND' || echo 'KEY_NOT_FOUND'")
-
-	verifyOutput, err := verifyCmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("verification command failed: %w, output: %s", err, string(verifyOutput))
-	}
-
-	if !strings.Contains(string(verifyOutput), "KEY_FOUND") {
-		return fmt.Errorf("key installation verification failed: %s", string(verifyOutput))
-	}
-
-	log.Debug("Key installation verified successfully")
-	return nil
-}
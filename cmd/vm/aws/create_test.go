@@ -2,12 +2,18 @@ package aws
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"strings"
 	"testing"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	awsinternal "github.com/clouddley/clouddley/internal/aws"
+	"github.com/clouddley/clouddley/internal/clouddleykey"
+	"github.com/clouddley/clouddley/internal/cloudinit"
+	"github.com/clouddley/clouddley/internal/ui"
 )
 
 // Mock EC2 client for create operations
@@ -498,6 +504,146 @@ func validateInstanceType(instanceType string) bool {
 	return strings.Contains(instanceType, ".") && len(instanceType) > 3
 }
 
+func TestEstimatedPricingNote(t *testing.T) {
+	live := ui.InstanceType{Type: "t3.micro", MonthlyCost: "$15.59/month"}
+	estimated := ui.InstanceType{Type: "t3.small", MonthlyCost: "~$23.18/month", Estimated: true}
+	unavailable := ui.InstanceType{Type: "t3.nano", MonthlyCost: priceUnavailable}
+
+	if note := estimatedPricingNote([]ui.InstanceType{live, unavailable}); note != "" {
+		t.Errorf("estimatedPricingNote() with live prices = %q, want empty", note)
+	}
+
+	note := estimatedPricingNote([]ui.InstanceType{live, estimated})
+	if !strings.Contains(note, "estimated") || !strings.Contains(note, "snapshot") {
+		t.Errorf("estimatedPricingNote() = %q, want it to mention the estimated snapshot", note)
+	}
+}
+
+func TestRootVolume(t *testing.T) {
+	tests := []struct {
+		name           string
+		volumeType     string
+		size           int32
+		iops           int32
+		throughput     int32
+		wantErr        bool
+		wantIops       *int32
+		wantThroughput *int32
+	}{
+		{name: "default gp3", volumeType: "gp3", size: 100},
+		{name: "gp3 with performance", volumeType: "gp3", size: 200, iops: 6000, throughput: 250, wantIops: int32Ptr(6000), wantThroughput: int32Ptr(250)},
+		{name: "io2", volumeType: "io2", size: 100, iops: 10000, wantIops: int32Ptr(10000)},
+		{name: "st1 cannot boot", volumeType: "st1", size: 500, wantErr: true},
+		{name: "io1 needs iops", volumeType: "io1", size: 100, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			volume, err := rootVolume(tt.volumeType, tt.size, tt.iops, tt.throughput)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("rootVolume() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			device := rootBlockDevice(volume)
+			if *device.VolumeSize != tt.size || string(device.VolumeType) != tt.volumeType {
+				t.Errorf("device = %d GB %s, want %d GB %s", *device.VolumeSize, device.VolumeType, tt.size, tt.volumeType)
+			}
+			if (device.Iops == nil) != (tt.wantIops == nil) || (device.Iops != nil && *device.Iops != *tt.wantIops) {
+				t.Errorf("device Iops = %v, want %v", device.Iops, tt.wantIops)
+			}
+			if (device.Throughput == nil) != (tt.wantThroughput == nil) || (device.Throughput != nil && *device.Throughput != *tt.wantThroughput) {
+				t.Errorf("device Throughput = %v, want %v", device.Throughput, tt.wantThroughput)
+			}
+			if !*device.Encrypted || !*device.DeleteOnTermination {
+				t.Error("root volume must be encrypted and deleted on termination")
+			}
+		})
+	}
+}
+
+func TestBuildUserData_ClouddleyKey(t *testing.T) {
+	userData, err := buildUserData(cloudinit.TemplateNone, "", true)
+	if err != nil {
+		t.Fatalf("buildUserData() error = %v", err)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(userData)
+	if err != nil {
+		t.Fatalf("user data is not base64: %v", err)
+	}
+	// The key is installed at boot even without a template or user data
+	if !strings.Contains(string(decoded), "ssh_authorized_keys:") || !strings.Contains(string(decoded), keyFields(clouddleykey.PublicKey())) {
+		t.Errorf("default user data does not install the Clouddley key:\n%s", decoded)
+	}
+
+	userData, err = buildUserData(cloudinit.TemplateNone, "", false)
+	if err != nil {
+		t.Fatalf("buildUserData() error = %v", err)
+	}
+	if userData != "" {
+		t.Errorf("buildUserData() with --no-clouddley-key = %q, want no user data", userData)
+	}
+}
+
+func TestCloudInitSummary(t *testing.T) {
+	tests := []struct {
+		name         string
+		template     string
+		custom       bool
+		clouddleyKey bool
+		want         string
+	}{
+		{name: "key only", template: cloudinit.TemplateNone, clouddleyKey: true, want: "Cloud-init installs the Clouddley public key at boot."},
+		{name: "template and user data", template: "docker", custom: true, clouddleyKey: true, want: "the Clouddley public key and the docker template and your user data"},
+		{name: "nothing", template: cloudinit.TemplateNone, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := cloudInitSummary(tt.template, tt.custom, tt.clouddleyKey)
+			if tt.want == "" {
+				if got != "" {
+					t.Errorf("cloudInitSummary() = %q, want empty", got)
+				}
+				return
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("cloudInitSummary() = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPublicNetworkInterface(t *testing.T) {
+	interfaces := publicNetworkInterface("subnet-private", "sg-123")
+	if len(interfaces) != 1 {
+		t.Fatalf("got %d network interfaces, want 1", len(interfaces))
+	}
+
+	primary := interfaces[0]
+	if aws.ToInt32(primary.DeviceIndex) != 0 || aws.ToString(primary.SubnetId) != "subnet-private" {
+		t.Errorf("interface = device %d in %s, want device 0 in subnet-private", aws.ToInt32(primary.DeviceIndex), aws.ToString(primary.SubnetId))
+	}
+	if len(primary.Groups) != 1 || primary.Groups[0] != "sg-123" {
+		t.Errorf("Groups = %v, want [sg-123]", primary.Groups)
+	}
+	// Subnets that do not map public IPs on launch must still get one
+	if !aws.ToBool(primary.AssociatePublicIpAddress) {
+		t.Error("AssociatePublicIpAddress = false, want true")
+	}
+}
+
+func TestBillingHoursNote(t *testing.T) {
+	if note := billingHoursNote(awsinternal.HoursPerMonth); note != "" {
+		t.Errorf("billingHoursNote() for a full month = %q, want empty", note)
+	}
+	if note := billingHoursNote(220); note != "monthly prices assume 220 hours of use" {
+		t.Errorf("billingHoursNote(220) = %q", note)
+	}
+}
+
 // Helper functions
 func stringPtr(s string) *string {
 	return &s
@@ -507,6 +653,11 @@ func int32Ptr(i int32) *int32 {
 	return &i
 }
 
+// keyFields returns the type and base64 body of an authorized_keys line
+func keyFields(publicKey string) string {
+	return strings.Join(strings.Fields(publicKey)[:2], " ")
+}
+
 // Interface for testing EC2 create operations
 type EC2CreateAPI interface {
 	RunInstances(ctx context.Context, params *ec2.RunInstancesInput, optFns ...func(*ec2.Options)) (*ec2.RunInstancesOutput, error)
@@ -7,7 +7,9 @@ import (
 	"os"
 	"strings"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	awsinternal "github.com/clouddley/clouddley/internal/aws"
 	"github.com/clouddley/clouddley/internal/log"
 	"github.com/clouddley/clouddley/internal/ui"
@@ -18,13 +20,18 @@ var deleteCmd = &cobra.Command{
 	Use:     "delete --id <instance-id1,instance-id2,...> [--yes]",
 	Aliases: []string{"del", "d"},
 	Short:   "Delete (terminate) one or more AWS EC2 instances",
-	Long:    `Delete (terminate) one or more AWS EC2 instances that were created by the Clouddley CLI. Supports comma-separated instance IDs.`,
+	Long:    `Delete (terminate) one or more AWS EC2 instances that were created by the Clouddley CLI. Supports comma-separated instance IDs.
+
+Clouddley Elastic IPs attached to deleted instances keep billing until released.
+You are asked whether to release them; with --yes they are kept unless --release-ip is set.
+The spot request of a spot instance is cancelled first so AWS does not launch a replacement.`,
 	Run:     runDelete,
 }
 
 func init() {
 	deleteCmd.Flags().StringP("id", "i", "", "Instance ID(s) to delete - supports comma-separated list (required)")
 	deleteCmd.Flags().BoolP("yes", "y", false, "Skip confirmation prompt")
+	deleteCmd.Flags().Bool("release-ip", false, "Release Clouddley Elastic IPs attached to the deleted instances")
 	deleteCmd.MarkFlagRequired("id")
 }
 
@@ -33,6 +40,7 @@ func runDelete(cmd *cobra.Command, args []string) {
 
 	instanceIDsFlag, _ := cmd.Flags().GetString("id")
 	skipConfirmation, _ := cmd.Flags().GetBool("yes")
+	releaseIP, _ := cmd.Flags().GetBool("release-ip")
 
 	if instanceIDsFlag == "" {
 		fmt.Println(ui.FormatError("Error: --id flag is required"))
@@ -90,6 +98,12 @@ func runDelete(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	// Look up Elastic IPs before termination removes the association
+	addresses, err := getInstanceElasticIPs(ctx, client, instanceIDs...)
+	if err != nil {
+		log.Warn("Could not look up Elastic IPs", "error", err)
+	}
+
 	// Track results
 	var successful []string
 	var failed []string
@@ -109,6 +123,15 @@ func runDelete(cmd *cobra.Command, args []string) {
 		}
 	}
 
+	// Offer to release Elastic IPs of terminated instances
+	for _, address := range addresses {
+		instanceID := aws.ToString(address.InstanceId)
+		if !contains(successful, instanceID) {
+			continue
+		}
+		handleElasticIPOnDelete(ctx, client, address, skipConfirmation, releaseIP)
+	}
+
 	// Summary
 	fmt.Println()
 	if len(successful) > 0 {
@@ -121,7 +144,60 @@ func runDelete(cmd *cobra.Command, args []string) {
 	}
 }
 
-func terminateInstance(ctx context.Context, client *ec2.Client, instanceID string) error {
+// handleElasticIPOnDelete releases the Elastic IP of a deleted instance when confirmed
+func handleElasticIPOnDelete(ctx context.Context, client *ec2.Client, address types.Address, skipConfirmation, releaseIP bool) {
+	publicIP := aws.ToString(address.PublicIp)
+	allocationID := aws.ToString(address.AllocationId)
+
+	if !releaseIP {
+		if skipConfirmation {
+			fmt.Printf("Elastic IP %s (%s) was kept; release it with: clouddley vm aws ip release --allocation %s\n",
+				publicIP, allocationID, allocationID)
+			return
+		}
+
+		fmt.Printf("Release Elastic IP %s from instance %s? (y/n): ", publicIP, aws.ToString(address.InstanceId))
+		reader := bufio.NewReader(os.Stdin)
+		response, err := reader.ReadString('\n')
+		if err != nil {
+			fmt.Println(ui.FormatError(fmt.Sprintf("Error reading input: %v", err)))
+			return
+		}
+
+		response = strings.TrimSpace(strings.ToLower(response))
+		if response != "y" && response != "yes" {
+			fmt.Printf("Elastic IP %s kept (%s)\n", publicIP, allocationID)
+			return
+		}
+	}
+
+	if err := releaseElasticIP(ctx, client, address); err != nil {
+		log.Error("Failed to release Elastic IP", "allocation", allocationID, "error", err)
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+
+	log.Info("Elastic IP released", "ip", publicIP)
+	fmt.Println(ui.FormatOutput("✓ Success", fmt.Sprintf("Elastic IP %s released", publicIP)))
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ec2TerminateClient is the part of the EC2 API terminateInstance uses
+type ec2TerminateClient interface {
+	DescribeInstances(ctx context.Context, params *ec2.DescribeInstancesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error)
+	CancelSpotInstanceRequests(ctx context.Context, params *ec2.CancelSpotInstanceRequestsInput, optFns ...func(*ec2.Options)) (*ec2.CancelSpotInstanceRequestsOutput, error)
+	TerminateInstances(ctx context.Context, params *ec2.TerminateInstancesInput, optFns ...func(*ec2.Options)) (*ec2.TerminateInstancesOutput, error)
+}
+
+func terminateInstance(ctx context.Context, client ec2TerminateClient, instanceID string) error {
 	// First, check if the instance exists and get its current state
 	describeResult, err := client.DescribeInstances(ctx, &ec2.DescribeInstancesInput{
 		InstanceIds: []string{instanceID},
@@ -145,6 +221,18 @@ func terminateInstance(ctx context.Context, client *ec2.Client, instanceID strin
 		return fmt.Errorf("instance is already being terminated")
 	}
 
+	// A persistent spot request (stop or hibernate on interruption) would launch a
+	// replacement once the instance is gone, so cancel it first
+	if instance.SpotInstanceRequestId != nil {
+		_, err = client.CancelSpotInstanceRequests(ctx, &ec2.CancelSpotInstanceRequestsInput{
+			SpotInstanceRequestIds: []string{*instance.SpotInstanceRequestId},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to cancel spot request %s: %w", *instance.SpotInstanceRequestId, err)
+		}
+		log.Info("Cancelled spot request", "instance", instanceID, "request", *instance.SpotInstanceRequestId)
+	}
+
 	// Terminate the instance
 	_, err = client.TerminateInstances(ctx, &ec2.TerminateInstancesInput{
 		InstanceIds: []string{instanceID},
@@ -403,3 +403,81 @@ type EC2TerminateAPI interface {
 	DescribeInstances(ctx context.Context, params *ec2.DescribeInstancesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error)
 	TerminateInstances(ctx context.Context, params *ec2.TerminateInstancesInput, optFns ...func(*ec2.Options)) (*ec2.TerminateInstancesOutput, error)
 }
+
+// mockEC2SpotTerminateClient records the order of EC2 calls made by terminateInstance
+type mockEC2SpotTerminateClient struct {
+	instance  types.Instance
+	cancelErr error
+	calls     []string
+}
+
+func (m *mockEC2SpotTerminateClient) DescribeInstances(ctx context.Context, params *ec2.DescribeInstancesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error) {
+	m.calls = append(m.calls, "DescribeInstances")
+	return &ec2.DescribeInstancesOutput{
+		Reservations: []types.Reservation{{Instances: []types.Instance{m.instance}}},
+	}, nil
+}
+
+func (m *mockEC2SpotTerminateClient) CancelSpotInstanceRequests(ctx context.Context, params *ec2.CancelSpotInstanceRequestsInput, optFns ...func(*ec2.Options)) (*ec2.CancelSpotInstanceRequestsOutput, error) {
+	m.calls = append(m.calls, "CancelSpotInstanceRequests:"+strings.Join(params.SpotInstanceRequestIds, ","))
+	if m.cancelErr != nil {
+		return nil, m.cancelErr
+	}
+	return &ec2.CancelSpotInstanceRequestsOutput{}, nil
+}
+
+func (m *mockEC2SpotTerminateClient) TerminateInstances(ctx context.Context, params *ec2.TerminateInstancesInput, optFns ...func(*ec2.Options)) (*ec2.TerminateInstancesOutput, error) {
+	m.calls = append(m.calls, "TerminateInstances:"+strings.Join(params.InstanceIds, ","))
+	return &ec2.TerminateInstancesOutput{}, nil
+}
+
+func TestTerminateInstance_SpotRequest(t *testing.T) {
+	ctx := context.Background()
+	instanceID := "i-1234567890abcdef0"
+
+	tests := []struct {
+		name      string
+		requestID *string
+		cancelErr error
+		wantCalls []string
+		wantErr   bool
+	}{
+		{
+			name:      "persistent spot request is cancelled before termination",
+			requestID: stringPtr("sir-abc123"),
+			wantCalls: []string{"DescribeInstances", "CancelSpotInstanceRequests:sir-abc123", "TerminateInstances:" + instanceID},
+		},
+		{
+			name:      "on-demand instance",
+			wantCalls: []string{"DescribeInstances", "TerminateInstances:" + instanceID},
+		},
+		{
+			name:      "cancel failure keeps the instance",
+			requestID: stringPtr("sir-abc123"),
+			cancelErr: errors.New("access denied"),
+			wantCalls: []string{"DescribeInstances", "CancelSpotInstanceRequests:sir-abc123"},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &mockEC2SpotTerminateClient{
+				instance: types.Instance{
+					InstanceId:            stringPtr(instanceID),
+					State:                 &types.InstanceState{Name: types.InstanceStateNameRunning},
+					SpotInstanceRequestId: tt.requestID,
+				},
+				cancelErr: tt.cancelErr,
+			}
+
+			err := terminateInstance(ctx, client, instanceID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("terminateInstance() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if strings.Join(client.calls, " ") != strings.Join(tt.wantCalls, " ") {
+				t.Errorf("calls = %v, want %v", client.calls, tt.wantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,511 @@
+package aws
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/lipgloss"
+	awsinternal "github.com/clouddley/clouddley/internal/aws"
+	"github.com/clouddley/clouddley/internal/log"
+	"github.com/clouddley/clouddley/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// sharedSecurityGroupName is the security group shared by instances without --dedicated-sg
+const sharedSecurityGroupName = "clouddley-default-sg"
+
+var firewallCmd = &cobra.Command{
+	Use:     "firewall",
+	Aliases: []string{"fw"},
+	Short:   "Manage inbound rules of Clouddley security groups",
+	Long:    `Inspect and change the inbound rules of security groups created by the Clouddley CLI. Changes are previewed before they are applied.`,
+	Example: `  clouddley vm aws firewall list
+  clouddley vm aws firewall allow --port 8080/tcp --cidr 203.0.113.0/24
+  clouddley vm aws firewall deny --port 22 --cidr 0.0.0.0/0
+  clouddley vm aws firewall reset --group sg-0123456789abcdef0`,
+}
+
+var firewallListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List inbound rules of Clouddley security groups",
+	Run:     runFirewallList,
+}
+
+var firewallAllowCmd = &cobra.Command{
+	Use:   "allow --port <port[/protocol]> [--cidr <cidr>]",
+	Short: "Allow inbound traffic on a port",
+	Long:  `Allow inbound traffic on a port from a CIDR. When --cidr is omitted, your detected public IP is used.`,
+	Run:   runFirewallAllow,
+}
+
+var firewallDenyCmd = &cobra.Command{
+	Use:   "deny --port <port[/protocol]> [--cidr <cidr>]",
+	Short: "Remove inbound rules for a port",
+	Long:  `Remove inbound rules for a port. When --cidr is omitted, rules for every source on that port are removed.`,
+	Run:   runFirewallDeny,
+}
+
+var firewallResetCmd = &cobra.Command{
+	Use:   "reset [--ssh-cidr <cidr>]",
+	Short: "Restore the default Clouddley rules",
+	Long:  `Restore the default rules: SSH from your public IP (or --ssh-cidr), and HTTP/HTTPS from anywhere. All other IP rules are removed.`,
+	Run:   runFirewallReset,
+}
+
+func init() {
+	for _, c := range []*cobra.Command{firewallListCmd, firewallAllowCmd, firewallDenyCmd, firewallResetCmd} {
+		c.Flags().StringP("group", "g", "", "Security group ID or name (defaults to the only Clouddley group, or prompts)")
+	}
+	for _, c := range []*cobra.Command{firewallAllowCmd, firewallDenyCmd, firewallResetCmd} {
+		c.Flags().BoolP("yes", "y", false, "Skip confirmation prompt")
+	}
+
+	firewallAllowCmd.Flags().StringP("port", "p", "", "Port or range with optional protocol, e.g. 8080/tcp (required)")
+	firewallAllowCmd.Flags().String("cidr", "", "Source CIDR (defaults to your public IP)")
+	firewallAllowCmd.Flags().String("description", "", "Rule description")
+	firewallAllowCmd.MarkFlagRequired("port")
+
+	firewallDenyCmd.Flags().StringP("port", "p", "", "Port or range with optional protocol, e.g. 8080/tcp (required)")
+	firewallDenyCmd.Flags().String("cidr", "", "Source CIDR to remove (defaults to all sources)")
+	firewallDenyCmd.MarkFlagRequired("port")
+
+	firewallResetCmd.Flags().StringSlice("ssh-cidr", nil, "CIDR allowed to SSH (repeatable, defaults to your public IP)")
+
+	firewallCmd.AddCommand(firewallListCmd)
+	firewallCmd.AddCommand(firewallAllowCmd)
+	firewallCmd.AddCommand(firewallDenyCmd)
+	firewallCmd.AddCommand(firewallResetCmd)
+}
+
+// listClouddleySecurityGroups returns the security groups tagged as created by
+// Clouddley, plus the shared group from releases that did not tag it
+func listClouddleySecurityGroups(ctx context.Context, client *ec2.Client) ([]types.SecurityGroup, error) {
+	tagged, err := client.DescribeSecurityGroups(ctx, &ec2.DescribeSecurityGroupsInput{
+		Filters: []types.Filter{
+			{
+				Name:   aws.String("tag:CreatedBy"),
+				Values: []string{"Clouddley"},
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe security groups: %w", err)
+	}
+
+	legacy, err := client.DescribeSecurityGroups(ctx, &ec2.DescribeSecurityGroupsInput{
+		Filters: []types.Filter{
+			{
+				Name:   aws.String("group-name"),
+				Values: []string{sharedSecurityGroupName},
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe security groups: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	var groups []types.SecurityGroup
+	for _, sg := range append(tagged.SecurityGroups, legacy.SecurityGroups...) {
+		id := aws.ToString(sg.GroupId)
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		groups = append(groups, sg)
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		return aws.ToString(groups[i].GroupName) < aws.ToString(groups[j].GroupName)
+	})
+	return groups, nil
+}
+
+// selectSecurityGroup returns the group matching --group, the only Clouddley
+// group, or one picked interactively
+func selectSecurityGroup(ctx context.Context, client *ec2.Client, group string) (*types.SecurityGroup, error) {
+	groups, err := listClouddleySecurityGroups(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	if group != "" {
+		for i, sg := range groups {
+			if aws.ToString(sg.GroupId) == group || aws.ToString(sg.GroupName) == group {
+				return &groups[i], nil
+			}
+		}
+		return nil, fmt.Errorf("security group %s not found or not managed by Clouddley", group)
+	}
+
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("no Clouddley security groups found")
+	}
+	if len(groups) == 1 {
+		return &groups[0], nil
+	}
+
+	choices := make([]string, len(groups))
+	for i, sg := range groups {
+		choices[i] = fmt.Sprintf("%s  %s  %s", aws.ToString(sg.GroupId), aws.ToString(sg.GroupName), aws.ToString(sg.VpcId))
+	}
+
+	choice, err := runListSelection("Select Security Group", choices)
+	if err != nil {
+		return nil, err
+	}
+	return &groups[choice], nil
+}
+
+func runFirewallList(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+
+	group, _ := cmd.Flags().GetString("group")
+
+	// Validate AWS credentials
+	if err := awsinternal.ValidateAWSCredentials(ctx); err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+
+	client, err := awsinternal.GetEC2Client(ctx)
+	if err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Failed to create EC2 client: %v", err)))
+		return
+	}
+
+	groups, err := listClouddleySecurityGroups(ctx, client)
+	if err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+
+	if group != "" {
+		var filtered []types.SecurityGroup
+		for _, sg := range groups {
+			if aws.ToString(sg.GroupId) == group || aws.ToString(sg.GroupName) == group {
+				filtered = append(filtered, sg)
+			}
+		}
+		groups = filtered
+	}
+
+	if len(groups) == 0 {
+		fmt.Println(ui.FormatOutput("✓ Clouddley Security Groups", "No security groups found"))
+		return
+	}
+
+	for _, sg := range groups {
+		fmt.Printf("%s (%s) in %s:\n\n", aws.ToString(sg.GroupName), aws.ToString(sg.GroupId), aws.ToString(sg.VpcId))
+		displayRulesTable(flattenPermissions(sg.IpPermissions))
+		fmt.Println()
+	}
+}
+
+func displayRulesTable(rules []ingressRule) {
+	if len(rules) == 0 {
+		fmt.Println("No inbound rules")
+		return
+	}
+
+	columns := []table.Column{
+		{Title: "Protocol", Width: 10},
+		{Title: "Ports", Width: 13},
+		{Title: "Source", Width: 45},
+		{Title: "Description", Width: 25},
+	}
+
+	rows := make([]table.Row, len(rules))
+	for i, rule := range rules {
+		rows[i] = table.Row{
+			formatProtocol(rule.Protocol),
+			formatPortRange(rule),
+			rule.CIDR,
+			rule.Description,
+		}
+	}
+
+	t := table.New(
+		table.WithColumns(columns),
+		table.WithRows(rows),
+		table.WithFocused(false),
+		table.WithHeight(len(rules)+2),
+	)
+
+	s := table.DefaultStyles()
+	s.Header = s.Header.
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderForeground(lipgloss.Color("240")).
+		BorderBottom(true).
+		Bold(true).
+		Foreground(lipgloss.Color("#7D56F4"))
+
+	s.Cell = s.Cell.
+		Foreground(lipgloss.Color("#FAFAFA"))
+
+	t.SetStyles(s)
+
+	fmt.Println(t.View())
+}
+
+func formatProtocol(protocol string) string {
+	if protocol == "-1" {
+		return "all"
+	}
+	return protocol
+}
+
+func formatPortRange(rule ingressRule) string {
+	if rule.Protocol == "-1" {
+		return "all"
+	}
+	if rule.FromPort == rule.ToPort {
+		return fmt.Sprintf("%d", rule.FromPort)
+	}
+	return fmt.Sprintf("%d-%d", rule.FromPort, rule.ToPort)
+}
+
+func runFirewallAllow(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+
+	portFlag, _ := cmd.Flags().GetString("port")
+	cidrFlag, _ := cmd.Flags().GetString("cidr")
+	description, _ := cmd.Flags().GetString("description")
+
+	port, err := parsePortSpec(portFlag)
+	if err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+
+	var cidr string
+	if cidrFlag != "" {
+		cidr, err = normalizeCIDR(cidrFlag)
+	} else {
+		var ip net.IP
+		ip, err = awsinternal.DetectPublicIP(ctx)
+		if err == nil {
+			cidr = awsinternal.HostCIDR(ip)
+		}
+	}
+	if err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+
+	if description == "" {
+		description = fmt.Sprintf("Port %s access", port)
+	}
+	rule := ingressRule{Protocol: port.Protocol, FromPort: port.FromPort, ToPort: port.ToPort, CIDR: cidr, Description: description}
+
+	applyFirewallChange(cmd, func(sg *types.SecurityGroup) ([]ingressRule, []ingressRule) {
+		return missingRules(sg.IpPermissions, []ingressRule{rule}), nil
+	})
+}
+
+func runFirewallDeny(cmd *cobra.Command, args []string) {
+	portFlag, _ := cmd.Flags().GetString("port")
+	cidrFlag, _ := cmd.Flags().GetString("cidr")
+
+	port, err := parsePortSpec(portFlag)
+	if err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+
+	cidr := ""
+	if cidrFlag != "" {
+		cidr, err = normalizeCIDR(cidrFlag)
+		if err != nil {
+			fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+			return
+		}
+	}
+
+	applyFirewallChange(cmd, func(sg *types.SecurityGroup) ([]ingressRule, []ingressRule) {
+		return nil, rulesForPort(sg.IpPermissions, port, cidr)
+	})
+}
+
+func runFirewallReset(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+
+	sshCIDRFlags, _ := cmd.Flags().GetStringSlice("ssh-cidr")
+
+	sshCIDRs, err := resolveSSHCIDRs(ctx, sshCIDRFlags)
+	if err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+
+	applyFirewallChange(cmd, func(sg *types.SecurityGroup) ([]ingressRule, []ingressRule) {
+		// Keep IPv6 defaults only if the group already uses IPv6 sources
+		ipv6 := false
+		for _, rule := range flattenPermissions(sg.IpPermissions) {
+			if isIPv6CIDR(rule.CIDR) {
+				ipv6 = true
+			}
+		}
+
+		desired := defaultIngressRules(sshCIDRs, nil, ipv6)
+		return missingRules(sg.IpPermissions, desired), extraRules(sg.IpPermissions, desired)
+	})
+}
+
+// applyFirewallChange selects the security group, computes the rules to add and
+// remove, previews the diff and applies it after confirmation
+func applyFirewallChange(cmd *cobra.Command, plan func(sg *types.SecurityGroup) (add []ingressRule, remove []ingressRule)) {
+	ctx := context.Background()
+
+	group, _ := cmd.Flags().GetString("group")
+	skipConfirmation, _ := cmd.Flags().GetBool("yes")
+
+	// Validate AWS credentials
+	if err := awsinternal.ValidateAWSCredentials(ctx); err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+
+	client, err := awsinternal.GetEC2Client(ctx)
+	if err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Failed to create EC2 client: %v", err)))
+		return
+	}
+
+	sg, err := selectSecurityGroup(ctx, client, group)
+	if err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+	sgID := aws.ToString(sg.GroupId)
+
+	add, remove := plan(sg)
+	if len(add) == 0 && len(remove) == 0 {
+		fmt.Println(ui.FormatOutput("✓ Firewall", fmt.Sprintf("No changes needed for %s (%s)", aws.ToString(sg.GroupName), sgID)))
+		return
+	}
+
+	fmt.Printf("Changes to %s (%s):\n\n", aws.ToString(sg.GroupName), sgID)
+	fmt.Println(formatRuleDiff(add, remove))
+
+	// Confirmation prompt (unless --yes flag is used)
+	if !skipConfirmation {
+		fmt.Print("Apply these changes? (y/n): ")
+
+		reader := bufio.NewReader(os.Stdin)
+		response, err := reader.ReadString('\n')
+		if err != nil {
+			fmt.Println(ui.FormatError(fmt.Sprintf("Error reading input: %v", err)))
+			return
+		}
+
+		response = strings.TrimSpace(strings.ToLower(response))
+		if response != "y" && response != "yes" {
+			fmt.Println("Operation cancelled")
+			return
+		}
+	}
+
+	if len(remove) > 0 {
+		_, err := client.RevokeSecurityGroupIngress(ctx, &ec2.RevokeSecurityGroupIngressInput{
+			GroupId:       aws.String(sgID),
+			IpPermissions: toPermissions(withoutDescriptions(remove)),
+		})
+		if err != nil {
+			log.Error("Failed to revoke security group rules", "group", sgID, "error", err)
+			fmt.Println(ui.FormatError(fmt.Sprintf("Failed to remove rules: %v", err)))
+			return
+		}
+	}
+
+	if len(add) > 0 {
+		_, err := client.AuthorizeSecurityGroupIngress(ctx, &ec2.AuthorizeSecurityGroupIngressInput{
+			GroupId:       aws.String(sgID),
+			IpPermissions: toPermissions(add),
+		})
+		if err != nil {
+			log.Error("Failed to authorize security group rules", "group", sgID, "error", err)
+			fmt.Println(ui.FormatError(fmt.Sprintf("Failed to add rules: %v", err)))
+			return
+		}
+	}
+
+	log.Info("Security group updated", "group", sgID, "added", len(add), "removed", len(remove))
+	fmt.Println(ui.FormatOutput("✓ Success", fmt.Sprintf("Security group %s updated", sgID)))
+}
+
+// extraRules returns the existing rules that are not in the desired set
+func extraRules(existing []types.IpPermission, desired []ingressRule) []ingressRule {
+	var extra []ingressRule
+	for _, have := range flattenPermissions(existing) {
+		found := false
+		for _, want := range desired {
+			if have.matches(want) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			extra = append(extra, have)
+		}
+	}
+	return extra
+}
+
+// rulesForPort returns the existing rules for exactly this port range and protocol,
+// limited to one source when cidr is set
+func rulesForPort(existing []types.IpPermission, port portSpec, cidr string) []ingressRule {
+	var matched []ingressRule
+	for _, rule := range flattenPermissions(existing) {
+		if rule.Protocol != port.Protocol || rule.FromPort != port.FromPort || rule.ToPort != port.ToPort {
+			continue
+		}
+		if cidr != "" && rule.CIDR != cidr {
+			continue
+		}
+		matched = append(matched, rule)
+	}
+	return matched
+}
+
+func withoutDescriptions(rules []ingressRule) []ingressRule {
+	stripped := make([]ingressRule, len(rules))
+	for i, rule := range rules {
+		rule.Description = ""
+		stripped[i] = rule
+	}
+	return stripped
+}
+
+// formatRuleDiff renders rules to add and remove as a +/- preview
+func formatRuleDiff(add, remove []ingressRule) string {
+	addStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#04B575"))
+	removeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5F87"))
+
+	var b strings.Builder
+	for _, rule := range remove {
+		b.WriteString(removeStyle.Render("- "+formatRule(rule)) + "\n")
+	}
+	for _, rule := range add {
+		b.WriteString(addStyle.Render("+ "+formatRule(rule)) + "\n")
+	}
+	return b.String()
+}
+
+func formatRule(rule ingressRule) string {
+	line := fmt.Sprintf("%-4s %-11s from %s", formatProtocol(rule.Protocol), formatPortRange(rule), rule.CIDR)
+	if rule.Description != "" {
+		line += fmt.Sprintf("  (%s)", rule.Description)
+	}
+	return line
+}
@@ -0,0 +1,113 @@
+package aws
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+func firewallTestPermissions() []types.IpPermission {
+	return []types.IpPermission{
+		{
+			IpProtocol: stringPtr("tcp"),
+			FromPort:   int32Ptr(22),
+			ToPort:     int32Ptr(22),
+			IpRanges: []types.IpRange{
+				{CidrIp: stringPtr("0.0.0.0/0"), Description: stringPtr("SSH access")},
+				{CidrIp: stringPtr("203.0.113.10/32")},
+			},
+		},
+		{
+			IpProtocol: stringPtr("tcp"),
+			FromPort:   int32Ptr(80),
+			ToPort:     int32Ptr(80),
+			IpRanges:   []types.IpRange{{CidrIp: stringPtr("0.0.0.0/0")}},
+		},
+		{
+			IpProtocol: stringPtr("tcp"),
+			FromPort:   int32Ptr(8080),
+			ToPort:     int32Ptr(8080),
+			IpRanges:   []types.IpRange{{CidrIp: stringPtr("0.0.0.0/0")}},
+			Ipv6Ranges: []types.Ipv6Range{{CidrIpv6: stringPtr("::/0")}},
+		},
+	}
+}
+
+func TestExtraRules(t *testing.T) {
+	desired := defaultIngressRules([]string{"203.0.113.10/32"}, nil, false)
+
+	extra := extraRules(firewallTestPermissions(), desired)
+
+	// SSH from anywhere and both 8080 rules are not part of the defaults
+	if len(extra) != 3 {
+		t.Fatalf("Expected 3 extra rules, got %d: %+v", len(extra), extra)
+	}
+
+	if extra[0].FromPort != 22 || extra[0].CIDR != "0.0.0.0/0" {
+		t.Errorf("Expected world SSH rule to be extra, got %+v", extra[0])
+	}
+
+	for _, rule := range extra[1:] {
+		if rule.FromPort != 8080 {
+			t.Errorf("Expected port 8080 rule to be extra, got %+v", rule)
+		}
+	}
+}
+
+func TestRulesForPort(t *testing.T) {
+	tests := []struct {
+		name     string
+		port     portSpec
+		cidr     string
+		expected int
+	}{
+		{"All sources for SSH", portSpec{Protocol: "tcp", FromPort: 22, ToPort: 22}, "", 2},
+		{"Single source for SSH", portSpec{Protocol: "tcp", FromPort: 22, ToPort: 22}, "0.0.0.0/0", 1},
+		{"IPv4 and IPv6 for 8080", portSpec{Protocol: "tcp", FromPort: 8080, ToPort: 8080}, "", 2},
+		{"Protocol must match", portSpec{Protocol: "udp", FromPort: 80, ToPort: 80}, "", 0},
+		{"Unknown port", portSpec{Protocol: "tcp", FromPort: 443, ToPort: 443}, "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := rulesForPort(firewallTestPermissions(), tt.port, tt.cidr)
+			if len(rules) != tt.expected {
+				t.Errorf("Expected %d rules, got %d: %+v", tt.expected, len(rules), rules)
+			}
+		})
+	}
+}
+
+func TestFormatRuleDiff(t *testing.T) {
+	add := []ingressRule{{Protocol: "tcp", FromPort: 8000, ToPort: 8100, CIDR: "203.0.113.0/24", Description: "App"}}
+	remove := []ingressRule{{Protocol: "tcp", FromPort: 22, ToPort: 22, CIDR: "0.0.0.0/0"}}
+
+	diff := formatRuleDiff(add, remove)
+
+	if !strings.Contains(diff, "- tcp  22          from 0.0.0.0/0") {
+		t.Errorf("Expected removal line in diff, got:\n%s", diff)
+	}
+
+	if !strings.Contains(diff, "+ tcp  8000-8100   from 203.0.113.0/24  (App)") {
+		t.Errorf("Expected addition line in diff, got:\n%s", diff)
+	}
+
+	if strings.Index(diff, "- tcp") > strings.Index(diff, "+ tcp") {
+		t.Error("Expected removals to be listed before additions")
+	}
+}
+
+func TestWithoutDescriptions(t *testing.T) {
+	rules := []ingressRule{{Protocol: "tcp", FromPort: 22, ToPort: 22, CIDR: "0.0.0.0/0", Description: "SSH access"}}
+
+	stripped := withoutDescriptions(rules)
+
+	if stripped[0].Description != "" {
+		t.Errorf("Expected description to be removed, got %q", stripped[0].Description)
+	}
+
+	if rules[0].Description != "SSH access" {
+		t.Error("Expected original rules to be unchanged")
+	}
+}
@@ -0,0 +1,233 @@
+package aws
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+const (
+	anyIPv4 = "0.0.0.0/0"
+	anyIPv6 = "::/0"
+)
+
+// ingressRule is a single inbound rule: one protocol, port range and source CIDR
+type ingressRule struct {
+	Protocol    string
+	FromPort    int32
+	ToPort      int32
+	CIDR        string
+	Description string
+}
+
+// portSpec is a port or port range with a protocol, parsed from "8080/tcp" or "8000-8100/udp"
+type portSpec struct {
+	Protocol string
+	FromPort int32
+	ToPort   int32
+}
+
+func (p portSpec) String() string {
+	if p.FromPort == p.ToPort {
+		return fmt.Sprintf("%d/%s", p.FromPort, p.Protocol)
+	}
+	return fmt.Sprintf("%d-%d/%s", p.FromPort, p.ToPort, p.Protocol)
+}
+
+// parsePortSpec parses "PORT[-PORT][/PROTOCOL]", defaulting to tcp
+func parsePortSpec(spec string) (portSpec, error) {
+	spec = strings.TrimSpace(spec)
+	protocol := "tcp"
+
+	if i := strings.Index(spec, "/"); i >= 0 {
+		protocol = strings.ToLower(spec[i+1:])
+		spec = spec[:i]
+	}
+
+	if protocol != "tcp" && protocol != "udp" {
+		return portSpec{}, fmt.Errorf("invalid protocol %q: must be tcp or udp", protocol)
+	}
+
+	fromStr, toStr := spec, spec
+	if i := strings.Index(spec, "-"); i >= 0 {
+		fromStr, toStr = spec[:i], spec[i+1:]
+	}
+
+	from, err := parsePort(fromStr)
+	if err != nil {
+		return portSpec{}, err
+	}
+	to, err := parsePort(toStr)
+	if err != nil {
+		return portSpec{}, err
+	}
+	if from > to {
+		return portSpec{}, fmt.Errorf("invalid port range %d-%d", from, to)
+	}
+
+	return portSpec{Protocol: protocol, FromPort: from, ToPort: to}, nil
+}
+
+func parsePort(s string) (int32, error) {
+	port, err := strconv.Atoi(s)
+	if err != nil || port < 1 || port > 65535 {
+		return 0, fmt.Errorf("invalid port %q: must be between 1 and 65535", s)
+	}
+	return int32(port), nil
+}
+
+// normalizeCIDR validates a CIDR or bare IP and returns it in canonical CIDR form
+func normalizeCIDR(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if !strings.Contains(s, "/") {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return "", fmt.Errorf("invalid CIDR %q", s)
+		}
+		if ip.To4() != nil {
+			return ip.String() + "/32", nil
+		}
+		return ip.String() + "/128", nil
+	}
+
+	_, network, err := net.ParseCIDR(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid CIDR %q", s)
+	}
+	return network.String(), nil
+}
+
+func isIPv6CIDR(cidr string) bool {
+	return strings.Contains(cidr, ":")
+}
+
+// defaultIngressRules returns SSH from the given sources plus HTTP, HTTPS and any
+// extra ports open to the world, including IPv6 ranges when the subnet supports them
+func defaultIngressRules(sshCIDRs []string, openPorts []portSpec, ipv6 bool) []ingressRule {
+	var rules []ingressRule
+
+	for _, cidr := range sshCIDRs {
+		if isIPv6CIDR(cidr) && !ipv6 {
+			continue
+		}
+		rules = append(rules, ingressRule{Protocol: "tcp", FromPort: 22, ToPort: 22, CIDR: cidr, Description: "SSH access"})
+	}
+
+	public := []portSpec{
+		{Protocol: "tcp", FromPort: 80, ToPort: 80},
+		{Protocol: "tcp", FromPort: 443, ToPort: 443},
+	}
+	descriptions := map[string]string{"80/tcp": "HTTP access", "443/tcp": "HTTPS access"}
+
+	sources := []string{anyIPv4}
+	if ipv6 {
+		sources = append(sources, anyIPv6)
+	}
+
+	for _, port := range append(public, openPorts...) {
+		description, ok := descriptions[port.String()]
+		if !ok {
+			description = fmt.Sprintf("Port %s access", port)
+		}
+		for _, cidr := range sources {
+			rules = append(rules, ingressRule{
+				Protocol:    port.Protocol,
+				FromPort:    port.FromPort,
+				ToPort:      port.ToPort,
+				CIDR:        cidr,
+				Description: description,
+			})
+		}
+	}
+
+	return rules
+}
+
+// flattenPermissions expands security group permissions into one rule per source CIDR
+func flattenPermissions(permissions []types.IpPermission) []ingressRule {
+	var rules []ingressRule
+	for _, perm := range permissions {
+		base := ingressRule{
+			Protocol: aws.ToString(perm.IpProtocol),
+			FromPort: aws.ToInt32(perm.FromPort),
+			ToPort:   aws.ToInt32(perm.ToPort),
+		}
+		for _, r := range perm.IpRanges {
+			rule := base
+			rule.CIDR = aws.ToString(r.CidrIp)
+			rule.Description = aws.ToString(r.Description)
+			rules = append(rules, rule)
+		}
+		for _, r := range perm.Ipv6Ranges {
+			rule := base
+			rule.CIDR = aws.ToString(r.CidrIpv6)
+			rule.Description = aws.ToString(r.Description)
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+func (r ingressRule) matches(other ingressRule) bool {
+	return r.Protocol == other.Protocol && r.FromPort == other.FromPort && r.ToPort == other.ToPort && r.CIDR == other.CIDR
+}
+
+// missingRules returns the desired rules that are not already present
+func missingRules(existing []types.IpPermission, desired []ingressRule) []ingressRule {
+	current := flattenPermissions(existing)
+
+	var missing []ingressRule
+	for _, want := range desired {
+		found := false
+		for _, have := range current {
+			if want.matches(have) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, want)
+		}
+	}
+	return missing
+}
+
+// toPermissions converts rules into IpPermissions for Authorize/RevokeSecurityGroupIngress
+func toPermissions(rules []ingressRule) []types.IpPermission {
+	permissions := make([]types.IpPermission, 0, len(rules))
+	for _, rule := range rules {
+		perm := types.IpPermission{
+			IpProtocol: aws.String(rule.Protocol),
+			FromPort:   aws.Int32(rule.FromPort),
+			ToPort:     aws.Int32(rule.ToPort),
+		}
+
+		var description *string
+		if rule.Description != "" {
+			description = aws.String(rule.Description)
+		}
+
+		if isIPv6CIDR(rule.CIDR) {
+			perm.Ipv6Ranges = []types.Ipv6Range{{CidrIpv6: aws.String(rule.CIDR), Description: description}}
+		} else {
+			perm.IpRanges = []types.IpRange{{CidrIp: aws.String(rule.CIDR), Description: description}}
+		}
+		permissions = append(permissions, perm)
+	}
+	return permissions
+}
+
+// hasWorldSSH reports whether the permissions allow SSH from anywhere
+func hasWorldSSH(permissions []types.IpPermission) bool {
+	for _, rule := range flattenPermissions(permissions) {
+		coversSSH := rule.Protocol == "-1" || (rule.Protocol == "tcp" && rule.FromPort <= 22 && rule.ToPort >= 22)
+		if coversSSH && (rule.CIDR == anyIPv4 || rule.CIDR == anyIPv6) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,226 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+func TestParsePortSpec(t *testing.T) {
+	tests := []struct {
+		spec        string
+		expected    portSpec
+		expectError bool
+	}{
+		{"8080/tcp", portSpec{Protocol: "tcp", FromPort: 8080, ToPort: 8080}, false},
+		{"8080", portSpec{Protocol: "tcp", FromPort: 8080, ToPort: 8080}, false},
+		{"53/UDP", portSpec{Protocol: "udp", FromPort: 53, ToPort: 53}, false},
+		{"8000-8100/tcp", portSpec{Protocol: "tcp", FromPort: 8000, ToPort: 8100}, false},
+		{"8100-8000/tcp", portSpec{}, true},
+		{"0/tcp", portSpec{}, true},
+		{"70000/tcp", portSpec{}, true},
+		{"80/icmp", portSpec{}, true},
+		{"http", portSpec{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			result, err := parsePortSpec(tt.spec)
+
+			if tt.expectError {
+				if err == nil {
+					t.Fatalf("Expected error, got %+v", result)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestNormalizeCIDR(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    string
+		expectError bool
+	}{
+		{"203.0.113.10", "203.0.113.10/32", false},
+		{"203.0.113.10/24", "203.0.113.0/24", false},
+		{"2001:db8::1", "2001:db8::1/128", false},
+		{"0.0.0.0/0", "0.0.0.0/0", false},
+		{"not-an-ip", "", true},
+		{"10.0.0.0/33", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := normalizeCIDR(tt.input)
+
+			if tt.expectError {
+				if err == nil {
+					t.Fatalf("Expected error, got %s", result)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestDefaultIngressRules(t *testing.T) {
+	openPorts := []portSpec{{Protocol: "tcp", FromPort: 8080, ToPort: 8080}}
+
+	t.Run("IPv4 only", func(t *testing.T) {
+		rules := defaultIngressRules([]string{"203.0.113.10/32", "2001:db8::1/128"}, openPorts, false)
+
+		// SSH from the IPv4 source only, plus 80, 443 and 8080 from 0.0.0.0/0
+		if len(rules) != 4 {
+			t.Fatalf("Expected 4 rules, got %d: %+v", len(rules), rules)
+		}
+
+		ssh := rules[0]
+		if ssh.FromPort != 22 || ssh.CIDR != "203.0.113.10/32" {
+			t.Errorf("Expected SSH rule from 203.0.113.10/32, got %+v", ssh)
+		}
+
+		for _, rule := range rules[1:] {
+			if rule.CIDR != anyIPv4 {
+				t.Errorf("Expected public rule from %s, got %+v", anyIPv4, rule)
+			}
+		}
+
+		if rules[3].FromPort != 8080 || rules[3].Description != "Port 8080/tcp access" {
+			t.Errorf("Expected open port 8080 rule, got %+v", rules[3])
+		}
+	})
+
+	t.Run("IPv6 subnet", func(t *testing.T) {
+		rules := defaultIngressRules([]string{"203.0.113.10/32", "2001:db8::1/128"}, nil, true)
+
+		// Two SSH sources plus 80 and 443 from both 0.0.0.0/0 and ::/0
+		if len(rules) != 6 {
+			t.Fatalf("Expected 6 rules, got %d: %+v", len(rules), rules)
+		}
+
+		ipv6Count := 0
+		for _, rule := range rules {
+			if isIPv6CIDR(rule.CIDR) {
+				ipv6Count++
+			}
+		}
+		if ipv6Count != 3 {
+			t.Errorf("Expected 3 IPv6 rules, got %d", ipv6Count)
+		}
+	})
+}
+
+func TestMissingRules(t *testing.T) {
+	existing := []types.IpPermission{
+		{
+			IpProtocol: stringPtr("tcp"),
+			FromPort:   int32Ptr(80),
+			ToPort:     int32Ptr(80),
+			IpRanges:   []types.IpRange{{CidrIp: stringPtr("0.0.0.0/0")}},
+		},
+		{
+			IpProtocol: stringPtr("tcp"),
+			FromPort:   int32Ptr(22),
+			ToPort:     int32Ptr(22),
+			IpRanges:   []types.IpRange{{CidrIp: stringPtr("198.51.100.7/32")}},
+		},
+	}
+
+	desired := defaultIngressRules([]string{"203.0.113.10/32"}, nil, false)
+	missing := missingRules(existing, desired)
+
+	// 80 exists; SSH from a different IP and 443 are missing
+	if len(missing) != 2 {
+		t.Fatalf("Expected 2 missing rules, got %d: %+v", len(missing), missing)
+	}
+
+	if missing[0].FromPort != 22 || missing[0].CIDR != "203.0.113.10/32" {
+		t.Errorf("Expected missing SSH rule, got %+v", missing[0])
+	}
+
+	if missing[1].FromPort != 443 {
+		t.Errorf("Expected missing HTTPS rule, got %+v", missing[1])
+	}
+}
+
+func TestToPermissions(t *testing.T) {
+	rules := []ingressRule{
+		{Protocol: "tcp", FromPort: 22, ToPort: 22, CIDR: "203.0.113.10/32", Description: "SSH access"},
+		{Protocol: "tcp", FromPort: 443, ToPort: 443, CIDR: anyIPv6},
+	}
+
+	permissions := toPermissions(rules)
+
+	if len(permissions) != 2 {
+		t.Fatalf("Expected 2 permissions, got %d", len(permissions))
+	}
+
+	if len(permissions[0].IpRanges) != 1 || *permissions[0].IpRanges[0].CidrIp != "203.0.113.10/32" {
+		t.Errorf("Expected IPv4 range, got %+v", permissions[0])
+	}
+
+	if len(permissions[1].Ipv6Ranges) != 1 || *permissions[1].Ipv6Ranges[0].CidrIpv6 != anyIPv6 {
+		t.Errorf("Expected IPv6 range, got %+v", permissions[1])
+	}
+
+	if permissions[1].Ipv6Ranges[0].Description != nil {
+		t.Error("Expected no description for rule without one")
+	}
+}
+
+func TestHasWorldSSH(t *testing.T) {
+	tests := []struct {
+		name        string
+		permissions []types.IpPermission
+		expected    bool
+	}{
+		{
+			name: "SSH open to the world",
+			permissions: []types.IpPermission{
+				{IpProtocol: stringPtr("tcp"), FromPort: int32Ptr(22), ToPort: int32Ptr(22), IpRanges: []types.IpRange{{CidrIp: stringPtr("0.0.0.0/0")}}},
+			},
+			expected: true,
+		},
+		{
+			name: "All traffic open over IPv6",
+			permissions: []types.IpPermission{
+				{IpProtocol: stringPtr("-1"), Ipv6Ranges: []types.Ipv6Range{{CidrIpv6: stringPtr("::/0")}}},
+			},
+			expected: true,
+		},
+		{
+			name: "SSH restricted",
+			permissions: []types.IpPermission{
+				{IpProtocol: stringPtr("tcp"), FromPort: int32Ptr(22), ToPort: int32Ptr(22), IpRanges: []types.IpRange{{CidrIp: stringPtr("203.0.113.10/32")}}},
+				{IpProtocol: stringPtr("tcp"), FromPort: int32Ptr(443), ToPort: int32Ptr(443), IpRanges: []types.IpRange{{CidrIp: stringPtr("0.0.0.0/0")}}},
+			},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := hasWorldSSH(tt.permissions); result != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,336 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	awsinternal "github.com/clouddley/clouddley/internal/aws"
+	"github.com/clouddley/clouddley/internal/log"
+	"github.com/clouddley/clouddley/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var ipCmd = &cobra.Command{
+	Use:   "ip",
+	Short: "Manage Elastic IPs for Clouddley instances",
+	Long:  `Attach, detach and release Elastic IPs so instances keep their public address across stop and start.`,
+	Example: `  clouddley vm aws ip attach --id i-1234567890abcdef0
+  clouddley vm aws ip detach --id i-1234567890abcdef0
+  clouddley vm aws ip release --allocation eipalloc-0123456789abcdef0`,
+}
+
+var ipAttachCmd = &cobra.Command{
+	Use:   "attach --id <instance-id> [--allocation <allocation-id>]",
+	Short: "Attach an Elastic IP to an instance",
+	Long:  `Attach an existing Clouddley Elastic IP to an instance, or allocate a new one when --allocation is omitted.`,
+	Run:   runIPAttach,
+}
+
+var ipDetachCmd = &cobra.Command{
+	Use:   "detach --id <instance-id>",
+	Short: "Detach the Elastic IP from an instance",
+	Long:  `Detach the Clouddley Elastic IP from an instance. The address stays allocated and can be attached again.`,
+	Run:   runIPDetach,
+}
+
+var ipReleaseCmd = &cobra.Command{
+	Use:   "release (--allocation <allocation-id> | --id <instance-id>)",
+	Short: "Release a Clouddley Elastic IP",
+	Long:  `Detach if needed and release a Clouddley Elastic IP back to AWS. Released addresses cannot be recovered.`,
+	Run:   runIPRelease,
+}
+
+func init() {
+	ipAttachCmd.Flags().StringP("id", "i", "", "Instance ID (required)")
+	ipAttachCmd.Flags().String("allocation", "", "Allocation ID of an existing Clouddley Elastic IP")
+	ipAttachCmd.MarkFlagRequired("id")
+
+	ipDetachCmd.Flags().StringP("id", "i", "", "Instance ID (required)")
+	ipDetachCmd.MarkFlagRequired("id")
+
+	ipReleaseCmd.Flags().String("allocation", "", "Allocation ID of the Elastic IP")
+	ipReleaseCmd.Flags().StringP("id", "i", "", "Instance ID whose Elastic IP should be released")
+
+	ipCmd.AddCommand(ipAttachCmd)
+	ipCmd.AddCommand(ipDetachCmd)
+	ipCmd.AddCommand(ipReleaseCmd)
+}
+
+// allocateElasticIP allocates a new Elastic IP tagged as created by Clouddley
+func allocateElasticIP(ctx context.Context, client *ec2.Client, name string) (*types.Address, error) {
+	result, err := client.AllocateAddress(ctx, &ec2.AllocateAddressInput{
+		Domain: types.DomainTypeVpc,
+		TagSpecifications: []types.TagSpecification{
+			{
+				ResourceType: types.ResourceTypeElasticIp,
+				Tags: []types.Tag{
+					{
+						Key:   aws.String("Name"),
+						Value: aws.String(name),
+					},
+					{
+						Key:   aws.String("CreatedBy"),
+						Value: aws.String("Clouddley"),
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to allocate Elastic IP: %w", err)
+	}
+
+	return &types.Address{
+		AllocationId: result.AllocationId,
+		PublicIp:     result.PublicIp,
+	}, nil
+}
+
+// associateElasticIP attaches an Elastic IP to an instance
+func associateElasticIP(ctx context.Context, client *ec2.Client, allocationID, instanceID string) error {
+	_, err := client.AssociateAddress(ctx, &ec2.AssociateAddressInput{
+		AllocationId: aws.String(allocationID),
+		InstanceId:   aws.String(instanceID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to associate Elastic IP %s with %s: %w", allocationID, instanceID, err)
+	}
+	return nil
+}
+
+// attachNewElasticIP allocates an Elastic IP and attaches it to the instance, releasing
+// the allocation again if it cannot be attached so no unused address is left billing
+func attachNewElasticIP(ctx context.Context, client *ec2.Client, name, instanceID string) (*types.Address, error) {
+	address, err := allocateElasticIP(ctx, client, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := associateElasticIP(ctx, client, aws.ToString(address.AllocationId), instanceID); err != nil {
+		if releaseErr := releaseElasticIP(ctx, client, *address); releaseErr != nil {
+			log.Warn("Failed to release unattached Elastic IP", "allocation", aws.ToString(address.AllocationId), "error", releaseErr)
+		}
+		return nil, err
+	}
+
+	return address, nil
+}
+
+// describeClouddleyAddresses returns Clouddley Elastic IPs matching the extra filters
+func describeClouddleyAddresses(ctx context.Context, client *ec2.Client, filters ...types.Filter) ([]types.Address, error) {
+	filters = append(filters, types.Filter{
+		Name:   aws.String("tag:CreatedBy"),
+		Values: []string{"Clouddley"},
+	})
+
+	result, err := client.DescribeAddresses(ctx, &ec2.DescribeAddressesInput{
+		Filters: filters,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe Elastic IPs: %w", err)
+	}
+
+	return result.Addresses, nil
+}
+
+// getInstanceElasticIPs returns the Clouddley Elastic IPs attached to the instances
+func getInstanceElasticIPs(ctx context.Context, client *ec2.Client, instanceIDs ...string) ([]types.Address, error) {
+	if len(instanceIDs) == 0 {
+		return nil, nil
+	}
+
+	return describeClouddleyAddresses(ctx, client, types.Filter{
+		Name:   aws.String("instance-id"),
+		Values: instanceIDs,
+	})
+}
+
+// releaseElasticIP detaches the address if it is attached and releases it
+func releaseElasticIP(ctx context.Context, client *ec2.Client, address types.Address) error {
+	if address.AssociationId != nil {
+		_, err := client.DisassociateAddress(ctx, &ec2.DisassociateAddressInput{
+			AssociationId: address.AssociationId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to disassociate Elastic IP %s: %w", aws.ToString(address.PublicIp), err)
+		}
+	}
+
+	_, err := client.ReleaseAddress(ctx, &ec2.ReleaseAddressInput{
+		AllocationId: address.AllocationId,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to release Elastic IP %s: %w", aws.ToString(address.PublicIp), err)
+	}
+
+	return nil
+}
+
+func runIPAttach(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+
+	instanceID, _ := cmd.Flags().GetString("id")
+	allocationID, _ := cmd.Flags().GetString("allocation")
+
+	// Validate AWS credentials
+	if err := awsinternal.ValidateAWSCredentials(ctx); err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+
+	client, err := awsinternal.GetEC2Client(ctx)
+	if err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Failed to create EC2 client: %v", err)))
+		return
+	}
+
+	existing, err := getInstanceElasticIPs(ctx, client, instanceID)
+	if err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+	if len(existing) > 0 {
+		fmt.Println(ui.FormatOutput("✓ Elastic IP", fmt.Sprintf("Instance %s already has Elastic IP %s", instanceID, aws.ToString(existing[0].PublicIp))))
+		return
+	}
+
+	var address *types.Address
+	if allocationID != "" {
+		addresses, err := describeClouddleyAddresses(ctx, client, types.Filter{
+			Name:   aws.String("allocation-id"),
+			Values: []string{allocationID},
+		})
+		if err != nil {
+			fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+			return
+		}
+		if len(addresses) == 0 {
+			fmt.Println(ui.FormatError(fmt.Sprintf("Error: Elastic IP %s not found or not managed by Clouddley", allocationID)))
+			return
+		}
+		if addresses[0].AssociationId != nil {
+			fmt.Println(ui.FormatError(fmt.Sprintf("Error: Elastic IP %s is attached to %s, detach it first", allocationID, aws.ToString(addresses[0].InstanceId))))
+			return
+		}
+		address = &addresses[0]
+
+		err = associateElasticIP(ctx, client, allocationID, instanceID)
+		if err != nil {
+			log.Error("Failed to attach Elastic IP", "instance", instanceID, "error", err)
+			fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+			return
+		}
+	} else {
+		fmt.Println("Allocating Elastic IP...")
+		address, err = attachNewElasticIP(ctx, client, instanceID+"-eip", instanceID)
+		if err != nil {
+			log.Error("Failed to attach Elastic IP", "instance", instanceID, "error", err)
+			fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+			return
+		}
+	}
+
+	log.Info("Elastic IP attached", "instance", instanceID, "ip", aws.ToString(address.PublicIp))
+	fmt.Println(ui.FormatOutput("✓ Success", fmt.Sprintf("Elastic IP %s (%s) attached to %s",
+		aws.ToString(address.PublicIp), aws.ToString(address.AllocationId), instanceID)))
+}
+
+func runIPDetach(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+
+	instanceID, _ := cmd.Flags().GetString("id")
+
+	// Validate AWS credentials
+	if err := awsinternal.ValidateAWSCredentials(ctx); err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+
+	client, err := awsinternal.GetEC2Client(ctx)
+	if err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Failed to create EC2 client: %v", err)))
+		return
+	}
+
+	addresses, err := getInstanceElasticIPs(ctx, client, instanceID)
+	if err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+	if len(addresses) == 0 {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: no Clouddley Elastic IP attached to %s", instanceID)))
+		return
+	}
+
+	for _, address := range addresses {
+		_, err := client.DisassociateAddress(ctx, &ec2.DisassociateAddressInput{
+			AssociationId: address.AssociationId,
+		})
+		if err != nil {
+			log.Error("Failed to detach Elastic IP", "instance", instanceID, "error", err)
+			fmt.Println(ui.FormatError(fmt.Sprintf("Failed to detach %s: %v", aws.ToString(address.PublicIp), err)))
+			continue
+		}
+
+		log.Info("Elastic IP detached", "instance", instanceID, "ip", aws.ToString(address.PublicIp))
+		fmt.Println(ui.FormatOutput("✓ Success", fmt.Sprintf("Elastic IP %s (%s) detached from %s",
+			aws.ToString(address.PublicIp), aws.ToString(address.AllocationId), instanceID)))
+	}
+}
+
+func runIPRelease(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+
+	allocationID, _ := cmd.Flags().GetString("allocation")
+	instanceID, _ := cmd.Flags().GetString("id")
+
+	if (allocationID == "") == (instanceID == "") {
+		fmt.Println(ui.FormatError("Error: exactly one of --allocation or --id is required"))
+		return
+	}
+
+	// Validate AWS credentials
+	if err := awsinternal.ValidateAWSCredentials(ctx); err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+
+	client, err := awsinternal.GetEC2Client(ctx)
+	if err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Failed to create EC2 client: %v", err)))
+		return
+	}
+
+	var addresses []types.Address
+	if allocationID != "" {
+		addresses, err = describeClouddleyAddresses(ctx, client, types.Filter{
+			Name:   aws.String("allocation-id"),
+			Values: []string{allocationID},
+		})
+	} else {
+		addresses, err = getInstanceElasticIPs(ctx, client, instanceID)
+	}
+	if err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+	if len(addresses) == 0 {
+		fmt.Println(ui.FormatError("Error: no matching Clouddley Elastic IP found"))
+		return
+	}
+
+	for _, address := range addresses {
+		if err := releaseElasticIP(ctx, client, address); err != nil {
+			log.Error("Failed to release Elastic IP", "allocation", aws.ToString(address.AllocationId), "error", err)
+			fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+			continue
+		}
+
+		log.Info("Elastic IP released", "ip", aws.ToString(address.PublicIp))
+		fmt.Println(ui.FormatOutput("✓ Success", fmt.Sprintf("Elastic IP %s (%s) released",
+			aws.ToString(address.PublicIp), aws.ToString(address.AllocationId))))
+	}
+}
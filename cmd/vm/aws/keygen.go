@@ -0,0 +1,111 @@
+package aws
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+	awsinternal "github.com/clouddley/clouddley/internal/aws"
+	"github.com/clouddley/clouddley/internal/log"
+	"github.com/clouddley/clouddley/internal/ui"
+	"golang.org/x/term"
+)
+
+// errNoSSHKey is returned when no local key exists and the user declines to generate one
+var errNoSSHKey = fmt.Errorf(`no SSH public key found. Generate one using:
+ssh-keygen -t ed25519 -C "your_email@example.com" (recommended)
+or
+ssh-keygen -t rsa -b 4096 -C "your_email@example.com"
+
+See https://docs.github.com/en/authentication/connecting-to-github-with-ssh/generating-a-new-ssh-key-and-adding-it-to-the-ssh-agent for details.
+
+Then retry the command`)
+
+// offerKeyGeneration asks to generate ~/.ssh/id_ed25519 when no local key exists
+func offerKeyGeneration() (*awsinternal.SSHKeyInfo, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+	path := filepath.Join(homeDir, ".ssh", "id_ed25519")
+
+	// Never overwrite a private key whose public half went missing
+	if _, err := os.Stat(path); err == nil {
+		return nil, fmt.Errorf("%s exists but %s.pub is missing; recreate it with: ssh-keygen -y -f %s > %s.pub", path, path, path, path)
+	}
+
+	confirmModel := ui.NewConfirmationModel(fmt.Sprintf("No SSH key found. Generate an ed25519 key at %s?", path))
+	p := tea.NewProgram(confirmModel)
+	m, err := p.Run()
+	if err != nil {
+		return nil, fmt.Errorf("error running confirmation prompt: %w", err)
+	}
+
+	if !m.(ui.ConfirmationModel).Selected() {
+		return nil, errNoSSHKey
+	}
+
+	passphrase, err := readNewPassphrase()
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := awsinternal.GenerateED25519Key(path, keyComment(), passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("SSH key generated", "path", key.Path)
+	fmt.Printf("Generated SSH key: %s (%s)\n", key.Path, key.Type)
+	if len(passphrase) > 0 {
+		fmt.Printf("Add it to ssh-agent to avoid retyping the passphrase: ssh-add %s\n", path)
+	}
+	return key, nil
+}
+
+// readNewPassphrase prompts twice for an optional passphrase without echoing it.
+// Without a terminal the key is generated without a passphrase.
+func readNewPassphrase() ([]byte, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return nil, nil
+	}
+
+	fmt.Print("Enter passphrase (empty for no passphrase): ")
+	passphrase, err := term.ReadPassword(fd)
+	fmt.Println()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	if len(passphrase) == 0 {
+		return nil, nil
+	}
+
+	fmt.Print("Enter same passphrase again: ")
+	confirm, err := term.ReadPassword(fd)
+	fmt.Println()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	if !bytes.Equal(passphrase, confirm) {
+		return nil, fmt.Errorf("passphrases do not match")
+	}
+
+	return passphrase, nil
+}
+
+// keyComment returns user@host for the generated key, like ssh-keygen does
+func keyComment() string {
+	username := "clouddley"
+	if current, err := user.Current(); err == nil {
+		username = current.Username
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		return username
+	}
+	return username + "@" + hostname
+}
@@ -0,0 +1,522 @@
+package aws
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/user"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/lipgloss"
+	awsinternal "github.com/clouddley/clouddley/internal/aws"
+	"github.com/clouddley/clouddley/internal/log"
+	"github.com/clouddley/clouddley/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// maxKeyPairNameLength is the longest key pair name EC2 accepts
+const maxKeyPairNameLength = 255
+
+var keysCmd = &cobra.Command{
+	Use:     "keys",
+	Aliases: []string{"key"},
+	Short:   "Manage AWS SSH key pairs",
+	Long: `List, import, delete and rotate the EC2 key pairs used to launch instances.
+
+Each teammate can import their own key pair and launch with
+'clouddley vm aws create --key-name <name>'.`,
+	Example: `  clouddley vm aws keys list
+  clouddley vm aws keys import --name alice-laptop --public-key ~/.ssh/id_ed25519.pub
+  clouddley vm aws keys rotate --name alice-laptop --public-key ~/.ssh/id_ed25519_new.pub
+  clouddley vm aws keys delete --name alice-laptop`,
+}
+
+var keysListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List key pairs and their fingerprints",
+	Run:     runKeysList,
+}
+
+var keysImportCmd = &cobra.Command{
+	Use:   "import [--name <key-name>] [--public-key <path>]",
+	Short: "Import a local SSH public key as a key pair",
+	Long:  `Import a local SSH public key into AWS. When --public-key is omitted you can pick one of the keys in ~/.ssh.`,
+	Run:   runKeysImport,
+}
+
+var keysDeleteCmd = &cobra.Command{
+	Use:   "delete --name <key-name> [--yes]",
+	Short: "Delete a key pair from AWS",
+	Long:  `Delete a key pair from AWS. Running instances keep the key in their authorized_keys.`,
+	Run:   runKeysDelete,
+}
+
+var keysRotateCmd = &cobra.Command{
+	Use:   "rotate [--name <key-name>] [--public-key <path>] [--yes]",
+	Short: "Replace a key pair with a new public key",
+	Long: `Replace the public key stored under a key pair name. EC2 key pairs cannot be
+updated in place, so the old key pair is deleted and the new key imported under
+the same name. Only instances launched afterwards use the new key.`,
+	Run: runKeysRotate,
+}
+
+func init() {
+	keysImportCmd.Flags().String("name", awsinternal.DefaultKeyPairName, "Key pair name")
+	keysImportCmd.Flags().String("public-key", "", "Path to the SSH public key to import")
+
+	keysDeleteCmd.Flags().String("name", "", "Key pair name (required)")
+	keysDeleteCmd.Flags().BoolP("yes", "y", false, "Skip confirmation prompt")
+	keysDeleteCmd.MarkFlagRequired("name")
+
+	keysRotateCmd.Flags().String("name", awsinternal.DefaultKeyPairName, "Key pair name")
+	keysRotateCmd.Flags().String("public-key", "", "Path to the new SSH public key")
+	keysRotateCmd.Flags().BoolP("yes", "y", false, "Skip confirmation prompt")
+
+	keysCmd.AddCommand(keysListCmd)
+	keysCmd.AddCommand(keysImportCmd)
+	keysCmd.AddCommand(keysDeleteCmd)
+	keysCmd.AddCommand(keysRotateCmd)
+}
+
+// validateKeyPairName checks a key pair name against the EC2 naming rules
+func validateKeyPairName(name string) error {
+	if name == "" {
+		return fmt.Errorf("key pair name cannot be empty")
+	}
+	if len(name) > maxKeyPairNameLength {
+		return fmt.Errorf("key pair name is longer than %d characters", maxKeyPairNameLength)
+	}
+	if strings.TrimSpace(name) != name {
+		return fmt.Errorf("key pair name cannot start or end with whitespace")
+	}
+	for _, r := range name {
+		if r < 0x20 || r > 0x7e {
+			return fmt.Errorf("key pair name %q must contain printable ASCII characters only", name)
+		}
+	}
+	return nil
+}
+
+// perUserKeyPairName returns a key pair name for the current OS user, e.g. clouddley-alice
+func perUserKeyPairName() string {
+	current, err := user.Current()
+	if err != nil {
+		return ""
+	}
+	return keyPairNameForUser(current.Username)
+}
+
+// keyPairNameForUser builds a valid key pair name from an OS username, dropping any
+// Windows domain prefix and characters EC2 would reject
+func keyPairNameForUser(username string) string {
+	if i := strings.LastIndex(username, `\`); i >= 0 {
+		username = username[i+1:]
+	}
+
+	var b strings.Builder
+	for _, r := range strings.ToLower(username) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			b.WriteRune(r)
+		}
+	}
+
+	if b.Len() == 0 {
+		return ""
+	}
+	return "clouddley-" + b.String()
+}
+
+// readPublicKeyForImport reads the public key at path, or lets the user pick a local key
+func readPublicKeyForImport(path string) (string, string, error) {
+	if path == "" {
+		key, err := selectLocalSSHKey()
+		if err != nil {
+			return "", "", err
+		}
+		path = key.Path
+	}
+
+	content, err := awsinternal.ReadSSHPublicKey(path)
+	if err != nil {
+		return "", "", err
+	}
+
+	// Validate the key locally before touching AWS
+	if err := awsinternal.ValidateImportableKey(content); err != nil {
+		return "", "", fmt.Errorf("%s: %w", path, err)
+	}
+
+	return path, content, nil
+}
+
+// instancesUsingKeyPair returns the IDs of non-terminated instances launched with the key pair
+func instancesUsingKeyPair(ctx context.Context, client *ec2.Client, name string) ([]string, error) {
+	result, err := client.DescribeInstances(ctx, &ec2.DescribeInstancesInput{
+		Filters: []types.Filter{
+			{
+				Name:   aws.String("key-name"),
+				Values: []string{name},
+			},
+			{
+				Name:   aws.String("instance-state-name"),
+				Values: []string{"pending", "running", "stopping", "stopped"},
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe instances: %w", err)
+	}
+
+	var ids []string
+	for _, reservation := range result.Reservations {
+		for _, instance := range reservation.Instances {
+			ids = append(ids, aws.ToString(instance.InstanceId))
+		}
+	}
+	return ids, nil
+}
+
+// confirmKeyChange asks a y/n question and reports whether the user agreed
+func confirmKeyChange(question string) (bool, error) {
+	fmt.Printf("%s (y/n): ", question)
+
+	reader := bufio.NewReader(os.Stdin)
+	response, err := reader.ReadString('\n')
+	if err != nil {
+		return false, fmt.Errorf("error reading input: %w", err)
+	}
+
+	response = strings.TrimSpace(strings.ToLower(response))
+	return response == "y" || response == "yes", nil
+}
+
+func runKeysList(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+
+	// Validate AWS credentials
+	if err := awsinternal.ValidateAWSCredentials(ctx); err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+
+	cfg, err := awsinternal.GetAWSConfig(ctx)
+	if err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error getting AWS config: %v", err)))
+		return
+	}
+
+	client, err := awsinternal.GetEC2Client(ctx)
+	if err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Failed to create EC2 client: %v", err)))
+		return
+	}
+
+	keys, err := awsinternal.ListKeyPairs(ctx, client)
+	if err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+
+	if len(keys) == 0 {
+		fmt.Println(ui.FormatOutput("✓ Key Pairs", fmt.Sprintf("No key pairs found in region %s", cfg.Region)))
+		return
+	}
+
+	localKeys, err := awsinternal.LocalPublicKeys()
+	if err != nil {
+		log.Warn("Could not read local SSH keys", "error", err)
+	}
+
+	fmt.Printf("Key pairs in region %s:\n\n", cfg.Region)
+	displayKeyPairsTable(keys, localKeys)
+}
+
+func displayKeyPairsTable(keys []awsinternal.KeyPairInfo, localKeys []awsinternal.PublicKeySource) {
+	columns := []table.Column{
+		{Title: "Name", Width: 30},
+		{Title: "Type", Width: 8},
+		{Title: "Fingerprint", Width: 48},
+		{Title: "Local Key", Width: 30},
+		{Title: "Created", Width: 20},
+	}
+
+	rows := make([]table.Row, len(keys))
+	for i, key := range keys {
+		created := "N/A"
+		if !key.CreatedAt.IsZero() {
+			created = key.CreatedAt.Format("2006-01-02 15:04:05")
+		}
+		local := "-"
+		if match, ok := awsinternal.MatchKeyFingerprint(key.Fingerprint, localKeys); ok {
+			local = match.Source
+		}
+		rows[i] = table.Row{
+			formatKeyPairName(key.Name),
+			key.Type,
+			key.Fingerprint,
+			local,
+			created,
+		}
+	}
+
+	t := table.New(
+		table.WithColumns(columns),
+		table.WithRows(rows),
+		table.WithFocused(false),
+		table.WithHeight(len(keys)+2),
+	)
+
+	s := table.DefaultStyles()
+	s.Header = s.Header.
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderForeground(lipgloss.Color("240")).
+		BorderBottom(true).
+		Bold(true).
+		Foreground(lipgloss.Color("#7D56F4"))
+
+	s.Cell = s.Cell.
+		Foreground(lipgloss.Color("#FAFAFA"))
+
+	t.SetStyles(s)
+
+	fmt.Println(t.View())
+}
+
+// formatKeyPairName marks the key pair create uses by default
+func formatKeyPairName(name string) string {
+	if name == awsinternal.DefaultKeyPairName {
+		return name + " (default)"
+	}
+	return name
+}
+
+func runKeysImport(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+
+	name, _ := cmd.Flags().GetString("name")
+	publicKeyPath, _ := cmd.Flags().GetString("public-key")
+
+	if err := validateKeyPairName(name); err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+
+	// Validate AWS credentials
+	if err := awsinternal.ValidateAWSCredentials(ctx); err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+
+	client, err := awsinternal.GetEC2Client(ctx)
+	if err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Failed to create EC2 client: %v", err)))
+		return
+	}
+
+	exists, err := awsinternal.CheckAWSKeyPair(ctx, client, name)
+	if err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+	if exists {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: key pair %s already exists, use 'clouddley vm aws keys rotate --name %s' to replace it", name, name)))
+		return
+	}
+
+	publicKeyPath, content, err := readPublicKeyForImport(publicKeyPath)
+	if err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+
+	if err := awsinternal.ImportSSHKeyPair(ctx, client, name, content); err != nil {
+		log.Error("Failed to import key pair", "key", name, "error", err)
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+
+	fingerprint, _ := awsinternal.PublicKeyFingerprint(content)
+	log.Info("Key pair imported", "key", name, "path", publicKeyPath)
+	fmt.Println(ui.FormatOutput("✓ Success", fmt.Sprintf("Imported %s as key pair %s (%s)", publicKeyPath, name, fingerprint)))
+}
+
+func runKeysDelete(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+
+	name, _ := cmd.Flags().GetString("name")
+	skipConfirmation, _ := cmd.Flags().GetBool("yes")
+
+	// Validate AWS credentials
+	if err := awsinternal.ValidateAWSCredentials(ctx); err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+
+	client, err := awsinternal.GetEC2Client(ctx)
+	if err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Failed to create EC2 client: %v", err)))
+		return
+	}
+
+	exists, err := awsinternal.CheckAWSKeyPair(ctx, client, name)
+	if err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+	if !exists {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: key pair %s not found", name)))
+		return
+	}
+
+	inUse, err := instancesUsingKeyPair(ctx, client, name)
+	if err != nil {
+		log.Warn("Could not check which instances use the key pair", "key", name, "error", err)
+	}
+	if len(inUse) > 0 {
+		log.Warn("Key pair is used by existing instances; they keep working but the key cannot be used for new launches",
+			"key", name, "instances", strings.Join(inUse, ", "))
+	}
+
+	if !skipConfirmation {
+		ok, err := confirmKeyChange(fmt.Sprintf("Are you sure you want to delete key pair %s?", name))
+		if err != nil {
+			fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+			return
+		}
+		if !ok {
+			fmt.Println("Operation cancelled")
+			return
+		}
+	}
+
+	if err := awsinternal.DeleteKeyPair(ctx, client, name); err != nil {
+		log.Error("Failed to delete key pair", "key", name, "error", err)
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+
+	log.Info("Key pair deleted", "key", name)
+	fmt.Println(ui.FormatOutput("✓ Success", fmt.Sprintf("Key pair %s deleted", name)))
+}
+
+func runKeysRotate(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+
+	name, _ := cmd.Flags().GetString("name")
+	publicKeyPath, _ := cmd.Flags().GetString("public-key")
+	skipConfirmation, _ := cmd.Flags().GetBool("yes")
+
+	if err := validateKeyPairName(name); err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+
+	// Validate AWS credentials
+	if err := awsinternal.ValidateAWSCredentials(ctx); err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+
+	client, err := awsinternal.GetEC2Client(ctx)
+	if err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Failed to create EC2 client: %v", err)))
+		return
+	}
+
+	current, err := awsinternal.GetKeyPair(ctx, client, name)
+	if err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+	if current == nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: key pair %s not found, use 'clouddley vm aws keys import --name %s' to create it", name, name)))
+		return
+	}
+
+	publicKeyPath, content, err := readPublicKeyForImport(publicKeyPath)
+	if err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+
+	newFingerprint, err := awsinternal.AWSKeyFingerprint(content)
+	if err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+	if awsinternal.FingerprintsEqual(newFingerprint, current.Fingerprint) {
+		fmt.Println(ui.FormatOutput("✓ Key Pair", fmt.Sprintf("Key pair %s already uses %s", name, publicKeyPath)))
+		return
+	}
+
+	fmt.Printf("Key pair %s will be replaced:\n", name)
+	fmt.Printf("  - %s\n", current.Fingerprint)
+	fmt.Printf("  + %s (%s)\n", newFingerprint, publicKeyPath)
+
+	if inUse, err := instancesUsingKeyPair(ctx, client, name); err == nil && len(inUse) > 0 {
+		log.Warn("Existing instances keep the old key in authorized_keys", "instances", strings.Join(inUse, ", "))
+	}
+
+	if !skipConfirmation {
+		ok, err := confirmKeyChange("Rotate this key pair?")
+		if err != nil {
+			fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+			return
+		}
+		if !ok {
+			fmt.Println("Operation cancelled")
+			return
+		}
+	}
+
+	// EC2 cannot rename or update a key pair, so import the new key under a temporary
+	// name first: a key EC2 rejects then leaves the old pair untouched
+	tempName := rotationKeyPairName(name)
+	if stale, err := awsinternal.GetKeyPair(ctx, client, tempName); err == nil && stale != nil {
+		if err := awsinternal.DeleteKeyPair(ctx, client, tempName); err != nil {
+			fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+			return
+		}
+	}
+	if err := awsinternal.ImportSSHKeyPair(ctx, client, tempName, content); err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		fmt.Printf("Key pair %s was not changed\n", name)
+		return
+	}
+
+	if err := awsinternal.DeleteKeyPair(ctx, client, name); err != nil {
+		deleteRotationKeyPair(ctx, client, tempName)
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+
+	if err := awsinternal.ImportSSHKeyPair(ctx, client, name, content); err != nil {
+		log.Error("Failed to import the new key after deleting the old one", "key", name, "error", err)
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		fmt.Printf("The new key is available as key pair %s; retry with: clouddley vm aws keys import --name %s --public-key %s\n", tempName, name, publicKeyPath)
+		return
+	}
+	deleteRotationKeyPair(ctx, client, tempName)
+
+	log.Info("Key pair rotated", "key", name, "path", publicKeyPath)
+	fmt.Println(ui.FormatOutput("✓ Success", fmt.Sprintf("Key pair %s now uses %s", name, publicKeyPath)))
+}
+
+// rotationKeyPairName is the temporary name a rotated key is imported under
+func rotationKeyPairName(name string) string {
+	return name + "-rotating"
+}
+
+// deleteRotationKeyPair removes the temporary key pair of a rotation
+func deleteRotationKeyPair(ctx context.Context, client *ec2.Client, name string) {
+	if err := awsinternal.DeleteKeyPair(ctx, client, name); err != nil {
+		log.Warn("Failed to delete temporary key pair", "key", name, "error", err)
+	}
+}
@@ -0,0 +1,64 @@
+package aws
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateKeyPairName(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expectError bool
+	}{
+		{"default key", "clouddley-default-key", false},
+		{"with spaces inside", "alice laptop", false},
+		{"empty", "", true},
+		{"leading space", " alice", true},
+		{"trailing space", "alice ", true},
+		{"non-ASCII", "alicé", true},
+		{"too long", strings.Repeat("a", 256), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateKeyPairName(tt.input)
+			if tt.expectError && err == nil {
+				t.Errorf("Expected error for %q, got nil", tt.input)
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Expected no error for %q, got: %v", tt.input, err)
+			}
+		})
+	}
+}
+
+func TestFormatKeyPairName(t *testing.T) {
+	if result := formatKeyPairName("clouddley-default-key"); result != "clouddley-default-key (default)" {
+		t.Errorf("Expected default marker, got %s", result)
+	}
+	if result := formatKeyPairName("alice-laptop"); result != "alice-laptop" {
+		t.Errorf("Expected alice-laptop, got %s", result)
+	}
+}
+
+func TestKeyPairNameForUser(t *testing.T) {
+	tests := []struct {
+		username string
+		expected string
+	}{
+		{"alice", "clouddley-alice"},
+		{"Alice.Smith", "clouddley-alice.smith"},
+		{`CORP\bob`, "clouddley-bob"},
+		{"j doe!", "clouddley-jdoe"},
+		{"田中", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.username, func(t *testing.T) {
+			if result := keyPairNameForUser(tt.username); result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
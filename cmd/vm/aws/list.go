@@ -17,12 +17,31 @@ import (
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List AWS EC2 instances created by Clouddley CLI",
-	Long:  `List all AWS EC2 instances that were created by the Clouddley CLI.`,
-	Run:   runList,
+	Long: `List all AWS EC2 instances that were created by the Clouddley CLI.
+
+With --cost, each instance shows what it costs per hour and per month: the
+on-demand compute price while it is running plus its attached EBS volumes,
+which are billed even when it is stopped.`,
+	Example: `  clouddley vm aws list
+  clouddley vm aws list --cost
+  clouddley vm aws list --cost --hours-per-month 220`,
+	Run: runList,
+}
+
+func init() {
+	listCmd.Flags().Bool("cost", false, "Show the hourly and monthly cost of each instance and the fleet total")
+	listCmd.Flags().Float64("hours-per-month", 0, "Hours a month instances run, for monthly costs (defaults to $CLOUDDLEY_HOURS_PER_MONTH or a full month)")
 }
 
 func runList(cmd *cobra.Command, args []string) {
 	ctx := context.Background()
+	withCost, _ := cmd.Flags().GetBool("cost")
+	hoursPerMonth, _ := cmd.Flags().GetFloat64("hours-per-month")
+
+	if err := awsinternal.ConfigureHoursPerMonth(hoursPerMonth); err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
 
 	// Validate AWS credentials
 	if err := awsinternal.ValidateAWSCredentials(ctx); err != nil {
@@ -38,7 +57,12 @@ func runList(cmd *cobra.Command, args []string) {
 	}
 
 	// List instances
-	instances, err := listCloudleyInstances(ctx)
+	client, err := awsinternal.GetEC2Client(ctx)
+	if err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+	instances, err := listCloudleyInstances(ctx, client)
 	if err != nil {
 		fmt.Println(ui.FormatError(fmt.Sprintf("Error listing instances: %v", err)))
 		return
@@ -51,7 +75,33 @@ func runList(cmd *cobra.Command, args []string) {
 
 	// Create and display table
 	fmt.Printf("Clouddley CLI Instances in region %s:\n\n", cfg.Region)
-	displayInstancesTable(instances)
+	if !withCost {
+		displayInstancesTable(instances, false)
+		return
+	}
+
+	if err := attachInstanceCosts(ctx, client, instances); err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error getting instance costs: %v", err)))
+		return
+	}
+	displayInstancesTable(instances, true)
+	fmt.Println(formatFleetCost(summarizeFleetCost(instances)))
+}
+
+// formatFleetCost is the footer under the cost table
+func formatFleetCost(total fleetCost) string {
+	prefix := ""
+	if total.Estimated {
+		prefix = "~"
+	}
+	footer := fmt.Sprintf("Monthly burn rate: %s$%.2f (%s$%.4f/hour with storage) across %d instance(s)", prefix, total.Monthly, prefix, total.Hourly, total.Priced)
+	if total.Unpriced > 0 {
+		footer += fmt.Sprintf("\n%d instance(s) could not be priced and are not included", total.Unpriced)
+	}
+	if total.Estimated {
+		footer += fmt.Sprintf("\n~ Estimated from the %s price snapshot; live pricing was unavailable", awsinternal.EmbeddedPricingSnapshot().GeneratedAt)
+	}
+	return footer + "\nStopped instances are charged for storage only; spot instances use the current spot price, others on-demand"
 }
 
 type ClouddleyInstance struct {
@@ -60,15 +110,18 @@ type ClouddleyInstance struct {
 	State        string
 	InstanceType string
 	PublicIP     string
+	ElasticIP    bool
 	LaunchTime   string
+	// AZ is the availability zone the instance runs in, which sets its spot price
+	AZ string
+	// Spot is set for spot instances, which are billed at the spot price
+	Spot bool
+	// Volumes and Cost are only set when costs are requested
+	Volumes []awsinternal.EBSVolume
+	Cost    *InstanceCost
 }
 
-func listCloudleyInstances(ctx context.Context) ([]ClouddleyInstance, error) {
-	client, err := awsinternal.GetEC2Client(ctx)
-	if err != nil {
-		return nil, err
-	}
-
+func listCloudleyInstances(ctx context.Context, client *ec2.Client) ([]ClouddleyInstance, error) {
 	// Describe instances with Clouddley tag
 	result, err := client.DescribeInstances(ctx, &ec2.DescribeInstancesInput{
 		Filters: []types.Filter{
@@ -104,6 +157,11 @@ func listCloudleyInstances(ctx context.Context) ([]ClouddleyInstance, error) {
 				publicIP = *instance.PublicIpAddress
 			}
 
+			var zone string
+			if instance.Placement != nil {
+				zone = aws.ToString(instance.Placement.AvailabilityZone)
+			}
+
 			launchTime := "N/A"
 			if instance.LaunchTime != nil {
 				launchTime = instance.LaunchTime.Format("2006-01-02 15:04:05")
@@ -116,22 +174,60 @@ func listCloudleyInstances(ctx context.Context) ([]ClouddleyInstance, error) {
 				InstanceType: string(instance.InstanceType),
 				PublicIP:     publicIP,
 				LaunchTime:   launchTime,
+				Spot:         instance.InstanceLifecycle == types.InstanceLifecycleTypeSpot,
+				AZ:           zone,
 			})
 		}
 	}
 
+	// Mark instances whose address is a Clouddley Elastic IP
+	instanceIDs := make([]string, len(instances))
+	for i, instance := range instances {
+		instanceIDs[i] = instance.InstanceID
+	}
+	addresses, err := getInstanceElasticIPs(ctx, client, instanceIDs...)
+	if err != nil {
+		return nil, err
+	}
+	elastic := elasticIPsByInstance(addresses)
+	for i := range instances {
+		instances[i].ElasticIP = elastic[instances[i].InstanceID]
+	}
+
 	return instances, nil
 }
 
-func displayInstancesTable(instances []ClouddleyInstance) {
+// elasticIPsByInstance returns the set of instance IDs that have an Elastic IP attached
+func elasticIPsByInstance(addresses []types.Address) map[string]bool {
+	elastic := make(map[string]bool, len(addresses))
+	for _, address := range addresses {
+		if address.InstanceId != nil {
+			elastic[*address.InstanceId] = true
+		}
+	}
+	return elastic
+}
+
+// formatPublicIP labels Elastic IPs so users know the address survives stop and start
+func formatPublicIP(ip string, elastic bool) string {
+	if elastic {
+		return ip + " (EIP)"
+	}
+	return ip
+}
+
+func displayInstancesTable(instances []ClouddleyInstance, withCost bool) {
 	columns := []table.Column{
 		{Title: "Instance ID", Width: 20},
 		{Title: "Name", Width: 20},
 		{Title: "State", Width: 12},
 		{Title: "Type", Width: 12},
-		{Title: "Public IP", Width: 15},
+		{Title: "Public IP", Width: 21},
 		{Title: "Launch Time", Width: 20},
 	}
+	if withCost {
+		columns = append(columns, table.Column{Title: "Compute/hr", Width: 10}, table.Column{Title: "Monthly", Width: 10})
+	}
 
 	rows := make([]table.Row, len(instances))
 	for i, instance := range instances {
@@ -140,9 +236,13 @@ func displayInstancesTable(instances []ClouddleyInstance) {
 			instance.Name,
 			instance.State,
 			instance.InstanceType,
-			instance.PublicIP,
+			formatPublicIP(instance.PublicIP, instance.ElasticIP),
 			instance.LaunchTime,
 		}
+		if withCost {
+			hourly, monthly := formatInstanceCost(instance.Cost)
+			rows[i] = append(rows[i], hourly, monthly)
+		}
 	}
 
 	t := table.New(
@@ -508,3 +508,37 @@ type EC2Instance struct {
 type EC2ListAPI interface {
 	DescribeInstances(ctx context.Context, params *ec2.DescribeInstancesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error)
 }
+
+func TestElasticIPsByInstance(t *testing.T) {
+	addresses := []types.Address{
+		{AllocationId: stringPtr("eipalloc-1"), PublicIp: stringPtr("203.0.113.10"), InstanceId: stringPtr("i-1234567890abcdef0")},
+		{AllocationId: stringPtr("eipalloc-2"), PublicIp: stringPtr("203.0.113.11")},
+	}
+
+	elastic := elasticIPsByInstance(addresses)
+
+	if len(elastic) != 1 {
+		t.Fatalf("Expected 1 instance with an Elastic IP, got %d", len(elastic))
+	}
+	if !elastic["i-1234567890abcdef0"] {
+		t.Error("Expected i-1234567890abcdef0 to have an Elastic IP")
+	}
+}
+
+func TestFormatPublicIP(t *testing.T) {
+	tests := []struct {
+		ip       string
+		elastic  bool
+		expected string
+	}{
+		{"203.0.113.10", true, "203.0.113.10 (EIP)"},
+		{"203.0.113.10", false, "203.0.113.10"},
+		{"N/A", false, "N/A"},
+	}
+
+	for _, tt := range tests {
+		if result := formatPublicIP(tt.ip, tt.elastic); result != tt.expected {
+			t.Errorf("formatPublicIP(%q, %v) = %q, expected %q", tt.ip, tt.elastic, result, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,571 @@
+package aws
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	awsinternal "github.com/clouddley/clouddley/internal/aws"
+	"github.com/clouddley/clouddley/internal/log"
+	"github.com/clouddley/clouddley/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// managedNetworkTagKey marks resources that belong to the Clouddley-managed network
+	managedNetworkTagKey   = "ClouddleyNetwork"
+	managedNetworkTagValue = "managed"
+	managedVPCName         = "clouddley-vpc"
+	defaultNetworkCIDR     = "10.100.0.0/16"
+	managedSubnetPrefixLen = 20
+)
+
+var networkCmd = &cobra.Command{
+	Use:   "network",
+	Short: "Manage the Clouddley-managed VPC",
+	Long: `Create or remove a Clouddley-managed VPC for accounts without a default VPC.
+
+When the region has no default VPC, 'clouddley vm aws create' launches into the
+Clouddley-managed VPC created by 'network init'.`,
+	Example: `  clouddley vm aws network init
+  clouddley vm aws network init --cidr 10.50.0.0/16 --zones 2
+  clouddley vm aws network destroy`,
+}
+
+var networkInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Create a Clouddley-managed VPC with public subnets",
+	Long:  `Create a tagged VPC with one public subnet per availability zone, an internet gateway and a route table.`,
+	Run:   runNetworkInit,
+}
+
+var networkDestroyCmd = &cobra.Command{
+	Use:   "destroy [--yes]",
+	Short: "Delete the Clouddley-managed VPC",
+	Long:  `Delete the Clouddley-managed VPC and its subnets, route table, internet gateway and security groups. Fails if instances are still running in it.`,
+	Run:   runNetworkDestroy,
+}
+
+func init() {
+	networkInitCmd.Flags().String("cidr", defaultNetworkCIDR, "IPv4 CIDR block for the VPC (/16 to /20)")
+	networkInitCmd.Flags().Int("zones", 3, "Number of availability zones to create public subnets in")
+	networkDestroyCmd.Flags().BoolP("yes", "y", false, "Skip confirmation prompt")
+
+	networkCmd.AddCommand(networkInitCmd)
+	networkCmd.AddCommand(networkDestroyCmd)
+}
+
+// managedTags returns the tags applied to every Clouddley-managed network resource
+func managedTags(name string) []types.Tag {
+	return []types.Tag{
+		{Key: aws.String("Name"), Value: aws.String(name)},
+		{Key: aws.String("CreatedBy"), Value: aws.String("Clouddley")},
+		{Key: aws.String(managedNetworkTagKey), Value: aws.String(managedNetworkTagValue)},
+	}
+}
+
+func managedTagSpec(resourceType types.ResourceType, name string) []types.TagSpecification {
+	return []types.TagSpecification{
+		{
+			ResourceType: resourceType,
+			Tags:         managedTags(name),
+		},
+	}
+}
+
+func managedNetworkFilter() types.Filter {
+	return types.Filter{
+		Name:   aws.String("tag:" + managedNetworkTagKey),
+		Values: []string{managedNetworkTagValue},
+	}
+}
+
+// getManagedVPC returns the ID of the Clouddley-managed VPC, or "" when none exists
+func getManagedVPC(ctx context.Context, client *ec2.Client) (string, error) {
+	result, err := client.DescribeVpcs(ctx, &ec2.DescribeVpcsInput{
+		Filters: []types.Filter{managedNetworkFilter()},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to describe VPCs: %w", err)
+	}
+
+	if len(result.Vpcs) == 0 {
+		return "", nil
+	}
+
+	return aws.ToString(result.Vpcs[0].VpcId), nil
+}
+
+func runNetworkInit(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+
+	cidr, _ := cmd.Flags().GetString("cidr")
+	zones, _ := cmd.Flags().GetInt("zones")
+
+	if zones < 1 {
+		fmt.Println(ui.FormatError("Error: --zones must be at least 1"))
+		return
+	}
+
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil || network.IP.To4() == nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: invalid IPv4 CIDR %q", cidr)))
+		return
+	}
+	if ones, _ := network.Mask.Size(); ones < 16 || ones > managedSubnetPrefixLen {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: VPC CIDR must be between /16 and /%d", managedSubnetPrefixLen)))
+		return
+	}
+	if capacity := managedSubnetCapacity(network); zones > capacity {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %s has room for %d /%d subnet(s), use a larger --cidr or fewer --zones", network, capacity, managedSubnetPrefixLen)))
+		return
+	}
+
+	// Validate AWS credentials
+	if err := awsinternal.ValidateAWSCredentials(ctx); err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+
+	client, err := awsinternal.GetEC2Client(ctx)
+	if err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Failed to create EC2 client: %v", err)))
+		return
+	}
+
+	existing, err := getManagedVPC(ctx, client)
+	if err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+	if existing != "" {
+		fmt.Println(ui.FormatOutput("✓ Network", fmt.Sprintf("Clouddley-managed VPC %s already exists", existing)))
+		return
+	}
+
+	fmt.Println("Creating Clouddley-managed network...")
+	summary, err := createManagedNetwork(ctx, client, network.String(), zones)
+	if err != nil {
+		log.Error("Failed to create managed network", "error", err)
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error creating network: %v", err)))
+		fmt.Println("If any resources were left behind, run 'clouddley vm aws network destroy' to clean them up.")
+		return
+	}
+
+	fmt.Println(ui.FormatOutput("✓ Success", "Clouddley-managed network created successfully!"))
+	fmt.Println()
+
+	networkTable := ui.NewInstanceDetailsTable()
+	networkTable.AddRow("VPC ID", summary.VPCID)
+	networkTable.AddRow("CIDR", summary.CIDR)
+	networkTable.AddRow("Internet Gateway", summary.InternetGatewayID)
+	networkTable.AddRow("Route Table", summary.RouteTableID)
+	for _, subnet := range summary.Subnets {
+		networkTable.AddRow("Subnet "+subnet.AZ, fmt.Sprintf("%s (%s)", subnet.ID, subnet.CIDR))
+	}
+	fmt.Println(networkTable.Render())
+}
+
+type managedSubnet struct {
+	ID   string
+	AZ   string
+	CIDR string
+}
+
+type managedNetwork struct {
+	VPCID             string
+	CIDR              string
+	InternetGatewayID string
+	RouteTableID      string
+	Subnets           []managedSubnet
+}
+
+// managedSubnetCapacity returns how many managed subnets fit in a VPC CIDR
+func managedSubnetCapacity(network *net.IPNet) int {
+	ones, _ := network.Mask.Size()
+	if ones > managedSubnetPrefixLen {
+		return 0
+	}
+	return 1 << (managedSubnetPrefixLen - ones)
+}
+
+// createManagedNetwork creates the VPC and its public subnets. When a step fails after
+// the VPC exists, everything created so far is deleted again
+func createManagedNetwork(ctx context.Context, client *ec2.Client, cidr string, zoneCount int) (_ *managedNetwork, err error) {
+	_, vpcNetwork, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CIDR %q: %w", cidr, err)
+	}
+	if capacity := managedSubnetCapacity(vpcNetwork); zoneCount > capacity {
+		return nil, fmt.Errorf("%s has room for %d /%d subnet(s), %d requested", cidr, capacity, managedSubnetPrefixLen, zoneCount)
+	}
+
+	zones, err := getAvailableZones(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	if len(zones) > zoneCount {
+		zones = zones[:zoneCount]
+	}
+
+	vpcResult, err := client.CreateVpc(ctx, &ec2.CreateVpcInput{
+		CidrBlock:         aws.String(cidr),
+		TagSpecifications: managedTagSpec(types.ResourceTypeVpc, managedVPCName),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create VPC: %w", err)
+	}
+	vpcID := aws.ToString(vpcResult.Vpc.VpcId)
+	log.Debug("Created VPC", "vpc", vpcID)
+
+	defer func() {
+		if err == nil {
+			return
+		}
+		if cleanupErr := destroyManagedNetwork(ctx, client, vpcID); cleanupErr != nil {
+			log.Warn("Failed to roll back partially created network", "vpc", vpcID, "error", cleanupErr)
+			return
+		}
+		log.Info("Rolled back partially created network", "vpc", vpcID)
+	}()
+
+	waiter := ec2.NewVpcAvailableWaiter(client)
+	if err := waiter.Wait(ctx, &ec2.DescribeVpcsInput{VpcIds: []string{vpcID}}, 2*time.Minute); err != nil {
+		return nil, fmt.Errorf("failed waiting for VPC %s: %w", vpcID, err)
+	}
+
+	_, err = client.ModifyVpcAttribute(ctx, &ec2.ModifyVpcAttributeInput{
+		VpcId:              aws.String(vpcID),
+		EnableDnsHostnames: &types.AttributeBooleanValue{Value: aws.Bool(true)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to enable DNS hostnames: %w", err)
+	}
+
+	igwResult, err := client.CreateInternetGateway(ctx, &ec2.CreateInternetGatewayInput{
+		TagSpecifications: managedTagSpec(types.ResourceTypeInternetGateway, "clouddley-igw"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create internet gateway: %w", err)
+	}
+	igwID := aws.ToString(igwResult.InternetGateway.InternetGatewayId)
+
+	_, err = client.AttachInternetGateway(ctx, &ec2.AttachInternetGatewayInput{
+		InternetGatewayId: aws.String(igwID),
+		VpcId:             aws.String(vpcID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to attach internet gateway: %w", err)
+	}
+
+	rtResult, err := client.CreateRouteTable(ctx, &ec2.CreateRouteTableInput{
+		VpcId:             aws.String(vpcID),
+		TagSpecifications: managedTagSpec(types.ResourceTypeRouteTable, "clouddley-public-rt"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create route table: %w", err)
+	}
+	rtID := aws.ToString(rtResult.RouteTable.RouteTableId)
+
+	_, err = client.CreateRoute(ctx, &ec2.CreateRouteInput{
+		RouteTableId:         aws.String(rtID),
+		DestinationCidrBlock: aws.String("0.0.0.0/0"),
+		GatewayId:            aws.String(igwID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create default route: %w", err)
+	}
+
+	network := &managedNetwork{
+		VPCID:             vpcID,
+		CIDR:              cidr,
+		InternetGatewayID: igwID,
+		RouteTableID:      rtID,
+	}
+
+	for i, zone := range zones {
+		subnetCIDR, err := nthSubnet(cidr, managedSubnetPrefixLen, i)
+		if err != nil {
+			return nil, err
+		}
+
+		subnetResult, err := client.CreateSubnet(ctx, &ec2.CreateSubnetInput{
+			VpcId:             aws.String(vpcID),
+			CidrBlock:         aws.String(subnetCIDR),
+			AvailabilityZone:  aws.String(zone),
+			TagSpecifications: managedTagSpec(types.ResourceTypeSubnet, "clouddley-public-"+zone),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create subnet in %s: %w", zone, err)
+		}
+		subnetID := aws.ToString(subnetResult.Subnet.SubnetId)
+
+		_, err = client.ModifySubnetAttribute(ctx, &ec2.ModifySubnetAttributeInput{
+			SubnetId:            aws.String(subnetID),
+			MapPublicIpOnLaunch: &types.AttributeBooleanValue{Value: aws.Bool(true)},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to enable public IPs on subnet %s: %w", subnetID, err)
+		}
+
+		_, err = client.AssociateRouteTable(ctx, &ec2.AssociateRouteTableInput{
+			RouteTableId: aws.String(rtID),
+			SubnetId:     aws.String(subnetID),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to associate route table with subnet %s: %w", subnetID, err)
+		}
+
+		network.Subnets = append(network.Subnets, managedSubnet{ID: subnetID, AZ: zone, CIDR: subnetCIDR})
+	}
+
+	return network, nil
+}
+
+// getAvailableZones returns the region's standard availability zones in name order
+func getAvailableZones(ctx context.Context, client *ec2.Client) ([]string, error) {
+	result, err := client.DescribeAvailabilityZones(ctx, &ec2.DescribeAvailabilityZonesInput{
+		Filters: []types.Filter{
+			{
+				Name:   aws.String("state"),
+				Values: []string{"available"},
+			},
+			{
+				Name:   aws.String("zone-type"),
+				Values: []string{"availability-zone"},
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe availability zones: %w", err)
+	}
+
+	var zones []string
+	for _, zone := range result.AvailabilityZones {
+		zones = append(zones, aws.ToString(zone.ZoneName))
+	}
+	sort.Strings(zones)
+
+	if len(zones) == 0 {
+		return nil, fmt.Errorf("no availability zones found")
+	}
+
+	return zones, nil
+}
+
+// nthSubnet returns the index-th subnet of the given prefix length inside an IPv4 CIDR
+func nthSubnet(cidr string, prefixLen int, index int) (string, error) {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return "", fmt.Errorf("invalid CIDR %q: %w", cidr, err)
+	}
+
+	ip := network.IP.To4()
+	if ip == nil {
+		return "", fmt.Errorf("CIDR %q is not IPv4", cidr)
+	}
+
+	ones, _ := network.Mask.Size()
+	if prefixLen < ones || prefixLen > 28 {
+		return "", fmt.Errorf("subnet prefix /%d does not fit in %s", prefixLen, cidr)
+	}
+	if index < 0 || index >= 1<<(prefixLen-ones) {
+		return "", fmt.Errorf("%s has room for %d /%d subnets", cidr, 1<<(prefixLen-ones), prefixLen)
+	}
+
+	base := binary.BigEndian.Uint32(ip)
+	subnet := make(net.IP, 4)
+	binary.BigEndian.PutUint32(subnet, base+uint32(index)<<(32-prefixLen))
+
+	return fmt.Sprintf("%s/%d", subnet, prefixLen), nil
+}
+
+func runNetworkDestroy(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+
+	skipConfirmation, _ := cmd.Flags().GetBool("yes")
+
+	// Validate AWS credentials
+	if err := awsinternal.ValidateAWSCredentials(ctx); err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+
+	client, err := awsinternal.GetEC2Client(ctx)
+	if err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Failed to create EC2 client: %v", err)))
+		return
+	}
+
+	vpcID, err := getManagedVPC(ctx, client)
+	if err != nil {
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error: %v", err)))
+		return
+	}
+	if vpcID == "" {
+		fmt.Println(ui.FormatOutput("✓ Network", "No Clouddley-managed VPC found"))
+		return
+	}
+
+	// Confirmation prompt (unless --yes flag is used)
+	if !skipConfirmation {
+		fmt.Printf("Are you sure you want to delete the Clouddley-managed VPC %s? (y/n): ", vpcID)
+
+		reader := bufio.NewReader(os.Stdin)
+		response, err := reader.ReadString('\n')
+		if err != nil {
+			fmt.Println(ui.FormatError(fmt.Sprintf("Error reading input: %v", err)))
+			return
+		}
+
+		response = strings.TrimSpace(strings.ToLower(response))
+		if response != "y" && response != "yes" {
+			fmt.Println("Operation cancelled")
+			return
+		}
+	}
+
+	fmt.Printf("Deleting Clouddley-managed VPC %s...\n", vpcID)
+	if err := destroyManagedNetwork(ctx, client, vpcID); err != nil {
+		log.Error("Failed to delete managed network", "vpc", vpcID, "error", err)
+		fmt.Println(ui.FormatError(fmt.Sprintf("Error deleting network: %v", err)))
+		return
+	}
+
+	log.Info("Managed network deleted", "vpc", vpcID)
+	fmt.Println(ui.FormatOutput("✓ Success", fmt.Sprintf("Clouddley-managed VPC %s deleted successfully", vpcID)))
+}
+
+func destroyManagedNetwork(ctx context.Context, client *ec2.Client, vpcID string) error {
+	vpcFilter := types.Filter{
+		Name:   aws.String("vpc-id"),
+		Values: []string{vpcID},
+	}
+
+	// Refuse to delete while instances still use the network
+	instances, err := client.DescribeInstances(ctx, &ec2.DescribeInstancesInput{
+		Filters: []types.Filter{
+			vpcFilter,
+			{
+				Name:   aws.String("instance-state-name"),
+				Values: []string{"pending", "running", "stopping", "stopped", "shutting-down"},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to describe instances: %w", err)
+	}
+
+	var inUse []string
+	for _, reservation := range instances.Reservations {
+		for _, instance := range reservation.Instances {
+			inUse = append(inUse, aws.ToString(instance.InstanceId))
+		}
+	}
+	if len(inUse) > 0 {
+		return fmt.Errorf("VPC still has instances: %s (delete them first with 'clouddley vm aws delete')", strings.Join(inUse, ", "))
+	}
+
+	// Security groups other than the VPC default one block VPC deletion
+	sgResult, err := client.DescribeSecurityGroups(ctx, &ec2.DescribeSecurityGroupsInput{
+		Filters: []types.Filter{vpcFilter},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to describe security groups: %w", err)
+	}
+	for _, sg := range sgResult.SecurityGroups {
+		if aws.ToString(sg.GroupName) == "default" {
+			continue
+		}
+		if _, err := client.DeleteSecurityGroup(ctx, &ec2.DeleteSecurityGroupInput{GroupId: sg.GroupId}); err != nil {
+			return fmt.Errorf("failed to delete security group %s: %w", aws.ToString(sg.GroupId), err)
+		}
+	}
+
+	subnetResult, err := client.DescribeSubnets(ctx, &ec2.DescribeSubnetsInput{
+		Filters: []types.Filter{vpcFilter},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to describe subnets: %w", err)
+	}
+	for _, subnet := range subnetResult.Subnets {
+		if _, err := client.DeleteSubnet(ctx, &ec2.DeleteSubnetInput{SubnetId: subnet.SubnetId}); err != nil {
+			return fmt.Errorf("failed to delete subnet %s: %w", aws.ToString(subnet.SubnetId), err)
+		}
+	}
+
+	rtResult, err := client.DescribeRouteTables(ctx, &ec2.DescribeRouteTablesInput{
+		Filters: []types.Filter{vpcFilter},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to describe route tables: %w", err)
+	}
+	for _, rt := range rtResult.RouteTables {
+		isMain := false
+		for _, assoc := range rt.Associations {
+			if aws.ToBool(assoc.Main) {
+				isMain = true
+			}
+		}
+		if isMain {
+			continue // deleted together with the VPC
+		}
+		if _, err := client.DeleteRouteTable(ctx, &ec2.DeleteRouteTableInput{RouteTableId: rt.RouteTableId}); err != nil {
+			return fmt.Errorf("failed to delete route table %s: %w", aws.ToString(rt.RouteTableId), err)
+		}
+	}
+
+	// Look gateways up by tag so one left unattached by a failed init is found too
+	igwResult, err := client.DescribeInternetGateways(ctx, &ec2.DescribeInternetGatewaysInput{
+		Filters: []types.Filter{managedNetworkFilter()},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to describe internet gateways: %w", err)
+	}
+	for _, igw := range igwResult.InternetGateways {
+		attached, otherVPC := managedGatewayAttachment(igw, vpcID)
+		if otherVPC {
+			continue
+		}
+		if attached {
+			_, err := client.DetachInternetGateway(ctx, &ec2.DetachInternetGatewayInput{
+				InternetGatewayId: igw.InternetGatewayId,
+				VpcId:             aws.String(vpcID),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to detach internet gateway %s: %w", aws.ToString(igw.InternetGatewayId), err)
+			}
+		}
+		if _, err := client.DeleteInternetGateway(ctx, &ec2.DeleteInternetGatewayInput{InternetGatewayId: igw.InternetGatewayId}); err != nil {
+			return fmt.Errorf("failed to delete internet gateway %s: %w", aws.ToString(igw.InternetGatewayId), err)
+		}
+	}
+
+	if _, err := client.DeleteVpc(ctx, &ec2.DeleteVpcInput{VpcId: aws.String(vpcID)}); err != nil {
+		return fmt.Errorf("failed to delete VPC: %w", err)
+	}
+
+	return nil
+}
+
+// managedGatewayAttachment reports whether a managed internet gateway is attached to
+// vpcID, or to some other VPC and must be left alone
+func managedGatewayAttachment(igw types.InternetGateway, vpcID string) (attached bool, otherVPC bool) {
+	for _, attachment := range igw.Attachments {
+		if aws.ToString(attachment.VpcId) == vpcID {
+			attached = true
+		} else {
+			otherVPC = true
+		}
+	}
+	return attached, otherVPC
+}
@@ -0,0 +1,130 @@
+package aws
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+func TestNthSubnet(t *testing.T) {
+	tests := []struct {
+		name        string
+		cidr        string
+		prefixLen   int
+		index       int
+		expected    string
+		expectError bool
+	}{
+		{"First /20 in /16", "10.100.0.0/16", 20, 0, "10.100.0.0/20", false},
+		{"Second /20 in /16", "10.100.0.0/16", 20, 1, "10.100.16.0/20", false},
+		{"Third /20 in /16", "10.100.0.0/16", 20, 2, "10.100.32.0/20", false},
+		{"Last /20 in /16", "10.100.0.0/16", 20, 15, "10.100.240.0/20", false},
+		{"Unaligned base is normalized", "10.100.5.0/16", 20, 1, "10.100.16.0/20", false},
+		{"Index out of range", "10.100.0.0/16", 20, 16, "", true},
+		{"Prefix larger than VPC", "10.100.0.0/20", 16, 0, "", true},
+		{"IPv6 not supported", "2001:db8::/56", 64, 0, "", true},
+		{"Invalid CIDR", "not-a-cidr", 20, 0, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := nthSubnet(tt.cidr, tt.prefixLen, tt.index)
+
+			if tt.expectError {
+				if err == nil {
+					t.Fatalf("Expected error, got %s", result)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestManagedTags(t *testing.T) {
+	tags := managedTags("clouddley-vpc")
+
+	expected := map[string]string{
+		"Name":               "clouddley-vpc",
+		"CreatedBy":          "Clouddley",
+		managedNetworkTagKey: managedNetworkTagValue,
+	}
+
+	if len(tags) != len(expected) {
+		t.Fatalf("Expected %d tags, got %d", len(expected), len(tags))
+	}
+
+	for _, tag := range tags {
+		if expected[*tag.Key] != *tag.Value {
+			t.Errorf("Unexpected tag %s=%s", *tag.Key, *tag.Value)
+		}
+	}
+}
+
+func TestManagedSubnetCapacity(t *testing.T) {
+	tests := []struct {
+		cidr     string
+		expected int
+	}{
+		{"10.100.0.0/16", 16},
+		{"10.0.0.0/18", 4},
+		{"10.0.0.0/20", 1},
+		{"10.0.0.0/24", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cidr, func(t *testing.T) {
+			_, network, err := net.ParseCIDR(tt.cidr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := managedSubnetCapacity(network); got != tt.expected {
+				t.Errorf("Expected %d subnets, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCreateManagedNetwork_ZonesExceedCIDR(t *testing.T) {
+	// The capacity check must fail before the nil client is used to create anything
+	_, err := createManagedNetwork(context.Background(), nil, "10.0.0.0/20", 2)
+	if err == nil {
+		t.Fatal("Expected an error for two zones in a /20")
+	}
+	if !strings.Contains(err.Error(), "room for 1 /20 subnet(s)") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestManagedGatewayAttachment(t *testing.T) {
+	tests := []struct {
+		name         string
+		igw          types.InternetGateway
+		wantAttached bool
+		wantOther    bool
+	}{
+		{"Unattached", types.InternetGateway{}, false, false},
+		{"Attached to managed VPC", types.InternetGateway{Attachments: []types.InternetGatewayAttachment{{VpcId: aws.String("vpc-1")}}}, true, false},
+		{"Attached elsewhere", types.InternetGateway{Attachments: []types.InternetGatewayAttachment{{VpcId: aws.String("vpc-2")}}}, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attached, other := managedGatewayAttachment(tt.igw, "vpc-1")
+			if attached != tt.wantAttached || other != tt.wantOther {
+				t.Errorf("Expected (%v, %v), got (%v, %v)", tt.wantAttached, tt.wantOther, attached, other)
+			}
+		})
+	}
+}
@@ -0,0 +1,275 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/clouddley/clouddley/internal/log"
+	"github.com/clouddley/clouddley/internal/ui"
+)
+
+// errNoDefaultVPC is returned when the region has neither a default nor a Clouddley-managed VPC
+var errNoDefaultVPC = errors.New("no default VPC found")
+
+// placementOptions holds the network placement flags for create
+type placementOptions struct {
+	VPC    string
+	Subnet string
+	AZ     string
+}
+
+// resolvePlacement picks the VPC and subnet to launch into. Explicit flags win;
+// otherwise the default VPC or the Clouddley-managed VPC is used, and when there is
+// neither the user picks a VPC and subnet interactively. The instance type must be
+// offered in the chosen AZ.
+func resolvePlacement(ctx context.Context, client *ec2.Client, instanceType string, opts placementOptions) (string, string, error) {
+	offered, err := getOfferedZones(ctx, client, instanceType)
+	if err != nil {
+		return "", "", err
+	}
+
+	if opts.Subnet != "" {
+		return resolveExplicitSubnet(ctx, client, instanceType, opts, offered)
+	}
+
+	if opts.VPC != "" {
+		subnetID, err := pickSubnet(ctx, client, opts.VPC, opts.AZ, instanceType, offered)
+		if err != nil {
+			return "", "", err
+		}
+		return opts.VPC, subnetID, nil
+	}
+
+	vpcID, subnetID, err := getDefaultVPCAndSubnet(ctx, client, opts.AZ, offered)
+	if !errors.Is(err, errNoDefaultVPC) {
+		return vpcID, subnetID, err
+	}
+
+	log.Warn("No default or Clouddley-managed VPC found in this region, select a VPC to launch into")
+	vpcID, err = pickVPC(ctx, client)
+	if err != nil {
+		return "", "", err
+	}
+
+	subnetID, err = pickSubnet(ctx, client, vpcID, opts.AZ, instanceType, offered)
+	if err != nil {
+		return "", "", err
+	}
+	return vpcID, subnetID, nil
+}
+
+func resolveExplicitSubnet(ctx context.Context, client *ec2.Client, instanceType string, opts placementOptions, offered map[string]bool) (string, string, error) {
+	result, err := client.DescribeSubnets(ctx, &ec2.DescribeSubnetsInput{
+		SubnetIds: []string{opts.Subnet},
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to describe subnet %s: %w", opts.Subnet, err)
+	}
+	if len(result.Subnets) == 0 {
+		return "", "", fmt.Errorf("subnet %s not found", opts.Subnet)
+	}
+
+	subnet := result.Subnets[0]
+	vpcID := aws.ToString(subnet.VpcId)
+	az := aws.ToString(subnet.AvailabilityZone)
+
+	if opts.VPC != "" && opts.VPC != vpcID {
+		return "", "", fmt.Errorf("subnet %s belongs to %s, not %s", opts.Subnet, vpcID, opts.VPC)
+	}
+	if opts.AZ != "" && opts.AZ != az {
+		return "", "", fmt.Errorf("subnet %s is in %s, not %s", opts.Subnet, az, opts.AZ)
+	}
+	if !offered[az] {
+		return "", "", notOfferedError(instanceType, az, offered)
+	}
+
+	return vpcID, opts.Subnet, nil
+}
+
+// getOfferedZones returns the availability zones where the instance type can be launched
+func getOfferedZones(ctx context.Context, client *ec2.Client, instanceType string) (map[string]bool, error) {
+	offered := make(map[string]bool)
+
+	paginator := ec2.NewDescribeInstanceTypeOfferingsPaginator(client, &ec2.DescribeInstanceTypeOfferingsInput{
+		LocationType: types.LocationTypeAvailabilityZone,
+		Filters: []types.Filter{
+			{
+				Name:   aws.String("instance-type"),
+				Values: []string{instanceType},
+			},
+		},
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe instance type offerings: %w", err)
+		}
+		for _, offering := range page.InstanceTypeOfferings {
+			offered[aws.ToString(offering.Location)] = true
+		}
+	}
+
+	if len(offered) == 0 {
+		return nil, fmt.Errorf("instance type %s is not offered in this region", instanceType)
+	}
+
+	return offered, nil
+}
+
+func notOfferedError(instanceType, az string, offered map[string]bool) error {
+	zones := make([]string, 0, len(offered))
+	for zone := range offered {
+		zones = append(zones, zone)
+	}
+	sort.Strings(zones)
+	return fmt.Errorf("instance type %s is not offered in %s (available in: %s)", instanceType, az, strings.Join(zones, ", "))
+}
+
+// pickVPC lets the user choose a VPC interactively
+func pickVPC(ctx context.Context, client *ec2.Client) (string, error) {
+	result, err := client.DescribeVpcs(ctx, &ec2.DescribeVpcsInput{})
+	if err != nil {
+		return "", fmt.Errorf("failed to describe VPCs: %w", err)
+	}
+
+	var vpcs []types.Vpc
+	for _, vpc := range result.Vpcs {
+		if vpc.State == types.VpcStateAvailable {
+			vpcs = append(vpcs, vpc)
+		}
+	}
+	if len(vpcs) == 0 {
+		return "", fmt.Errorf("no VPCs found in this region, run 'clouddley vm aws network init' or pass --vpc/--subnet")
+	}
+
+	choices := make([]string, len(vpcs))
+	for i, vpc := range vpcs {
+		choices[i] = formatVPCChoice(vpc)
+	}
+
+	choice, err := runListSelection("Select VPC", choices)
+	if err != nil {
+		return "", err
+	}
+	return aws.ToString(vpcs[choice].VpcId), nil
+}
+
+// pickSubnet chooses a subnet in the VPC whose AZ offers the instance type,
+// prompting when more than one qualifies
+func pickSubnet(ctx context.Context, client *ec2.Client, vpcID, az, instanceType string, offered map[string]bool) (string, error) {
+	filters := []types.Filter{
+		{
+			Name:   aws.String("vpc-id"),
+			Values: []string{vpcID},
+		},
+	}
+	if az != "" {
+		filters = append(filters, types.Filter{
+			Name:   aws.String("availability-zone"),
+			Values: []string{az},
+		})
+	}
+
+	result, err := client.DescribeSubnets(ctx, &ec2.DescribeSubnetsInput{Filters: filters})
+	if err != nil {
+		return "", fmt.Errorf("failed to describe subnets: %w", err)
+	}
+
+	candidates := eligibleSubnets(result.Subnets, offered)
+	if len(candidates) == 0 {
+		if az != "" {
+			return "", fmt.Errorf("no subnet in %s with free IPs offers %s in %s", vpcID, instanceType, az)
+		}
+		return "", fmt.Errorf("no subnet in %s with free IPs offers %s", vpcID, instanceType)
+	}
+
+	if len(candidates) == 1 {
+		return aws.ToString(candidates[0].SubnetId), nil
+	}
+
+	choices := make([]string, len(candidates))
+	for i, subnet := range candidates {
+		choices[i] = formatSubnetChoice(subnet)
+	}
+
+	choice, err := runListSelection("Select Subnet", choices)
+	if err != nil {
+		return "", err
+	}
+	return aws.ToString(candidates[choice].SubnetId), nil
+}
+
+// eligibleSubnets keeps subnets that have free IPs in an AZ offering the instance type, sorted by AZ
+func eligibleSubnets(subnets []types.Subnet, offered map[string]bool) []types.Subnet {
+	var eligible []types.Subnet
+	for _, subnet := range subnets {
+		if !offered[aws.ToString(subnet.AvailabilityZone)] {
+			continue
+		}
+		if aws.ToInt32(subnet.AvailableIpAddressCount) == 0 {
+			continue
+		}
+		eligible = append(eligible, subnet)
+	}
+
+	sort.SliceStable(eligible, func(i, j int) bool {
+		return aws.ToString(eligible[i].AvailabilityZone) < aws.ToString(eligible[j].AvailabilityZone)
+	})
+	return eligible
+}
+
+func formatVPCChoice(vpc types.Vpc) string {
+	choice := fmt.Sprintf("%s  %s", aws.ToString(vpc.VpcId), aws.ToString(vpc.CidrBlock))
+	if name := tagValue(vpc.Tags, "Name"); name != "" {
+		choice += fmt.Sprintf("  %s", name)
+	}
+	if aws.ToBool(vpc.IsDefault) {
+		choice += "  (default)"
+	}
+	return choice
+}
+
+func formatSubnetChoice(subnet types.Subnet) string {
+	choice := fmt.Sprintf("%s  %s  %s  %d free IPs",
+		aws.ToString(subnet.SubnetId),
+		aws.ToString(subnet.AvailabilityZone),
+		aws.ToString(subnet.CidrBlock),
+		aws.ToInt32(subnet.AvailableIpAddressCount))
+	if name := tagValue(subnet.Tags, "Name"); name != "" {
+		choice += fmt.Sprintf("  %s", name)
+	}
+	if aws.ToBool(subnet.MapPublicIpOnLaunch) {
+		choice += "  (public)"
+	}
+	return choice
+}
+
+func tagValue(tags []types.Tag, key string) string {
+	for _, tag := range tags {
+		if aws.ToString(tag.Key) == key {
+			return aws.ToString(tag.Value)
+		}
+	}
+	return ""
+}
+
+func runListSelection(title string, choices []string) (int, error) {
+	p := tea.NewProgram(ui.NewListSelectionModel(title, choices))
+	m, err := p.Run()
+	if err != nil {
+		return -1, fmt.Errorf("error running selection: %w", err)
+	}
+
+	choice := m.(ui.ListSelectionModel).Selected()
+	if choice == -1 {
+		return -1, fmt.Errorf("operation cancelled")
+	}
+	return choice, nil
+}
@@ -0,0 +1,79 @@
+package aws
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+func TestEligibleSubnets(t *testing.T) {
+	subnets := []types.Subnet{
+		{SubnetId: aws.String("subnet-c"), AvailabilityZone: aws.String("us-east-1c"), AvailableIpAddressCount: aws.Int32(10)},
+		{SubnetId: aws.String("subnet-a"), AvailabilityZone: aws.String("us-east-1a"), AvailableIpAddressCount: aws.Int32(250)},
+		{SubnetId: aws.String("subnet-full"), AvailabilityZone: aws.String("us-east-1a"), AvailableIpAddressCount: aws.Int32(0)},
+		{SubnetId: aws.String("subnet-e"), AvailabilityZone: aws.String("us-east-1e"), AvailableIpAddressCount: aws.Int32(100)},
+	}
+	offered := map[string]bool{"us-east-1a": true, "us-east-1c": true}
+
+	eligible := eligibleSubnets(subnets, offered)
+
+	if len(eligible) != 2 {
+		t.Fatalf("Expected 2 eligible subnets, got %d", len(eligible))
+	}
+
+	if *eligible[0].SubnetId != "subnet-a" {
+		t.Errorf("Expected first subnet subnet-a, got %s", *eligible[0].SubnetId)
+	}
+
+	if *eligible[1].SubnetId != "subnet-c" {
+		t.Errorf("Expected second subnet subnet-c, got %s", *eligible[1].SubnetId)
+	}
+}
+
+func TestFormatSubnetChoice(t *testing.T) {
+	subnet := types.Subnet{
+		SubnetId:                aws.String("subnet-123"),
+		AvailabilityZone:        aws.String("eu-west-1b"),
+		CidrBlock:               aws.String("10.0.1.0/24"),
+		AvailableIpAddressCount: aws.Int32(249),
+		MapPublicIpOnLaunch:     aws.Bool(true),
+		Tags: []types.Tag{
+			{Key: aws.String("Name"), Value: aws.String("public-b")},
+		},
+	}
+
+	choice := formatSubnetChoice(subnet)
+
+	for _, expected := range []string{"subnet-123", "eu-west-1b", "10.0.1.0/24", "249 free IPs", "public-b", "(public)"} {
+		if !strings.Contains(choice, expected) {
+			t.Errorf("Expected %q in subnet choice, got %q", expected, choice)
+		}
+	}
+}
+
+func TestFormatVPCChoice(t *testing.T) {
+	vpc := types.Vpc{
+		VpcId:     aws.String("vpc-123"),
+		CidrBlock: aws.String("172.31.0.0/16"),
+		IsDefault: aws.Bool(true),
+	}
+
+	choice := formatVPCChoice(vpc)
+
+	if choice != "vpc-123  172.31.0.0/16  (default)" {
+		t.Errorf("Unexpected VPC choice: %q", choice)
+	}
+}
+
+func TestNotOfferedError(t *testing.T) {
+	offered := map[string]bool{"us-east-1b": true, "us-east-1a": true}
+
+	err := notOfferedError("m5.large", "us-east-1e", offered)
+
+	expected := "instance type m5.large is not offered in us-east-1e (available in: us-east-1a, us-east-1b)"
+	if err.Error() != expected {
+		t.Errorf("Expected %q, got %q", expected, err.Error())
+	}
+}
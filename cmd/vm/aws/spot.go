@@ -0,0 +1,50 @@
+package aws
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// spotOptions holds the spot launch settings gathered from flags
+type spotOptions struct {
+	MaxPrice     string // USD per hour, empty to cap at the on-demand price
+	Interruption string // stop, hibernate or terminate
+}
+
+// buildSpotMarketOptions converts the spot flags into RunInstances market options.
+// Stop and hibernate require a persistent request; terminate uses a one-time request.
+func buildSpotMarketOptions(opts spotOptions) (*types.InstanceMarketOptionsRequest, error) {
+	behavior := types.InstanceInterruptionBehavior(opts.Interruption)
+	if opts.Interruption == "" {
+		behavior = types.InstanceInterruptionBehaviorTerminate
+	}
+
+	spot := &types.SpotMarketOptions{
+		InstanceInterruptionBehavior: behavior,
+	}
+
+	switch behavior {
+	case types.InstanceInterruptionBehaviorTerminate:
+		spot.SpotInstanceType = types.SpotInstanceTypeOneTime
+	case types.InstanceInterruptionBehaviorStop, types.InstanceInterruptionBehaviorHibernate:
+		spot.SpotInstanceType = types.SpotInstanceTypePersistent
+	default:
+		return nil, fmt.Errorf("invalid spot interruption behavior %q: must be stop, hibernate or terminate", opts.Interruption)
+	}
+
+	if opts.MaxPrice != "" {
+		price, err := strconv.ParseFloat(opts.MaxPrice, 64)
+		if err != nil || price <= 0 {
+			return nil, fmt.Errorf("invalid spot max price %q: must be a positive USD hourly amount", opts.MaxPrice)
+		}
+		spot.MaxPrice = aws.String(opts.MaxPrice)
+	}
+
+	return &types.InstanceMarketOptionsRequest{
+		MarketType:  types.MarketTypeSpot,
+		SpotOptions: spot,
+	}, nil
+}
@@ -0,0 +1,89 @@
+package aws
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+func TestBuildSpotMarketOptions(t *testing.T) {
+	tests := []struct {
+		name             string
+		opts             spotOptions
+		expectedType     types.SpotInstanceType
+		expectedBehavior types.InstanceInterruptionBehavior
+		expectedMaxPrice string
+		expectError      string
+	}{
+		{
+			name:             "Default terminate",
+			opts:             spotOptions{},
+			expectedType:     types.SpotInstanceTypeOneTime,
+			expectedBehavior: types.InstanceInterruptionBehaviorTerminate,
+		},
+		{
+			name:             "Stop with max price",
+			opts:             spotOptions{MaxPrice: "0.05", Interruption: "stop"},
+			expectedType:     types.SpotInstanceTypePersistent,
+			expectedBehavior: types.InstanceInterruptionBehaviorStop,
+			expectedMaxPrice: "0.05",
+		},
+		{
+			name:             "Hibernate",
+			opts:             spotOptions{Interruption: "hibernate"},
+			expectedType:     types.SpotInstanceTypePersistent,
+			expectedBehavior: types.InstanceInterruptionBehaviorHibernate,
+		},
+		{
+			name:        "Invalid behavior",
+			opts:        spotOptions{Interruption: "pause"},
+			expectError: "invalid spot interruption behavior",
+		},
+		{
+			name:        "Invalid max price",
+			opts:        spotOptions{MaxPrice: "-1"},
+			expectError: "invalid spot max price",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			market, err := buildSpotMarketOptions(tt.opts)
+
+			if tt.expectError != "" {
+				if err == nil {
+					t.Fatalf("Expected error containing %q, got nil", tt.expectError)
+				}
+				if !strings.Contains(err.Error(), tt.expectError) {
+					t.Errorf("Expected error containing %q, got: %v", tt.expectError, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if market.MarketType != types.MarketTypeSpot {
+				t.Errorf("Expected market type spot, got %s", market.MarketType)
+			}
+
+			if market.SpotOptions.SpotInstanceType != tt.expectedType {
+				t.Errorf("Expected spot instance type %s, got %s", tt.expectedType, market.SpotOptions.SpotInstanceType)
+			}
+
+			if market.SpotOptions.InstanceInterruptionBehavior != tt.expectedBehavior {
+				t.Errorf("Expected interruption behavior %s, got %s", tt.expectedBehavior, market.SpotOptions.InstanceInterruptionBehavior)
+			}
+
+			maxPrice := ""
+			if market.SpotOptions.MaxPrice != nil {
+				maxPrice = *market.SpotOptions.MaxPrice
+			}
+			if maxPrice != tt.expectedMaxPrice {
+				t.Errorf("Expected max price %q, got %q", tt.expectedMaxPrice, maxPrice)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/clouddley/clouddley/internal/log"
+	"github.com/clouddley/clouddley/internal/ui"
+)
+
+// triggrReadyTimeout bounds how long we wait for cloud-init to install Docker and initialize the swarm
+const triggrReadyTimeout = 10 * time.Minute
+
+// triggrCheckCommand waits for cloud-init to finish and prints the Docker server
+// version and swarm state, e.g. "27.3.1 active"
+const triggrCheckCommand = `cloud-init status --wait >/dev/null 2>&1; sudo docker info --format '{{.ServerVersion}} {{.Swarm.LocalNodeState}}'`
+
+// waitForTriggrReady polls the instance over SSH until Docker is running and the
+// single-node swarm is active, showing a spinner while it waits
+func waitForTriggrReady(ctx context.Context, publicIP string) (string, error) {
+	fmt.Println()
+	loadingModel := ui.NewLoadingModel("Waiting for Docker and swarm to be ready...")
+
+	type result struct {
+		version string
+		err     error
+	}
+	done := make(chan result, 1)
+
+	go func() {
+		version, err := pollTriggrReady(ctx, publicIP, triggrReadyTimeout)
+		done <- result{version: version, err: err}
+	}()
+
+	// Start the loading spinner
+	p := tea.NewProgram(loadingModel)
+	go func() {
+		p.Run()
+	}()
+
+	res := <-done
+	loadingModel.Finish()
+	p.Quit()
+
+	fmt.Print("\r\033[K") // Clear the spinner line
+
+	return res.version, res.err
+}
+
+func pollTriggrReady(ctx context.Context, publicIP string, timeout time.Duration) (string, error) {
+	deadline := time.Now().Add(timeout)
+	var lastErr error
+
+	for time.Now().Before(deadline) {
+		cmd := exec.CommandContext(ctx, "ssh",
+			"-o", "StrictHostKeyChecking=no",
+			"-o", "UserKnownHostsFile=/dev/null",
+			"-o", "ConnectTimeout=30",
+			"-o", "ServerAliveInterval=5",
+			"-o", "ServerAliveCountMax=3",
+			fmt.Sprintf("ubuntu@%s", publicIP),
+			triggrCheckCommand)
+
+		output, err := cmd.CombinedOutput()
+		if err == nil {
+			version, err := parseTriggrStatus(string(output))
+			if err == nil {
+				return version, nil
+			}
+			lastErr = err
+		} else {
+			lastErr = fmt.Errorf("SSH command failed: %w, output: %s", err, strings.TrimSpace(string(output)))
+		}
+
+		log.Debug("Triggr host not ready yet", "host", publicIP, "error", lastErr)
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(15 * time.Second):
+		}
+	}
+
+	return "", fmt.Errorf("timed out waiting for Docker swarm: %w", lastErr)
+}
+
+// parseTriggrStatus extracts the Docker version from the check command output and
+// verifies the swarm is active
+func parseTriggrStatus(output string) (string, error) {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	fields := strings.Fields(lines[len(lines)-1])
+
+	if len(fields) != 2 {
+		return "", fmt.Errorf("unexpected docker info output: %q", strings.TrimSpace(output))
+	}
+
+	if fields[1] != "active" {
+		return "", fmt.Errorf("docker swarm is %s, expected active", fields[1])
+	}
+
+	return fields[0], nil
+}
@@ -0,0 +1,65 @@
+package aws
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseTriggrStatus(t *testing.T) {
+	tests := []struct {
+		name            string
+		output          string
+		expectedVersion string
+		expectError     string
+	}{
+		{
+			name:            "Active swarm",
+			output:          "27.3.1 active\n",
+			expectedVersion: "27.3.1",
+		},
+		{
+			name:            "Active swarm after SSH warning",
+			output:          "Warning: Permanently added '52.1.2.3' (ED25519) to the list of known hosts.\n27.3.1 active\n",
+			expectedVersion: "27.3.1",
+		},
+		{
+			name:        "Inactive swarm",
+			output:      "27.3.1 inactive\n",
+			expectError: "docker swarm is inactive",
+		},
+		{
+			name:        "Docker not installed",
+			output:      "sudo: docker: command not found\n",
+			expectError: "unexpected docker info output",
+		},
+		{
+			name:        "Empty output",
+			output:      "",
+			expectError: "unexpected docker info output",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, err := parseTriggrStatus(tt.output)
+
+			if tt.expectError != "" {
+				if err == nil {
+					t.Fatalf("Expected error containing %q, got nil", tt.expectError)
+				}
+				if !strings.Contains(err.Error(), tt.expectError) {
+					t.Errorf("Expected error containing %q, got: %v", tt.expectError, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if version != tt.expectedVersion {
+				t.Errorf("Expected version %s, got %s", tt.expectedVersion, version)
+			}
+		})
+	}
+}
@@ -0,0 +1,337 @@
+// Package authorizedkeys reads and safely updates OpenSSH authorized_keys files.
+package authorizedkeys
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	// DirPerm is the permission sshd expects on ~/.ssh
+	DirPerm os.FileMode = 0700
+	// FilePerm is the permission used for authorized_keys
+	FilePerm os.FileMode = 0600
+	// BackupSuffix is appended to authorized_keys for the copy kept before each change
+	BackupSuffix = ".clouddley.bak"
+)
+
+// Entry is one line of an authorized_keys file
+type Entry struct {
+	Line string        // original line, without the trailing newline
+	Key  ssh.PublicKey // nil for blank lines, comments and lines that fail to parse
+}
+
+// Fingerprint returns the SHA256 fingerprint of the entry's key, or "" if it has none
+func (e Entry) Fingerprint() string {
+	if e.Key == nil {
+		return ""
+	}
+	return ssh.FingerprintSHA256(e.Key)
+}
+
+// Result describes what a change to authorized_keys did
+type Result struct {
+	Path             string   // authorized_keys file that was checked
+	Changed          bool     // the file content was rewritten
+	Backup           string   // copy of the previous content, empty if none was made
+	Removed          int      // number of entries removed
+	FixedPermissions []string // permission fixes applied, e.g. "~/.ssh 0755 -> 0700"
+}
+
+// Parse splits authorized_keys content into entries, keeping every line as-is
+func Parse(data []byte) []Entry {
+	text := strings.TrimSuffix(string(data), "\n")
+	if text == "" {
+		return nil
+	}
+
+	lines := strings.Split(text, "\n")
+	entries := make([]Entry, 0, len(lines))
+	for _, line := range lines {
+		line = strings.TrimSuffix(line, "\r")
+		entry := Entry{Line: line}
+
+		trimmed := strings.TrimSpace(line)
+		if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			// ParseAuthorizedKey also accepts lines with leading options
+			if key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(trimmed)); err == nil {
+				entry.Key = key
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// Format joins entries back into file content
+func Format(entries []Entry) []byte {
+	var buf bytes.Buffer
+	for _, entry := range entries {
+		buf.WriteString(entry.Line)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// HasKey reports whether any entry has the given SHA256 fingerprint
+func HasKey(entries []Entry, fingerprint string) bool {
+	for _, entry := range entries {
+		if entry.Fingerprint() == fingerprint {
+			return true
+		}
+	}
+	return false
+}
+
+// Add appends each public key to the authorized_keys file at path unless a key with
+// the same fingerprint is already present, creating the file and its directory as needed
+func Add(path string, publicKeys ...string) (*Result, error) {
+	var added []Entry
+	for _, publicKey := range publicKeys {
+		key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(publicKey))
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key: %w", err)
+		}
+		added = append(added, Entry{Line: strings.TrimSpace(publicKey), Key: key})
+	}
+
+	result := &Result{Path: path}
+
+	dir := filepath.Dir(path)
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		if err := os.MkdirAll(dir, DirPerm); err != nil {
+			return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+		}
+		// When run as root for another user, hand the new directory to the home owner
+		if err := matchOwner(dir, filepath.Dir(dir)); err != nil {
+			return nil, err
+		}
+	}
+
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	entries := Parse(existing)
+	changed := false
+	for _, entry := range added {
+		if !HasKey(entries, entry.Fingerprint()) {
+			entries = append(entries, entry)
+			changed = true
+		}
+	}
+	if changed {
+		if err := replace(path, existing, Format(entries), result); err != nil {
+			return nil, err
+		}
+	}
+
+	fixed, err := EnsurePermissions(path)
+	if err != nil {
+		return nil, err
+	}
+	result.FixedPermissions = fixed
+
+	return result, nil
+}
+
+// Remove deletes every entry whose key has one of the given SHA256 fingerprints,
+// keeping comments and all other lines untouched
+func Remove(path string, fingerprints ...string) (*Result, error) {
+	result := &Result{Path: path}
+
+	existing, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return result, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	remove := make(map[string]bool, len(fingerprints))
+	for _, fp := range fingerprints {
+		remove[fp] = true
+	}
+
+	var kept []Entry
+	for _, entry := range Parse(existing) {
+		if entry.Key != nil && remove[entry.Fingerprint()] {
+			result.Removed++
+			continue
+		}
+		kept = append(kept, entry)
+	}
+
+	if result.Removed == 0 {
+		return result, nil
+	}
+
+	if err := replace(path, existing, Format(kept), result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Status describes an authorized_keys file and which keys are installed in it
+type Status struct {
+	Path      string
+	Exists    bool
+	Installed []string // requested fingerprints present in the file
+	Problems  []string // permission issues that may stop sshd from using the file
+}
+
+// Inspect reports which of the given fingerprints are in the file at path and lists
+// permission problems with the file and its directory
+func Inspect(path string, fingerprints ...string) (*Status, error) {
+	status := &Status{Path: path}
+
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err == nil {
+		status.Exists = true
+		entries := Parse(content)
+		for _, fingerprint := range fingerprints {
+			if HasKey(entries, fingerprint) {
+				status.Installed = append(status.Installed, fingerprint)
+			}
+		}
+	}
+
+	problems, err := PermissionProblems(path)
+	if err != nil {
+		return nil, err
+	}
+	status.Problems = problems
+
+	return status, nil
+}
+
+// PermissionProblems lists modes on authorized_keys and its directory that sshd's
+// StrictModes rejects (group or world writable) or that are looser than recommended
+func PermissionProblems(path string) ([]string, error) {
+	var problems []string
+	for _, target := range []struct {
+		path string
+		perm os.FileMode
+	}{
+		{filepath.Dir(path), DirPerm},
+		{path, FilePerm},
+	} {
+		info, err := os.Stat(target.path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat %s: %w", target.path, err)
+		}
+
+		mode := info.Mode().Perm()
+		switch {
+		case mode&0022 != 0:
+			problems = append(problems, fmt.Sprintf("%s is %04o: group or world writable, sshd will refuse the key (expected %04o)", target.path, mode, target.perm))
+		case mode&^target.perm != 0:
+			problems = append(problems, fmt.Sprintf("%s is %04o: more permissive than the recommended %04o", target.path, mode, target.perm))
+		}
+	}
+	return problems, nil
+}
+
+// EnsurePermissions tightens the authorized_keys file and its directory to the modes
+// sshd's StrictModes accepts and returns a description of each change made
+func EnsurePermissions(path string) ([]string, error) {
+	var fixed []string
+	for _, target := range []struct {
+		path string
+		perm os.FileMode
+	}{
+		{filepath.Dir(path), DirPerm},
+		{path, FilePerm},
+	} {
+		info, err := os.Stat(target.path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return fixed, fmt.Errorf("failed to stat %s: %w", target.path, err)
+		}
+
+		current := info.Mode().Perm()
+		if current == target.perm {
+			continue
+		}
+		if err := os.Chmod(target.path, target.perm); err != nil {
+			return fixed, fmt.Errorf("failed to set permissions on %s: %w", target.path, err)
+		}
+		fixed = append(fixed, fmt.Sprintf("%s %04o -> %04o", target.path, current, target.perm))
+	}
+	return fixed, nil
+}
+
+// replace backs up the previous content, if any, and atomically writes the new content
+func replace(path string, previous []byte, content []byte, result *Result) error {
+	if previous != nil {
+		backup := path + BackupSuffix
+		if err := os.WriteFile(backup, previous, FilePerm); err != nil {
+			return fmt.Errorf("failed to back up %s: %w", path, err)
+		}
+		if err := matchOwner(backup, path); err != nil {
+			return err
+		}
+		result.Backup = backup
+	}
+
+	if err := writeAtomic(path, content); err != nil {
+		return err
+	}
+	result.Changed = true
+	return nil
+}
+
+// writeAtomic writes content to a temporary file in the same directory and renames
+// it over path, so sshd never sees a partially written file
+func writeAtomic(path string, content []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".authorized_keys-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", tmpPath, err)
+	}
+	// Keep the owner of the file being replaced, or of its directory for a new file
+	owner := path
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		owner = filepath.Dir(path)
+	}
+	if err := matchOwner(tmpPath, owner); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(FilePerm); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to set permissions on %s: %w", tmpPath, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync %s: %w", tmpPath, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", tmpPath, err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,283 @@
+package authorizedkeys
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	testKey      = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAICmyl5Coa4PmyE2iaauW/gTEXsGHXdExi8Ij0Ak89xnf clouddley"
+	otherKey     = "ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAAAgQC0zwgvQdDk33sUVxVJjQqfc0i8s2ur3pXz1ShYjGdJQ80SGi3QIUN9Adbrcpd92b0bnWY+IGrFkSCSyIPSGNSiFkZEVb7brr/mMg04dKb9K3cMw853XExafBPZMyOdQx7rWWPC9VlwzDJhe0DQLhWcg4ho/4XeU9oXHsoDJEqKoQ== alice"
+	testKeyPrint = "SHA256:x7M3xfUFb4DumQfhHqibPW0MaBQZ3Rztzjfn63tSQyw"
+)
+
+func TestParse(t *testing.T) {
+	content := "# managed by ops\n" + otherKey + "\n\n" +
+		`command="echo hi",no-pty ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAICmyl5Coa4PmyE2iaauW/gTEXsGHXdExi8Ij0Ak89xnf renamed` + "\n" +
+		"garbage line\n"
+
+	entries := Parse([]byte(content))
+
+	if len(entries) != 5 {
+		t.Fatalf("Expected 5 entries, got %d", len(entries))
+	}
+
+	withKeys := 0
+	for _, entry := range entries {
+		if entry.Key != nil {
+			withKeys++
+		}
+	}
+	if withKeys != 2 {
+		t.Errorf("Expected 2 entries with keys, got %d", withKeys)
+	}
+
+	// Detection is by fingerprint, so options and a different comment don't matter
+	if !HasKey(entries, testKeyPrint) {
+		t.Error("Expected key with options to be detected by fingerprint")
+	}
+
+	if string(Format(entries)) != content {
+		t.Errorf("Expected round trip to preserve content, got:\n%s", Format(entries))
+	}
+}
+
+func TestAdd(t *testing.T) {
+	t.Run("creates directory and file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), ".ssh", "authorized_keys")
+
+		result, err := Add(path, testKey)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		if !result.Changed || result.Backup != "" {
+			t.Errorf("Expected a change without backup, got %+v", result)
+		}
+		assertContent(t, path, testKey+"\n")
+		assertMode(t, filepath.Dir(path), DirPerm)
+		assertMode(t, path, FilePerm)
+	})
+
+	t.Run("is idempotent", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "authorized_keys")
+		writeFile(t, path, otherKey+"\n", FilePerm)
+
+		if _, err := Add(path, testKey); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		result, err := Add(path, testKey)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		if result.Changed {
+			t.Error("Expected second run to change nothing")
+		}
+		assertContent(t, path, otherKey+"\n"+testKey+"\n")
+	})
+
+	t.Run("backs up and handles missing trailing newline", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "authorized_keys")
+		writeFile(t, path, otherKey, FilePerm)
+
+		result, err := Add(path, testKey)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		if result.Backup != path+BackupSuffix {
+			t.Errorf("Expected backup at %s, got %q", path+BackupSuffix, result.Backup)
+		}
+		assertContent(t, result.Backup, otherKey)
+		assertContent(t, path, otherKey+"\n"+testKey+"\n")
+	})
+
+	t.Run("fixes permissions", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), ".ssh")
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		os.Chmod(dir, 0755)
+		path := filepath.Join(dir, "authorized_keys")
+		writeFile(t, path, testKey+"\n", 0644)
+
+		result, err := Add(path, testKey)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		if result.Changed {
+			t.Error("Expected content to be unchanged")
+		}
+		if len(result.FixedPermissions) != 2 {
+			t.Errorf("Expected 2 permission fixes, got %v", result.FixedPermissions)
+		}
+		assertMode(t, dir, DirPerm)
+		assertMode(t, path, FilePerm)
+	})
+
+	t.Run("rejects invalid key", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "authorized_keys")
+		if _, err := Add(path, "not a key"); err == nil {
+			t.Fatal("Expected error for invalid key")
+		}
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Error("Expected no file to be written")
+		}
+	})
+}
+
+func writeFile(t *testing.T, path, content string, perm os.FileMode) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), perm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, perm); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func assertContent(t *testing.T, path, expected string) {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != expected {
+		t.Errorf("Unexpected content in %s:\n%s\nexpected:\n%s", path, content, expected)
+	}
+}
+
+func assertMode(t *testing.T, path string, expected os.FileMode) {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != expected {
+		t.Errorf("Expected %s to have mode %o, got %o", path, expected, info.Mode().Perm())
+	}
+}
+
+func TestRemove(t *testing.T) {
+	t.Run("removes every matching entry and keeps the rest", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "authorized_keys")
+		writeFile(t, path, "# ops\n"+testKey+"\n"+otherKey+"\n"+`no-pty `+testKey+"\n", FilePerm)
+
+		result, err := Remove(path, testKeyPrint)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		if !result.Changed || result.Removed != 2 {
+			t.Errorf("Expected 2 entries removed, got %+v", result)
+		}
+		assertContent(t, path, "# ops\n"+otherKey+"\n")
+		assertContent(t, path+BackupSuffix, "# ops\n"+testKey+"\n"+otherKey+"\n"+`no-pty `+testKey+"\n")
+	})
+
+	t.Run("key not installed", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "authorized_keys")
+		writeFile(t, path, otherKey+"\n", FilePerm)
+
+		result, err := Remove(path, testKeyPrint)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if result.Changed || result.Backup != "" {
+			t.Errorf("Expected no change, got %+v", result)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		result, err := Remove(filepath.Join(t.TempDir(), "authorized_keys"), testKeyPrint)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if result.Changed {
+			t.Error("Expected no change")
+		}
+	})
+}
+
+func TestInspect(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), ".ssh")
+	if err := os.Mkdir(dir, DirPerm); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "authorized_keys")
+
+	status, err := Inspect(path, testKeyPrint)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if status.Exists || len(status.Installed) != 0 {
+		t.Errorf("Expected missing file, got %+v", status)
+	}
+
+	writeFile(t, path, testKey+"\n", 0664)
+
+	status, err = Inspect(path, testKeyPrint, "SHA256:unknown")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !status.Exists || len(status.Installed) != 1 {
+		t.Errorf("Expected key installed, got %+v", status)
+	}
+	if len(status.Problems) != 1 || !strings.Contains(status.Problems[0], "group or world writable") {
+		t.Errorf("Expected a writable file problem, got %v", status.Problems)
+	}
+}
+
+func TestPermissionProblems(t *testing.T) {
+	tests := []struct {
+		name     string
+		dirPerm  os.FileMode
+		filePerm os.FileMode
+		expected int
+	}{
+		{"strict", 0700, 0600, 0},
+		{"readable", 0755, 0644, 2},
+		{"writable directory", 0775, 0600, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), ".ssh")
+			if err := os.Mkdir(dir, 0700); err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(dir, "authorized_keys")
+			writeFile(t, path, testKey+"\n", tt.filePerm)
+			if err := os.Chmod(dir, tt.dirPerm); err != nil {
+				t.Fatal(err)
+			}
+
+			problems, err := PermissionProblems(path)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if len(problems) != tt.expected {
+				t.Errorf("Expected %d problems, got %v", tt.expected, problems)
+			}
+		})
+	}
+}
+
+func TestAdd_MultipleKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "authorized_keys")
+	writeFile(t, path, testKey+"\n", FilePerm)
+
+	result, err := Add(path, testKey, otherKey)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if !result.Changed {
+		t.Error("Expected the missing key to be added")
+	}
+	assertContent(t, path, testKey+"\n"+otherKey+"\n")
+}
@@ -0,0 +1,28 @@
+//go:build !windows
+
+package authorizedkeys
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// matchOwner gives path the same owner and group as reference. Only root can change
+// ownership, so failures are ignored for other users, who only touch their own files.
+func matchOwner(path string, reference string) error {
+	info, err := os.Stat(reference)
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", reference, err)
+	}
+
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+
+	if err := os.Chown(path, int(stat.Uid), int(stat.Gid)); err != nil && os.Geteuid() == 0 {
+		return fmt.Errorf("failed to set owner of %s: %w", path, err)
+	}
+	return nil
+}
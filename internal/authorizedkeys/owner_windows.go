@@ -0,0 +1,8 @@
+//go:build windows
+
+package authorizedkeys
+
+// matchOwner is a no-op on Windows, where files inherit ACLs from their directory
+func matchOwner(path string, reference string) error {
+	return nil
+}
@@ -0,0 +1,43 @@
+package authorizedkeys
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// RemoteAddScript returns a POSIX shell script that idempotently adds the public keys
+// to the remote user's ~/.ssh/authorized_keys. Keys are matched on their base64 body,
+// so an existing entry with options or a different comment is not duplicated. The
+// script prints "KEY_ADDED" or "KEY_PRESENT" for each key.
+func RemoteAddScript(publicKeys ...string) (string, error) {
+	var b strings.Builder
+	b.WriteString("set -e\n")
+	b.WriteString("umask 077\n")
+	b.WriteString("mkdir -p ~/.ssh\n")
+	b.WriteString("chmod 700 ~/.ssh\n")
+	b.WriteString("touch ~/.ssh/authorized_keys\n")
+	b.WriteString("chmod 600 ~/.ssh/authorized_keys\n")
+
+	for _, publicKey := range publicKeys {
+		key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(publicKey))
+		if err != nil {
+			return "", fmt.Errorf("invalid public key: %w", err)
+		}
+
+		// The base64 body and a re-marshalled line never contain single quotes
+		body := strings.Fields(string(ssh.MarshalAuthorizedKey(key)))[1]
+		line := strings.ReplaceAll(strings.TrimSpace(publicKey), "'", "")
+
+		fmt.Fprintf(&b, "if grep -qF '%s' ~/.ssh/authorized_keys; then\n", body)
+		b.WriteString("  echo KEY_PRESENT\n")
+		b.WriteString("else\n")
+		b.WriteString("  [ -s ~/.ssh/authorized_keys ] && [ -n \"$(tail -c1 ~/.ssh/authorized_keys)\" ] && echo >> ~/.ssh/authorized_keys\n")
+		fmt.Fprintf(&b, "  echo '%s' >> ~/.ssh/authorized_keys\n", line)
+		b.WriteString("  echo KEY_ADDED\n")
+		b.WriteString("fi\n")
+	}
+
+	return b.String(), nil
+}
@@ -0,0 +1,55 @@
+package authorizedkeys
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRemoteAddScript(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	script, err := RemoteAddScript(testKey, otherKey)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	home := t.TempDir()
+	path := filepath.Join(home, ".ssh", "authorized_keys")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	// Existing entry with options and no trailing newline
+	writeFile(t, path, `no-pty `+testKey, 0644)
+
+	run := func() string {
+		cmd := exec.Command("sh", "-c", script)
+		cmd.Env = append(os.Environ(), "HOME="+home)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("Script failed: %v\n%s", err, output)
+		}
+		return string(output)
+	}
+
+	if output := run(); output != "KEY_PRESENT\nKEY_ADDED\n" {
+		t.Errorf("Unexpected first run output: %q", output)
+	}
+	if output := run(); output != "KEY_PRESENT\nKEY_PRESENT\n" {
+		t.Errorf("Unexpected second run output: %q", output)
+	}
+
+	assertContent(t, path, `no-pty `+testKey+"\n"+otherKey+"\n")
+	assertMode(t, filepath.Dir(path), DirPerm)
+	assertMode(t, path, FilePerm)
+}
+
+func TestRemoteAddScript_InvalidKey(t *testing.T) {
+	if _, err := RemoteAddScript("not a key"); err == nil || !strings.Contains(err.Error(), "invalid public key") {
+		t.Errorf("Expected invalid key error, got: %v", err)
+	}
+}
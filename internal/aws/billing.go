@@ -0,0 +1,77 @@
+package aws
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+)
+
+const (
+	// HoursPerMonthEnv sets how many hours a month instances are priced for, e.g.
+	// CLOUDDLEY_HOURS_PER_MONTH=220 for dev boxes stopped outside business hours
+	HoursPerMonthEnv = "CLOUDDLEY_HOURS_PER_MONTH"
+
+	// maxHoursPerMonth is the length of the longest month
+	maxHoursPerMonth = 31 * 24
+)
+
+var (
+	billingMu               sync.Mutex
+	configuredHoursPerMonth float64
+)
+
+// ParseHoursPerMonth parses and validates an hours-per-month value
+func ParseHoursPerMonth(value string) (float64, error) {
+	hours, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid hours per month %q: use a number such as 220", value)
+	}
+	return hours, validateHoursPerMonth(hours)
+}
+
+func validateHoursPerMonth(hours float64) error {
+	if hours <= 0 || hours > maxHoursPerMonth {
+		return fmt.Errorf("hours per month must be more than 0 and at most %d, got %g", maxHoursPerMonth, hours)
+	}
+	return nil
+}
+
+// ConfigureHoursPerMonth sets the hours a month instances are priced for. Zero uses
+// CLOUDDLEY_HOURS_PER_MONTH, or HoursPerMonth when it is not set
+func ConfigureHoursPerMonth(hours float64) error {
+	if hours == 0 {
+		value := os.Getenv(HoursPerMonthEnv)
+		if value == "" {
+			hours = HoursPerMonth
+		} else {
+			var err error
+			if hours, err = ParseHoursPerMonth(value); err != nil {
+				return fmt.Errorf("invalid %s: %w", HoursPerMonthEnv, err)
+			}
+		}
+	}
+	if err := validateHoursPerMonth(hours); err != nil {
+		return err
+	}
+
+	billingMu.Lock()
+	defer billingMu.Unlock()
+	configuredHoursPerMonth = hours
+	return nil
+}
+
+// BillingHoursPerMonth returns the hours a month instances are priced for. Storage
+// is billed for the whole month and always uses HoursPerMonth
+func BillingHoursPerMonth() float64 {
+	billingMu.Lock()
+	defer billingMu.Unlock()
+
+	if configuredHoursPerMonth > 0 {
+		return configuredHoursPerMonth
+	}
+	if hours, err := ParseHoursPerMonth(os.Getenv(HoursPerMonthEnv)); err == nil {
+		return hours
+	}
+	return HoursPerMonth
+}
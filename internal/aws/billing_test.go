@@ -0,0 +1,93 @@
+package aws
+
+import "testing"
+
+// resetHoursPerMonth restores the default billing hours after a test
+func resetHoursPerMonth(t *testing.T) {
+	t.Cleanup(func() {
+		billingMu.Lock()
+		configuredHoursPerMonth = 0
+		billingMu.Unlock()
+	})
+}
+
+func TestParseHoursPerMonth(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    float64
+		wantErr bool
+	}{
+		{value: "220", want: 220},
+		{value: "730.56", want: 730.56},
+		{value: "744", want: 744},
+		{value: "0", wantErr: true},
+		{value: "-10", wantErr: true},
+		{value: "745", wantErr: true},
+		{value: "business", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseHoursPerMonth(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseHoursPerMonth(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseHoursPerMonth(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigureHoursPerMonth(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     string
+		hours   float64
+		want    float64
+		wantErr bool
+	}{
+		{name: "default", want: HoursPerMonth},
+		{name: "from environment", env: "220", want: 220},
+		{name: "flag overrides environment", env: "220", hours: 160, want: 160},
+		{name: "invalid environment", env: "lots", wantErr: true},
+		{name: "invalid flag", hours: 1000, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetHoursPerMonth(t)
+			t.Setenv(HoursPerMonthEnv, tt.env)
+
+			err := ConfigureHoursPerMonth(tt.hours)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ConfigureHoursPerMonth() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr {
+				if got := BillingHoursPerMonth(); got != tt.want {
+					t.Errorf("BillingHoursPerMonth() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestBillingHoursPerMonth_Unconfigured(t *testing.T) {
+	resetHoursPerMonth(t)
+
+	t.Setenv(HoursPerMonthEnv, "")
+	if got := BillingHoursPerMonth(); got != HoursPerMonth {
+		t.Errorf("BillingHoursPerMonth() = %v, want %v", got, HoursPerMonth)
+	}
+
+	t.Setenv(HoursPerMonthEnv, "176")
+	if got := BillingHoursPerMonth(); got != 176 {
+		t.Errorf("BillingHoursPerMonth() with %s=176 = %v, want 176", HoursPerMonthEnv, got)
+	}
+
+	// An invalid value is reported by ConfigureHoursPerMonth, not silently used
+	t.Setenv(HoursPerMonthEnv, "0")
+	if got := BillingHoursPerMonth(); got != HoursPerMonth {
+		t.Errorf("BillingHoursPerMonth() with an invalid value = %v, want %v", got, HoursPerMonth)
+	}
+}
@@ -0,0 +1,154 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// What create does when a launch would exceed a budget
+const (
+	BudgetActionConfirm = "confirm"
+	BudgetActionRefuse  = "refuse"
+)
+
+const budgetsFile = "budgets.json"
+
+// Budget limits what Clouddley instances may cost in one context. A zero limit is not enforced
+type Budget struct {
+	// MonthlyLimit caps the monthly cost of every Clouddley instance in the context
+	MonthlyLimit float64 `json:"monthly_limit,omitempty"`
+	// InstanceLimit caps the monthly cost of a single new instance
+	InstanceLimit float64 `json:"instance_limit,omitempty"`
+	// Action is BudgetActionConfirm or BudgetActionRefuse
+	Action string `json:"action,omitempty"`
+}
+
+// BudgetViolation describes a limit a launch would exceed
+type BudgetViolation struct {
+	Limit     string
+	Max       float64
+	Projected float64
+}
+
+func (v BudgetViolation) String() string {
+	return fmt.Sprintf("%s of $%.2f/month exceeded: projected $%.2f/month", v.Limit, v.Max, v.Projected)
+}
+
+// Validate checks the limits and action
+func (b Budget) Validate() error {
+	if b.MonthlyLimit < 0 || b.InstanceLimit < 0 {
+		return fmt.Errorf("budget limits cannot be negative")
+	}
+	if b.MonthlyLimit == 0 && b.InstanceLimit == 0 {
+		return fmt.Errorf("set a monthly budget, a per-instance limit or both")
+	}
+	switch b.Action {
+	case BudgetActionConfirm, BudgetActionRefuse:
+		return nil
+	default:
+		return fmt.Errorf("invalid budget action %q (use %s|%s)", b.Action, BudgetActionConfirm, BudgetActionRefuse)
+	}
+}
+
+// Refuses reports whether launches over the budget are refused instead of confirmed
+func (b Budget) Refuses() bool {
+	return b.Action == BudgetActionRefuse
+}
+
+// Check returns the limits exceeded by adding an instance costing instanceMonthly
+// to a fleet costing fleetMonthly
+func (b Budget) Check(fleetMonthly, instanceMonthly float64) []BudgetViolation {
+	var violations []BudgetViolation
+	if b.InstanceLimit > 0 && instanceMonthly > b.InstanceLimit {
+		violations = append(violations, BudgetViolation{Limit: "Per-instance limit", Max: b.InstanceLimit, Projected: instanceMonthly})
+	}
+	if projected := fleetMonthly + instanceMonthly; b.MonthlyLimit > 0 && projected > b.MonthlyLimit {
+		violations = append(violations, BudgetViolation{Limit: "Monthly budget", Max: b.MonthlyLimit, Projected: projected})
+	}
+	return violations
+}
+
+// Budgets holds the budget of each context, keyed by BudgetContext
+type Budgets map[string]Budget
+
+// Contexts returns the configured contexts in order
+func (b Budgets) Contexts() []string {
+	contexts := make([]string, 0, len(b))
+	for name := range b {
+		contexts = append(contexts, name)
+	}
+	sort.Strings(contexts)
+	return contexts
+}
+
+// BudgetsPath returns the budget file under the user config directory
+func BudgetsPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user config directory: %w", err)
+	}
+	return filepath.Join(dir, "clouddley", budgetsFile), nil
+}
+
+// BudgetContext names the context budgets apply to: the AWS profile and region
+// Clouddley instances are listed and launched in, e.g. "corp/us-east-1"
+func BudgetContext(ctx context.Context) (string, error) {
+	cfg, err := GetAWSConfig(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get AWS config: %w", err)
+	}
+	return budgetContextName(os.Getenv("AWS_PROFILE"), cfg.Region), nil
+}
+
+// ValidateBudgetContext checks that a context has the profile/region form
+func ValidateBudgetContext(name string) error {
+	profile, region, ok := strings.Cut(name, "/")
+	if !ok || profile == "" || region == "" || strings.Contains(region, "/") {
+		return fmt.Errorf("invalid context %q: use profile/region, e.g. default/us-east-1", name)
+	}
+	return nil
+}
+
+func budgetContextName(profile, region string) string {
+	if profile == "" {
+		profile = "default"
+	}
+	return profile + "/" + region
+}
+
+// LoadBudgets reads the budget file; a missing file has no budgets
+func LoadBudgets(path string) (Budgets, error) {
+	budgets := make(Budgets)
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return budgets, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read budgets: %w", err)
+	}
+	if err := json.Unmarshal(content, &budgets); err != nil {
+		return nil, fmt.Errorf("failed to parse budgets %s: %w", path, err)
+	}
+	return budgets, nil
+}
+
+// SaveBudgets writes the budget file, creating its directory
+func SaveBudgets(path string, budgets Budgets) error {
+	content, err := json.MarshalIndent(budgets, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode budgets: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, append(content, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write budgets: %w", err)
+	}
+	return nil
+}
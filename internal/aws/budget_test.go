@@ -0,0 +1,137 @@
+package aws
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBudget_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		budget  Budget
+		wantErr bool
+	}{
+		{name: "monthly only", budget: Budget{MonthlyLimit: 500, Action: BudgetActionConfirm}},
+		{name: "per instance only", budget: Budget{InstanceLimit: 100, Action: BudgetActionRefuse}},
+		{name: "no limits", budget: Budget{Action: BudgetActionConfirm}, wantErr: true},
+		{name: "negative limit", budget: Budget{MonthlyLimit: -1, Action: BudgetActionConfirm}, wantErr: true},
+		{name: "unknown action", budget: Budget{MonthlyLimit: 500, Action: "warn"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.budget.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBudget_Check(t *testing.T) {
+	tests := []struct {
+		name            string
+		budget          Budget
+		fleetMonthly    float64
+		instanceMonthly float64
+		wantLimits      []string
+	}{
+		{name: "within both", budget: Budget{MonthlyLimit: 500, InstanceLimit: 100}, fleetMonthly: 300, instanceMonthly: 80},
+		{name: "at the limit", budget: Budget{MonthlyLimit: 500}, fleetMonthly: 400, instanceMonthly: 100},
+		{name: "fleet over", budget: Budget{MonthlyLimit: 500, InstanceLimit: 100}, fleetMonthly: 450, instanceMonthly: 80, wantLimits: []string{"Monthly budget"}},
+		{name: "instance over", budget: Budget{MonthlyLimit: 500, InstanceLimit: 100}, instanceMonthly: 140, wantLimits: []string{"Per-instance limit"}},
+		{name: "both over", budget: Budget{MonthlyLimit: 500, InstanceLimit: 100}, fleetMonthly: 400, instanceMonthly: 140, wantLimits: []string{"Per-instance limit", "Monthly budget"}},
+		{name: "no limits set", budget: Budget{}, fleetMonthly: 10000, instanceMonthly: 5000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := tt.budget.Check(tt.fleetMonthly, tt.instanceMonthly)
+			if len(violations) != len(tt.wantLimits) {
+				t.Fatalf("Check() = %v, want %v", violations, tt.wantLimits)
+			}
+			for i, violation := range violations {
+				if violation.Limit != tt.wantLimits[i] {
+					t.Errorf("violation %d = %s, want %s", i, violation.Limit, tt.wantLimits[i])
+				}
+			}
+		})
+	}
+}
+
+func TestBudgetViolation_Projected(t *testing.T) {
+	violations := Budget{MonthlyLimit: 500}.Check(450, 80)
+	if len(violations) != 1 || violations[0].Projected != 530 {
+		t.Fatalf("Check() = %+v, want a projected fleet cost of 530", violations)
+	}
+	if got, want := violations[0].String(), "Monthly budget of $500.00/month exceeded: projected $530.00/month"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestBudgetContextName(t *testing.T) {
+	if got := budgetContextName("", "us-east-1"); got != "default/us-east-1" {
+		t.Errorf("budgetContextName() = %q, want default/us-east-1", got)
+	}
+	if got := budgetContextName("corp", "eu-west-1"); got != "corp/eu-west-1" {
+		t.Errorf("budgetContextName() = %q, want corp/eu-west-1", got)
+	}
+}
+
+func TestLoadSaveBudgets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "clouddley", budgetsFile)
+
+	budgets, err := LoadBudgets(path)
+	if err != nil {
+		t.Fatalf("LoadBudgets() on a missing file error = %v", err)
+	}
+	if len(budgets) != 0 {
+		t.Fatalf("LoadBudgets() on a missing file = %v, want none", budgets)
+	}
+
+	budgets["corp/us-east-1"] = Budget{MonthlyLimit: 500, InstanceLimit: 100, Action: BudgetActionRefuse}
+	budgets["default/eu-west-1"] = Budget{MonthlyLimit: 50, Action: BudgetActionConfirm}
+	if err := SaveBudgets(path, budgets); err != nil {
+		t.Fatalf("SaveBudgets() error = %v", err)
+	}
+
+	loaded, err := LoadBudgets(path)
+	if err != nil {
+		t.Fatalf("LoadBudgets() error = %v", err)
+	}
+	if loaded["corp/us-east-1"] != budgets["corp/us-east-1"] {
+		t.Errorf("loaded budget = %+v, want %+v", loaded["corp/us-east-1"], budgets["corp/us-east-1"])
+	}
+	if contexts := loaded.Contexts(); len(contexts) != 2 || contexts[0] != "corp/us-east-1" {
+		t.Errorf("Contexts() = %v, want sorted contexts", contexts)
+	}
+
+	if err := os.WriteFile(path, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadBudgets(path); err == nil {
+		t.Error("LoadBudgets() accepted a corrupt file")
+	}
+}
+
+func TestValidateBudgetContext(t *testing.T) {
+	tests := []struct {
+		name    string
+		context string
+		wantErr bool
+	}{
+		{name: "profile and region", context: "corp/us-east-1"},
+		{name: "no slash", context: "us-east-1", wantErr: true},
+		{name: "empty profile", context: "/us-east-1", wantErr: true},
+		{name: "empty region", context: "corp/", wantErr: true},
+		{name: "extra slash", context: "corp/us-east-1/a", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateBudgetContext(tt.context); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateBudgetContext(%q) error = %v, wantErr %v", tt.context, err, tt.wantErr)
+			}
+		})
+	}
+}
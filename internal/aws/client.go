@@ -8,7 +8,6 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
-	"github.com/aws/aws-sdk-go-v2/service/pricing"
 )
 
 // GetEC2Client returns an AWS EC2 client configured with the current AWS profile
@@ -31,24 +30,16 @@ func GetEC2Client(ctx context.Context) (*ec2.Client, error) {
 	return ec2.NewFromConfig(cfg), nil
 }
 
-// GetPricingClient returns an AWS Pricing client
-func GetPricingClient(ctx context.Context) (*pricing.Client, error) {
-	awsProfile := os.Getenv("AWS_PROFILE")
-	
-	var cfg aws.Config
-	var err error
-	
-	if awsProfile != "" {
-		cfg, err = config.LoadDefaultConfig(ctx, config.WithSharedConfigProfile(awsProfile), config.WithRegion("us-east-1"))
-	} else {
-		cfg, err = config.LoadDefaultConfig(ctx, config.WithRegion("us-east-1"))
-	}
-	
+// GetEC2ClientForRegion returns an EC2 client for a region other than the profile's default
+func GetEC2ClientForRegion(ctx context.Context, region string) (*ec2.Client, error) {
+	cfg, err := GetAWSConfig(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+		return nil, err
 	}
 	
-	return pricing.NewFromConfig(cfg), nil
+	return ec2.NewFromConfig(cfg, func(o *ec2.Options) {
+		o.Region = region
+	}), nil
 }
 
 // GetAWSConfig returns the AWS configuration
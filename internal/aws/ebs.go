@@ -0,0 +1,300 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/pricing"
+)
+
+// EBS volume types, named as in the volumeApiName price list attribute
+const (
+	VolumeTypeGP2 = "gp2"
+	VolumeTypeGP3 = "gp3"
+	VolumeTypeIO1 = "io1"
+	VolumeTypeIO2 = "io2"
+	VolumeTypeST1 = "st1"
+	VolumeTypeSC1 = "sc1"
+)
+
+// gp3 includes this baseline performance in the storage price
+const (
+	gp3BaselineIOPS       = 3000
+	gp3BaselineThroughput = 125 // MiB/s
+)
+
+// io2 provisioned IOPS are billed in tiers: up to 32,000, up to 64,000 and above
+const (
+	io2Tier1Limit = 32000
+	io2Tier2Limit = 64000
+)
+
+// Price list units for EBS performance charges
+const (
+	priceUnitIOPSMonth  = "IOPS-Mo"
+	priceUnitGiBpsMonth = "GiBps-mo"
+	priceUnitMiBpsMonth = "MiBps-Mo"
+)
+
+// DefaultEBSVolume is the root volume Clouddley VMs are launched with
+var DefaultEBSVolume = EBSVolume{Type: VolumeTypeGP3, SizeGB: 100}
+
+// EBSVolume describes a volume to price or provision. Zero IOPS and Throughput use
+// the volume type's baseline
+type EBSVolume struct {
+	Type       string
+	SizeGB     int32
+	IOPS       int32
+	Throughput int32 // MiB/s, gp3 only
+}
+
+// ebsVolumeLimits are the size and performance bounds of a volume type
+type ebsVolumeLimits struct {
+	minSize, maxSize int32
+	minIOPS, maxIOPS int32 // zero when IOPS cannot be provisioned
+	iopsPerGB        int32 // maximum provisioned IOPS per GB
+	iopsRequired     bool
+}
+
+var ebsLimits = map[string]ebsVolumeLimits{
+	VolumeTypeGP2: {minSize: 1, maxSize: 16384},
+	VolumeTypeGP3: {minSize: 1, maxSize: 16384, minIOPS: gp3BaselineIOPS, maxIOPS: 16000, iopsPerGB: 500},
+	VolumeTypeIO1: {minSize: 4, maxSize: 16384, minIOPS: 100, maxIOPS: 64000, iopsPerGB: 50, iopsRequired: true},
+	VolumeTypeIO2: {minSize: 4, maxSize: 65536, minIOPS: 100, maxIOPS: 256000, iopsPerGB: 1000, iopsRequired: true},
+	VolumeTypeST1: {minSize: 125, maxSize: 16384},
+	VolumeTypeSC1: {minSize: 125, maxSize: 16384},
+}
+
+// EBSVolumeTypes returns the supported volume types
+func EBSVolumeTypes() []string {
+	return []string{VolumeTypeGP2, VolumeTypeGP3, VolumeTypeIO1, VolumeTypeIO2, VolumeTypeST1, VolumeTypeSC1}
+}
+
+func (v EBSVolume) String() string {
+	s := fmt.Sprintf("%d GB %s", v.SizeGB, v.Type)
+	if v.IOPS > 0 {
+		s += fmt.Sprintf(", %d IOPS", v.IOPS)
+	}
+	if v.Throughput > 0 {
+		s += fmt.Sprintf(", %d MiB/s", v.Throughput)
+	}
+	return s
+}
+
+// Validate checks the volume against the EBS limits for its type
+func (v EBSVolume) Validate() error {
+	limits, ok := ebsLimits[v.Type]
+	if !ok {
+		return fmt.Errorf("unsupported volume type %q (use %s)", v.Type, strings.Join(EBSVolumeTypes(), "|"))
+	}
+	if v.SizeGB < limits.minSize || v.SizeGB > limits.maxSize {
+		return fmt.Errorf("%s volumes must be %d-%d GB, got %d", v.Type, limits.minSize, limits.maxSize, v.SizeGB)
+	}
+
+	switch {
+	case v.IOPS < 0 || v.Throughput < 0:
+		return fmt.Errorf("IOPS and throughput cannot be negative")
+	case limits.maxIOPS == 0 && v.IOPS > 0:
+		return fmt.Errorf("IOPS cannot be provisioned for %s volumes", v.Type)
+	case limits.iopsRequired && v.IOPS == 0:
+		return fmt.Errorf("%s volumes require provisioned IOPS", v.Type)
+	case v.IOPS > 0 && (v.IOPS < limits.minIOPS || v.IOPS > limits.maxIOPS):
+		return fmt.Errorf("%s volumes support %d-%d IOPS, got %d", v.Type, limits.minIOPS, limits.maxIOPS, v.IOPS)
+	case v.IOPS > 0 && !(v.Type == VolumeTypeGP3 && v.IOPS <= gp3BaselineIOPS) && int64(v.IOPS) > int64(v.SizeGB)*int64(limits.iopsPerGB):
+		return fmt.Errorf("%s volumes support at most %d IOPS per GB, %d GB allows %d", v.Type, limits.iopsPerGB, v.SizeGB, v.SizeGB*limits.iopsPerGB)
+	}
+
+	if v.Throughput > 0 {
+		if v.Type != VolumeTypeGP3 {
+			return fmt.Errorf("throughput can only be provisioned for gp3 volumes")
+		}
+		if v.Throughput < gp3BaselineThroughput || v.Throughput > 1000 {
+			return fmt.Errorf("gp3 volumes support %d-1000 MiB/s, got %d", gp3BaselineThroughput, v.Throughput)
+		}
+		iops := max(v.IOPS, gp3BaselineIOPS)
+		if v.Throughput*4 > iops {
+			return fmt.Errorf("gp3 throughput is limited to 0.25 MiB/s per IOPS, %d IOPS allows %d MiB/s", iops, iops/4)
+		}
+	}
+
+	return nil
+}
+
+// ebsRateComponents lists every rate a volume type can be billed with. The storage
+// rate is named after the type, performance rates are "<type>/<charge>"
+func ebsRateComponents(volumeType string) []string {
+	switch volumeType {
+	case VolumeTypeGP3:
+		return []string{VolumeTypeGP3, "gp3/iops", "gp3/throughput"}
+	case VolumeTypeIO1:
+		return []string{VolumeTypeIO1, "io1/iops"}
+	case VolumeTypeIO2:
+		return []string{VolumeTypeIO2, "io2/iops", "io2/iops-tier2", "io2/iops-tier3"}
+	default:
+		return []string{volumeType}
+	}
+}
+
+// rateComponents lists the rates this volume is actually billed with
+func (v EBSVolume) rateComponents() []string {
+	components := []string{v.Type}
+	switch v.Type {
+	case VolumeTypeGP3:
+		if v.IOPS > gp3BaselineIOPS {
+			components = append(components, "gp3/iops")
+		}
+		if v.Throughput > gp3BaselineThroughput {
+			components = append(components, "gp3/throughput")
+		}
+	case VolumeTypeIO1:
+		components = append(components, "io1/iops")
+	case VolumeTypeIO2:
+		components = append(components, "io2/iops")
+		if v.IOPS > io2Tier1Limit {
+			components = append(components, "io2/iops-tier2")
+		}
+		if v.IOPS > io2Tier2Limit {
+			components = append(components, "io2/iops-tier3")
+		}
+	}
+	return components
+}
+
+// MonthlyCost returns the monthly USD cost of the volume given its rates, keyed as
+// returned by rateComponents
+func (v EBSVolume) MonthlyCost(rates map[string]float64) float64 {
+	cost := float64(v.SizeGB) * rates[v.Type]
+	iops := float64(v.IOPS)
+
+	switch v.Type {
+	case VolumeTypeGP3:
+		if v.IOPS > gp3BaselineIOPS {
+			cost += (iops - gp3BaselineIOPS) * rates["gp3/iops"]
+		}
+		if v.Throughput > gp3BaselineThroughput {
+			cost += float64(v.Throughput-gp3BaselineThroughput) * rates["gp3/throughput"]
+		}
+	case VolumeTypeIO1:
+		cost += iops * rates["io1/iops"]
+	case VolumeTypeIO2:
+		cost += min(iops, io2Tier1Limit) * rates["io2/iops"]
+		if iops > io2Tier1Limit {
+			cost += (min(iops, io2Tier2Limit) - io2Tier1Limit) * rates["io2/iops-tier2"]
+		}
+		if iops > io2Tier2Limit {
+			cost += (iops - io2Tier2Limit) * rates["io2/iops-tier3"]
+		}
+	}
+
+	return cost
+}
+
+// VolumePricing returns the monthly cost of a volume, served from the pricing cache
+// when possible and from the embedded snapshot when the Pricing API fails
+func (p *Pricer) VolumePricing(ctx context.Context, volume EBSVolume) (float64, bool, error) {
+	// One query returns every rate of a volume type, so share it between components
+	var live map[string]float64
+	var liveErr error
+	fetched := false
+	fetchLive := func() (map[string]float64, error) {
+		if !fetched {
+			live, liveErr = getEBSRates(ctx, p.client, p.Region, volume.Type)
+			fetched = true
+		}
+		return live, liveErr
+	}
+
+	rates := make(map[string]float64)
+	estimated := false
+	for _, component := range volume.rateComponents() {
+		price, fromSnapshot, err := priceWithFallback(p.cache, ebsPriceCacheKey(p.Region, component), func() (float64, error) {
+			live, err := fetchLive()
+			if err != nil {
+				return 0, err
+			}
+			price, ok := live[component]
+			if !ok {
+				return 0, fmt.Errorf("no EBS %s pricing found for region %s", component, p.Region)
+			}
+			return price, nil
+		}, func() (float64, bool) {
+			return p.snapshot.EBSPrice(p.Region, component)
+		})
+		if err != nil {
+			return 0, false, err
+		}
+		rates[component] = price
+		estimated = estimated || fromSnapshot
+	}
+
+	return volume.MonthlyCost(rates), estimated, nil
+}
+
+// getEBSRates fetches every on-demand rate of a volume type in a region, keyed as in
+// ebsRateComponents
+func getEBSRates(ctx context.Context, client *pricing.Client, region, volumeType string) (map[string]float64, error) {
+	priceList, err := getProductsInRegion(ctx, client, region, 100, termMatch("volumeApiName", volumeType))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	rates := make(map[string]float64)
+	for _, raw := range priceList {
+		item, err := parsePriceListItem(raw)
+		if err != nil {
+			continue
+		}
+		if component, price, ok := ebsRate(item, volumeType, now); ok {
+			rates[component] = price
+		}
+	}
+
+	if _, ok := rates[volumeType]; !ok {
+		return nil, fmt.Errorf("no EBS %s pricing found for region %s", volumeType, region)
+	}
+	return rates, nil
+}
+
+// ebsRate classifies a price list item as one of the volume type's rates
+func ebsRate(item *priceListItem, volumeType string, at time.Time) (string, float64, bool) {
+	if item.Product.Attributes["volumeApiName"] != volumeType {
+		return "", 0, false
+	}
+
+	var component string
+	var price float64
+	var err error
+	switch item.Product.ProductFamily {
+	case "Storage":
+		component = volumeType
+		price, err = item.onDemandPrice(priceUnitGBMonth, at)
+	case "System Operation":
+		component = volumeType + "/iops"
+		usageType := item.Product.Attributes["usagetype"]
+		switch {
+		case strings.HasSuffix(usageType, ".tier2"):
+			component = volumeType + "/iops-tier2"
+		case strings.HasSuffix(usageType, ".tier3"):
+			component = volumeType + "/iops-tier3"
+		}
+		price, err = item.onDemandPrice(priceUnitIOPSMonth, at)
+	case "Provisioned Throughput":
+		component = volumeType + "/throughput"
+		// Throughput is published per GiB/s-month; Clouddley provisions in MiB/s
+		price, err = item.onDemandPrice(priceUnitMiBpsMonth, at)
+		if err != nil {
+			price, err = item.onDemandPrice(priceUnitGiBpsMonth, at)
+			price /= 1024
+		}
+	default:
+		return "", 0, false
+	}
+
+	if err != nil {
+		return "", 0, false
+	}
+	return component, price, true
+}
@@ -0,0 +1,124 @@
+package aws
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEBSRate(t *testing.T) {
+	june := time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		fixture       string
+		volumeType    string
+		wantComponent string
+		wantPrice     float64
+		wantOK        bool
+	}{
+		{name: "gp3 storage", fixture: "ebs_gp3_us_east_1.json", volumeType: "gp3", wantComponent: "gp3", wantPrice: 0.08, wantOK: true},
+		{name: "gp2 is not gp3", fixture: "ebs_gp2_us_east_1.json", volumeType: "gp3"},
+		{name: "gp2 storage", fixture: "ebs_gp2_us_east_1.json", volumeType: "gp2", wantComponent: "gp2", wantPrice: 0.10, wantOK: true},
+		{name: "gp3 iops", fixture: "ebs_gp3_iops_us_east_1.json", volumeType: "gp3", wantComponent: "gp3/iops", wantPrice: 0.005, wantOK: true},
+		{name: "gp3 throughput per MiB/s", fixture: "ebs_gp3_throughput_us_east_1.json", volumeType: "gp3", wantComponent: "gp3/throughput", wantPrice: 0.04, wantOK: true},
+		{name: "io2 iops tier 2", fixture: "ebs_io2_iops_tier2_us_east_2.json", volumeType: "io2", wantComponent: "io2/iops-tier2", wantPrice: 0.0455, wantOK: true},
+		{name: "instance is not a volume", fixture: "ec2_t3_micro_us_east_1.json", volumeType: "gp3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := loadPriceListFixture(t, tt.fixture)
+			component, price, ok := ebsRate(item, tt.volumeType, june)
+			if ok != tt.wantOK {
+				t.Fatalf("ebsRate() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if component != tt.wantComponent {
+				t.Errorf("ebsRate() component = %q, want %q", component, tt.wantComponent)
+			}
+			if math.Abs(price-tt.wantPrice) > 1e-9 {
+				t.Errorf("ebsRate() price = %v, want %v", price, tt.wantPrice)
+			}
+		})
+	}
+}
+
+func TestEBSVolume_MonthlyCost(t *testing.T) {
+	rates := map[string]float64{
+		"gp2": 0.10, "gp3": 0.08, "gp3/iops": 0.005, "gp3/throughput": 0.04,
+		"io1": 0.125, "io1/iops": 0.065,
+		"io2": 0.125, "io2/iops": 0.065, "io2/iops-tier2": 0.0455, "io2/iops-tier3": 0.03185,
+		"st1": 0.045, "sc1": 0.015,
+	}
+
+	tests := []struct {
+		name   string
+		volume EBSVolume
+		want   float64
+	}{
+		{name: "default gp3", volume: DefaultEBSVolume, want: 8},
+		{name: "gp3 baseline performance is free", volume: EBSVolume{Type: "gp3", SizeGB: 100, IOPS: 3000, Throughput: 125}, want: 8},
+		{name: "gp3 extra iops and throughput", volume: EBSVolume{Type: "gp3", SizeGB: 100, IOPS: 6000, Throughput: 250}, want: 8 + 3000*0.005 + 125*0.04},
+		{name: "gp2", volume: EBSVolume{Type: "gp2", SizeGB: 100}, want: 10},
+		{name: "io1", volume: EBSVolume{Type: "io1", SizeGB: 100, IOPS: 1000}, want: 12.5 + 65},
+		{name: "io2 tier 1", volume: EBSVolume{Type: "io2", SizeGB: 100, IOPS: 1000}, want: 12.5 + 65},
+		{name: "io2 all tiers", volume: EBSVolume{Type: "io2", SizeGB: 100, IOPS: 80000}, want: 12.5 + 32000*0.065 + 32000*0.0455 + 16000*0.03185},
+		{name: "st1", volume: EBSVolume{Type: "st1", SizeGB: 500}, want: 22.5},
+		{name: "sc1", volume: EBSVolume{Type: "sc1", SizeGB: 500}, want: 7.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Only the rates the volume is billed with are fetched
+			used := make(map[string]float64)
+			for _, component := range tt.volume.rateComponents() {
+				used[component] = rates[component]
+			}
+			if got := tt.volume.MonthlyCost(used); math.Abs(got-tt.want) > 1e-6 {
+				t.Errorf("MonthlyCost() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEBSVolume_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		volume  EBSVolume
+		wantErr string
+	}{
+		{name: "default", volume: DefaultEBSVolume},
+		{name: "gp3 with performance", volume: EBSVolume{Type: "gp3", SizeGB: 100, IOPS: 6000, Throughput: 250}},
+		{name: "small gp3 at baseline", volume: EBSVolume{Type: "gp3", SizeGB: 1, IOPS: 3000}},
+		{name: "io2", volume: EBSVolume{Type: "io2", SizeGB: 100, IOPS: 64000}},
+		{name: "st1", volume: EBSVolume{Type: "st1", SizeGB: 125}},
+		{name: "unknown type", volume: EBSVolume{Type: "standard", SizeGB: 100}, wantErr: "unsupported volume type"},
+		{name: "too small", volume: EBSVolume{Type: "st1", SizeGB: 100}, wantErr: "125-16384 GB"},
+		{name: "io1 without iops", volume: EBSVolume{Type: "io1", SizeGB: 100}, wantErr: "require provisioned IOPS"},
+		{name: "gp2 with iops", volume: EBSVolume{Type: "gp2", SizeGB: 100, IOPS: 3000}, wantErr: "cannot be provisioned"},
+		{name: "gp3 iops out of range", volume: EBSVolume{Type: "gp3", SizeGB: 100, IOPS: 20000}, wantErr: "3000-16000 IOPS"},
+		{name: "gp3 iops per GB", volume: EBSVolume{Type: "gp3", SizeGB: 8, IOPS: 5000}, wantErr: "per GB"},
+		{name: "io1 iops per GB", volume: EBSVolume{Type: "io1", SizeGB: 10, IOPS: 1000}, wantErr: "per GB"},
+		{name: "throughput on io2", volume: EBSVolume{Type: "io2", SizeGB: 100, IOPS: 1000, Throughput: 200}, wantErr: "only be provisioned for gp3"},
+		{name: "throughput above iops ratio", volume: EBSVolume{Type: "gp3", SizeGB: 100, Throughput: 1000}, wantErr: "0.25 MiB/s per IOPS"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.volume.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
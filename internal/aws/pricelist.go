@@ -0,0 +1,161 @@
+package aws
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Price dimension units used by the AWS Price List
+const (
+	priceUnitHours   = "Hrs"
+	priceUnitGBMonth = "GB-Mo"
+)
+
+// errNoMatchingPrice is returned when a price list item has no dimension for the requested unit
+var errNoMatchingPrice = errors.New("no matching price dimension")
+
+// priceListItem is one entry of a Pricing API GetProducts PriceList
+type priceListItem struct {
+	Product         priceListProduct `json:"product"`
+	ServiceCode     string           `json:"serviceCode"`
+	Terms           priceListTerms   `json:"terms"`
+	Version         string           `json:"version"`
+	PublicationDate string           `json:"publicationDate"`
+}
+
+// priceListProduct describes what is being priced
+type priceListProduct struct {
+	ProductFamily string            `json:"productFamily"`
+	Attributes    map[string]string `json:"attributes"`
+	SKU           string            `json:"sku"`
+}
+
+// priceListTerms holds the offers for a product, keyed by offer term code
+type priceListTerms struct {
+	OnDemand map[string]priceListOffer `json:"OnDemand"`
+	Reserved map[string]priceListOffer `json:"Reserved"`
+}
+
+// priceListOffer is one set of prices with the date it takes effect
+type priceListOffer struct {
+	OfferTermCode   string                        `json:"offerTermCode"`
+	SKU             string                        `json:"sku"`
+	EffectiveDate   string                        `json:"effectiveDate"`
+	PriceDimensions map[string]priceListDimension `json:"priceDimensions"`
+	TermAttributes  map[string]string             `json:"termAttributes"`
+}
+
+// priceListDimension is a single rate, possibly one tier of a tiered price
+type priceListDimension struct {
+	RateCode     string            `json:"rateCode"`
+	Description  string            `json:"description"`
+	Unit         string            `json:"unit"`
+	BeginRange   string            `json:"beginRange"`
+	EndRange     string            `json:"endRange"`
+	PricePerUnit map[string]string `json:"pricePerUnit"`
+}
+
+// parsePriceListItem decodes one PriceList entry
+func parsePriceListItem(raw string) (*priceListItem, error) {
+	var item priceListItem
+	if err := json.Unmarshal([]byte(raw), &item); err != nil {
+		return nil, fmt.Errorf("failed to parse pricing JSON: %w", err)
+	}
+	return &item, nil
+}
+
+// onDemandPrice returns the USD on-demand price per unit in effect at the given time
+func (i *priceListItem) onDemandPrice(unit string, at time.Time) (float64, error) {
+	offer, ok := effectiveOffer(i.Terms.OnDemand, at)
+	if !ok {
+		return 0, fmt.Errorf("no on-demand offer in effect for %s", i.Product.SKU)
+	}
+	return offer.price(unit)
+}
+
+// reservedPrice returns the effective hourly USD price of a standard, no upfront
+// reserved instance with the given lease length ("1yr" or "3yr")
+func (i *priceListItem) reservedPrice(lease string, at time.Time) (float64, error) {
+	offers := make(map[string]priceListOffer)
+	for code, offer := range i.Terms.Reserved {
+		attrs := offer.TermAttributes
+		if attrs["LeaseContractLength"] == lease && attrs["OfferingClass"] == "standard" && attrs["PurchaseOption"] == "No Upfront" {
+			offers[code] = offer
+		}
+	}
+
+	offer, ok := effectiveOffer(offers, at)
+	if !ok {
+		return 0, fmt.Errorf("no %s standard no upfront reservation for %s", lease, i.Product.SKU)
+	}
+	return offer.price(priceUnitHours)
+}
+
+// effectiveDate parses the offer's effective date; offers without one apply from the start
+func (o priceListOffer) effectiveDate() time.Time {
+	if o.EffectiveDate == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339, o.EffectiveDate)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// price returns the USD price of the first tier of the dimension billed in unit
+func (o priceListOffer) price(unit string) (float64, error) {
+	var matches []priceListDimension
+	for _, dimension := range o.PriceDimensions {
+		if dimension.Unit == unit {
+			matches = append(matches, dimension)
+		}
+	}
+	if len(matches) == 0 {
+		return 0, fmt.Errorf("%w: unit %s", errNoMatchingPrice, unit)
+	}
+
+	// For tiered prices the first tier is what a single resource pays
+	sort.Slice(matches, func(a, b int) bool {
+		return rangeStart(matches[a].BeginRange) < rangeStart(matches[b].BeginRange)
+	})
+
+	usd, ok := matches[0].PricePerUnit["USD"]
+	if !ok {
+		return 0, fmt.Errorf("no USD price for rate %s", matches[0].RateCode)
+	}
+	price, err := strconv.ParseFloat(usd, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid price %q for rate %s: %w", usd, matches[0].RateCode, err)
+	}
+	return price, nil
+}
+
+// effectiveOffer picks the offer with the latest effective date that is not after at
+func effectiveOffer(offers map[string]priceListOffer, at time.Time) (priceListOffer, bool) {
+	var best priceListOffer
+	found := false
+	for _, offer := range offers {
+		effective := offer.effectiveDate()
+		if effective.After(at) {
+			continue
+		}
+		if !found || effective.After(best.effectiveDate()) {
+			best = offer
+			found = true
+		}
+	}
+	return best, found
+}
+
+func rangeStart(value string) float64 {
+	start, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0
+	}
+	return start
+}
@@ -0,0 +1,135 @@
+package aws
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func loadPriceListFixture(t *testing.T, name string) *priceListItem {
+	t.Helper()
+	raw, err := os.ReadFile(filepath.Join("testdata", "pricelist", name))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	item, err := parsePriceListItem(string(raw))
+	if err != nil {
+		t.Fatalf("parsePriceListItem(%s) error = %v", name, err)
+	}
+	return item
+}
+
+func TestPriceListItem_OnDemandPrice(t *testing.T) {
+	june := time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		fixture    string
+		unit       string
+		at         time.Time
+		want       float64
+		wantErr    bool
+		noMatching bool
+	}{
+		{name: "ec2 hourly", fixture: "ec2_t3_micro_us_east_1.json", unit: priceUnitHours, at: june, want: 0.0104},
+		{name: "ec2 ignores reserved terms", fixture: "ec2_t3_micro_us_east_1.json", unit: priceUnitHours, at: june.AddDate(1, 0, 0), want: 0.0104},
+		{name: "ec2 wrong unit", fixture: "ec2_t3_micro_us_east_1.json", unit: priceUnitGBMonth, at: june, wantErr: true, noMatching: true},
+		{name: "ec2 before any offer", fixture: "ec2_t3_micro_us_east_1.json", unit: priceUnitHours, at: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), wantErr: true},
+		{name: "current price before change", fixture: "ec2_price_change.json", unit: priceUnitHours, at: june, want: 0.096},
+		{name: "new price after change", fixture: "ec2_price_change.json", unit: priceUnitHours, at: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), want: 0.09},
+		{name: "ebs per GB-month", fixture: "ebs_gp3_us_east_1.json", unit: priceUnitGBMonth, at: june, want: 0.08},
+		{name: "ebs wrong unit", fixture: "ebs_gp3_us_east_1.json", unit: priceUnitHours, at: june, wantErr: true, noMatching: true},
+		{name: "tiered uses first tier", fixture: "tiered_data_transfer.json", unit: "GB", at: june, want: 0},
+		{name: "only quantity dimension", fixture: "ec2_reservation_only.json", unit: priceUnitHours, at: june, wantErr: true, noMatching: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := loadPriceListFixture(t, tt.fixture)
+			got, err := item.onDemandPrice(tt.unit, tt.at)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("onDemandPrice() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.noMatching && !errors.Is(err, errNoMatchingPrice) {
+				t.Errorf("onDemandPrice() error = %v, want errNoMatchingPrice", err)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("onDemandPrice() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePriceListItem(t *testing.T) {
+	item := loadPriceListFixture(t, "ebs_gp3_us_east_1.json")
+	if item.Product.ProductFamily != "Storage" {
+		t.Errorf("ProductFamily = %q, want Storage", item.Product.ProductFamily)
+	}
+	if item.Product.Attributes["volumeApiName"] != "gp3" {
+		t.Errorf("volumeApiName = %q, want gp3", item.Product.Attributes["volumeApiName"])
+	}
+
+	reserved := loadPriceListFixture(t, "ec2_t3_micro_us_east_1.json").Terms.Reserved
+	if len(reserved) != 1 {
+		t.Fatalf("Reserved terms = %d, want 1", len(reserved))
+	}
+	for _, offer := range reserved {
+		if offer.TermAttributes["LeaseContractLength"] != "1yr" {
+			t.Errorf("LeaseContractLength = %q, want 1yr", offer.TermAttributes["LeaseContractLength"])
+		}
+	}
+
+	if _, err := parsePriceListItem("{"); err == nil {
+		t.Error("parsePriceListItem() accepted invalid JSON")
+	}
+}
+
+func TestPriceListOffer_InvalidPrice(t *testing.T) {
+	tests := []struct {
+		name      string
+		dimension priceListDimension
+	}{
+		{name: "no USD", dimension: priceListDimension{Unit: priceUnitHours, PricePerUnit: map[string]string{"CNY": "0.1"}}},
+		{name: "not a number", dimension: priceListDimension{Unit: priceUnitHours, PricePerUnit: map[string]string{"USD": "free"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			offer := priceListOffer{PriceDimensions: map[string]priceListDimension{"rate": tt.dimension}}
+			if _, err := offer.price(priceUnitHours); err == nil {
+				t.Error("price() accepted an invalid price")
+			}
+		})
+	}
+}
+
+func TestPriceListItem_ReservedPrice(t *testing.T) {
+	june := time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		lease   string
+		at      time.Time
+		want    float64
+		wantErr bool
+	}{
+		{name: "1 year no upfront", lease: "1yr", at: june, want: 0.0065},
+		{name: "no 3 year offer", lease: "3yr", at: june, wantErr: true},
+		{name: "before the offer", lease: "1yr", at: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), wantErr: true},
+	}
+
+	item := loadPriceListFixture(t, "ec2_t3_micro_us_east_1.json")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := item.reservedPrice(tt.lease, tt.at)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("reservedPrice() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("reservedPrice() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
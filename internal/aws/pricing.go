@@ -2,276 +2,258 @@ package aws
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"strconv"
-	"strings"
+	"sync"
+	"time"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/pricing"
-	"github.com/aws/aws-sdk-go-v2/service/pricing/types"
+	"github.com/aws/aws-sdk-go-v2/service/savingsplans"
+	"github.com/clouddley/clouddley/internal/log"
 )
 
-// PricingInfo holds pricing information for an instance type
+// HoursPerMonth is the average number of hours in a month (24 hours * 30.44 days).
+// Instances are priced for BillingHoursPerMonth, which defaults to it
+const HoursPerMonth = 24 * 30.44
+
+// PricingInfo holds pricing information for an instance type. Monthly prices
+// assume the instance runs BillingHoursPerMonth() hours; storage is billed for the whole month
 type PricingInfo struct {
 	InstanceType    string
-	OnDemandPrice   float64
-	EBSPrice        float64
-	TotalPrice      float64
+	HourlyPrice     float64 // on-demand compute per running hour
+	OnDemandPrice   float64 // monthly compute
+	EBSPrice        float64 // monthly storage
+	TotalPrice      float64 // monthly compute and storage
+	HoursPerMonth   float64
 	FormattedPrice  string
+	FormattedHourly string
+	// Estimated is set when a price came from the embedded snapshot instead of the Pricing API
+	Estimated    bool
+	SnapshotDate string
 }
 
-// GetInstancePricing fetches pricing for a specific EC2 instance type using the default region
-func GetInstancePricing(ctx context.Context, instanceType string) (*PricingInfo, error) {
-	// Get the default region from AWS config
+// DefaultPricingParallelism bounds concurrent Pricing API requests
+const DefaultPricingParallelism = 4
+
+// Pricer fetches instance pricing for one region, sharing a single Pricing API
+// client and the pricing cache between calls. It is safe for concurrent use
+type Pricer struct {
+	Region       string
+	client       *pricing.Client
+	savingsPlans SavingsPlanRatesClient
+	cache        *PricingCache
+	snapshot     *PricingSnapshot
+}
+
+// NewPricer loads the AWS config once and returns a Pricer for its default region
+func NewPricer(ctx context.Context) (*Pricer, error) {
 	cfg, err := GetAWSConfig(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get AWS config: %w", err)
 	}
-	region := cfg.Region
 
-	client, err := GetPricingClient(ctx)
+	// The Pricing API is only served from a few regions; us-east-1 has every price list
+	client := pricing.NewFromConfig(cfg, func(o *pricing.Options) {
+		o.Region = "us-east-1"
+	})
+	// The Savings Plans API is global and served from us-east-1
+	savingsPlansClient := savingsplans.NewFromConfig(cfg, func(o *savingsplans.Options) {
+		o.Region = "us-east-1"
+	})
+
+	return &Pricer{
+		Region:       cfg.Region,
+		client:       client,
+		savingsPlans: savingsPlansClient,
+		cache:        currentPricingCache(),
+		snapshot:     EmbeddedPricingSnapshot(),
+	}, nil
+}
+
+// ForRegion returns a Pricer for another region sharing the client and cache
+func (p *Pricer) ForRegion(region string) *Pricer {
+	other := *p
+	other.Region = region
+	return &other
+}
+
+// ReservedPricing returns the effective hourly price of a standard, no upfront
+// reserved instance with the given lease ("1yr" or "3yr")
+func (p *Pricer) ReservedPricing(ctx context.Context, instanceType, lease string) (float64, error) {
+	return cachedPrice(p.cache, reservedPriceCacheKey(p.Region, instanceType, lease), func() (float64, error) {
+		return getEC2ReservedPrice(ctx, p.client, instanceType, p.Region, lease)
+	})
+}
+
+// GetInstancePricing fetches pricing for a specific EC2 instance type using the default region
+func GetInstancePricing(ctx context.Context, instanceType string) (*PricingInfo, error) {
+	pricer, err := NewPricer(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create pricing client: %w", err)
+		return nil, err
 	}
+	return pricer.InstancePricing(ctx, instanceType, DefaultEBSVolume)
+}
 
+// InstancePricing returns the monthly on-demand cost of an instance type with the
+// given root volume, served from the pricing cache when a fresh entry exists. When
+// the Pricing API fails, prices from the embedded snapshot are used and marked Estimated
+func (p *Pricer) InstancePricing(ctx context.Context, instanceType string, volume EBSVolume) (*PricingInfo, error) {
 	// Get EC2 instance pricing
-	onDemandPrice, err := getEC2OnDemandPrice(ctx, client, instanceType, region)
+	hourlyPrice, ec2Estimated, err := p.OnDemandHourly(ctx, instanceType)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get EC2 pricing: %w", err)
 	}
+	hoursPerMonth := BillingHoursPerMonth()
+	onDemandPrice := hourlyPrice * hoursPerMonth
 
-	// Get EBS gp3 pricing for 100GB
-	ebsPrice, err := getEBSPrice(ctx, client, region, 100)
+	// Get EBS pricing for the root volume, including provisioned IOPS and throughput
+	ebsPrice, ebsEstimated, err := p.VolumePricing(ctx, volume)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get EBS pricing: %w", err)
 	}
 
 	totalPrice := onDemandPrice + ebsPrice
-	
-	return &PricingInfo{
-		InstanceType:   instanceType,
-		OnDemandPrice:  onDemandPrice,
-		EBSPrice:       ebsPrice,
-		TotalPrice:     totalPrice,
-		FormattedPrice: fmt.Sprintf("$%.2f/month", totalPrice),
-	}, nil
-}
 
-// getEC2OnDemandPrice fetches the on-demand price for an EC2 instance
-func getEC2OnDemandPrice(ctx context.Context, client *pricing.Client, instanceType, region string) (float64, error) {
-	input := &pricing.GetProductsInput{
-		ServiceCode: aws.String("AmazonEC2"),
-		Filters: []types.Filter{
-			{
-				Type:  types.FilterTypeTermMatch,
-				Field: aws.String("instanceType"),
-				Value: aws.String(instanceType),
-			},
-			{
-				Type:  types.FilterTypeTermMatch,
-				Field: aws.String("location"),
-				Value: aws.String(getLocationName(region)),
-			},
-			{
-				Type:  types.FilterTypeTermMatch,
-				Field: aws.String("tenancy"),
-				Value: aws.String("Shared"),
-			},
-			{
-				Type:  types.FilterTypeTermMatch,
-				Field: aws.String("operatingSystem"),
-				Value: aws.String("Linux"),
-			},
-			{
-				Type:  types.FilterTypeTermMatch,
-				Field: aws.String("preInstalledSw"),
-				Value: aws.String("NA"),
-			},
-			{
-				Type:  types.FilterTypeTermMatch,
-				Field: aws.String("capacitystatus"),
-				Value: aws.String("Used"),
-			},
-		},
-		MaxResults: aws.Int32(1),
+	info := &PricingInfo{
+		InstanceType:    instanceType,
+		HourlyPrice:     hourlyPrice,
+		OnDemandPrice:   onDemandPrice,
+		EBSPrice:        ebsPrice,
+		TotalPrice:      totalPrice,
+		HoursPerMonth:   hoursPerMonth,
+		FormattedPrice:  fmt.Sprintf("$%.2f/month", totalPrice),
+		FormattedHourly: fmt.Sprintf("$%.4f/hour", hourlyPrice),
 	}
-
-	result, err := client.GetProducts(ctx, input)
-	if err != nil {
-		return 0, fmt.Errorf("failed to get products: %w", err)
+	if ec2Estimated || ebsEstimated {
+		info.Estimated = true
+		info.SnapshotDate = p.snapshot.GeneratedAt
+		info.FormattedPrice = "~" + info.FormattedPrice
+		info.FormattedHourly = "~" + info.FormattedHourly
 	}
+	return info, nil
+}
 
-	if len(result.PriceList) == 0 {
-		return 0, fmt.Errorf("no pricing found for instance type %s in %s", instanceType, region)
-	}
+// OnDemandHourly returns the hourly on-demand price of an instance type without
+// storage. estimated reports whether it came from the embedded snapshot
+func (p *Pricer) OnDemandHourly(ctx context.Context, instanceType string) (float64, bool, error) {
+	return priceWithFallback(p.cache, ec2PriceCacheKey(p.Region, instanceType), func() (float64, error) {
+		return getEC2OnDemandPrice(ctx, p.client, instanceType, p.Region)
+	}, func() (float64, bool) {
+		return p.snapshot.EC2Price(p.Region, instanceType)
+	})
+}
 
-	// Parse the pricing JSON
-	var product map[string]interface{}
-	if err := json.Unmarshal([]byte(result.PriceList[0]), &product); err != nil {
-		return 0, fmt.Errorf("failed to parse pricing JSON: %w", err)
+// priceWithFallback returns a cached or live price, or the snapshot price when the
+// live fetch fails. estimated reports whether the snapshot was used
+func priceWithFallback(cache *PricingCache, key string, fetch func() (float64, error), snapshot func() (float64, bool)) (price float64, estimated bool, err error) {
+	price, err = cachedPrice(cache, key, fetch)
+	if err == nil {
+		return price, false, nil
 	}
 
-	// Navigate the complex pricing structure
-	terms, ok := product["terms"].(map[string]interface{})
-	if !ok {
-		return 0, fmt.Errorf("terms not found in pricing data")
+	if fallback, ok := snapshot(); ok {
+		log.Debug("Pricing API unavailable, using snapshot price", "key", key, "error", err)
+		return fallback, true, nil
 	}
+	return 0, false, err
+}
 
-	onDemand, ok := terms["OnDemand"].(map[string]interface{})
-	if !ok {
-		return 0, fmt.Errorf("OnDemand terms not found")
+// ForEachLimited calls fn for every index in [0, n) with at most parallel calls
+// running at once and returns when all of them have finished
+func ForEachLimited(n, parallel int, fn func(i int)) {
+	if parallel < 1 {
+		parallel = 1
 	}
 
-	// Get the first (and usually only) on-demand offer
-	for _, offer := range onDemand {
-		offerMap, ok := offer.(map[string]interface{})
-		if !ok {
-			continue
-		}
-
-		priceDimensions, ok := offerMap["priceDimensions"].(map[string]interface{})
-		if !ok {
-			continue
-		}
-
-		// Get the first price dimension
-		for _, dimension := range priceDimensions {
-			dimMap, ok := dimension.(map[string]interface{})
-			if !ok {
-				continue
-			}
-
-			pricePerUnit, ok := dimMap["pricePerUnit"].(map[string]interface{})
-			if !ok {
-				continue
-			}
-
-			usdPrice, ok := pricePerUnit["USD"].(string)
-			if !ok {
-				continue
-			}
-
-			hourlyPrice, err := strconv.ParseFloat(usdPrice, 64)
-			if err != nil {
-				continue
-			}
-
-			// Convert hourly to monthly (24 hours * 30.44 days average)
-			monthlyPrice := hourlyPrice * 24 * 30.44
-			return monthlyPrice, nil
-		}
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(i)
+		}(i)
 	}
-
-	return 0, fmt.Errorf("failed to extract price from pricing data")
+	wg.Wait()
 }
 
-// getEBSPrice fetches EBS gp3 pricing per GB
-func getEBSPrice(ctx context.Context, client *pricing.Client, region string, sizeGB int) (float64, error) {
-	input := &pricing.GetProductsInput{
-		ServiceCode: aws.String("AmazonEC2"),
-		Filters: []types.Filter{
-			{
-				Type:  types.FilterTypeTermMatch,
-				Field: aws.String("productFamily"),
-				Value: aws.String("Storage"),
-			},
-			{
-				Type:  types.FilterTypeTermMatch,
-				Field: aws.String("volumeType"),
-				Value: aws.String("General Purpose"),
-			},
-			{
-				Type:  types.FilterTypeTermMatch,
-				Field: aws.String("location"),
-				Value: aws.String(getLocationName(region)),
-			},
-		},
-		MaxResults: aws.Int32(10),
+// cachedPrice returns the cached price for key, or fetches and caches it
+func cachedPrice(cache *PricingCache, key string, fetch func() (float64, error)) (float64, error) {
+	if price, ok := cache.Get(key); ok {
+		log.Debug("Using cached price", "key", key, "price", price)
+		return price, nil
 	}
 
-	result, err := client.GetProducts(ctx, input)
+	price, err := fetch()
 	if err != nil {
-		return 0, fmt.Errorf("failed to get EBS products: %w", err)
+		return 0, err
 	}
 
-	for _, priceListItem := range result.PriceList {
-		var product map[string]interface{}
-		if err := json.Unmarshal([]byte(priceListItem), &product); err != nil {
-			continue
-		}
+	if err := cache.Set(key, price); err != nil {
+		log.Debug("Failed to update pricing cache", "key", key, "error", err)
+	}
+	return price, nil
+}
 
-		// Check if this is gp3 storage
-		productMap, ok := product["product"].(map[string]interface{})
-		if !ok {
-			continue
-		}
+// getEC2OnDemandPrice fetches the on-demand hourly price for an EC2 instance
+func getEC2OnDemandPrice(ctx context.Context, client *pricing.Client, instanceType, region string) (float64, error) {
+	now := time.Now()
+	return getEC2Price(ctx, client, instanceType, region, func(item *priceListItem) (float64, error) {
+		return item.onDemandPrice(priceUnitHours, now)
+	})
+}
 
-		attributes, ok := productMap["attributes"].(map[string]interface{})
-		if !ok {
-			continue
-		}
+// getEC2ReservedPrice fetches the effective hourly price of a standard, no upfront
+// reserved instance with the given lease ("1yr" or "3yr")
+func getEC2ReservedPrice(ctx context.Context, client *pricing.Client, instanceType, region, lease string) (float64, error) {
+	now := time.Now()
+	return getEC2Price(ctx, client, instanceType, region, func(item *priceListItem) (float64, error) {
+		return item.reservedPrice(lease, now)
+	})
+}
 
-		storageMedia, ok := attributes["storageMedia"].(string)
-		if !ok || !strings.Contains(strings.ToLower(storageMedia), "ssd") {
-			continue
-		}
+// getEC2Price finds the shared tenancy Linux product for an instance type and
+// extracts a price from the first item that has one
+func getEC2Price(ctx context.Context, client *pricing.Client, instanceType, region string, extract func(*priceListItem) (float64, error)) (float64, error) {
+	priceList, err := getProductsInRegion(ctx, client, region, 10,
+		termMatch("instanceType", instanceType),
+		termMatch("tenancy", "Shared"),
+		termMatch("operatingSystem", "Linux"),
+		termMatch("preInstalledSw", "NA"),
+		termMatch("capacitystatus", "Used"),
+	)
+	if err != nil {
+		return 0, err
+	}
 
-		// Parse pricing
-		terms, ok := product["terms"].(map[string]interface{})
-		if !ok {
-			continue
-		}
+	if len(priceList) == 0 {
+		return 0, fmt.Errorf("no pricing found for instance type %s in %s", instanceType, region)
+	}
 
-		onDemand, ok := terms["OnDemand"].(map[string]interface{})
-		if !ok {
+	// Capacity reservation and other rates share the product, so select by unit
+	var lastErr error
+	for _, raw := range priceList {
+		item, err := parsePriceListItem(raw)
+		if err != nil {
+			lastErr = err
 			continue
 		}
 
-		for _, offer := range onDemand {
-			offerMap, ok := offer.(map[string]interface{})
-			if !ok {
-				continue
-			}
-
-			priceDimensions, ok := offerMap["priceDimensions"].(map[string]interface{})
-			if !ok {
-				continue
-			}
-
-			for _, dimension := range priceDimensions {
-				dimMap, ok := dimension.(map[string]interface{})
-				if !ok {
-					continue
-				}
-
-				pricePerUnit, ok := dimMap["pricePerUnit"].(map[string]interface{})
-				if !ok {
-					continue
-				}
-
-				usdPrice, ok := pricePerUnit["USD"].(string)
-				if !ok {
-					continue
-				}
-
-				pricePerGBMonth, err := strconv.ParseFloat(usdPrice, 64)
-				if err != nil {
-					continue
-				}
-
-				// Calculate total EBS cost for the specified size
-				totalEBSCost := pricePerGBMonth * float64(sizeGB)
-				return totalEBSCost, nil
-			}
+		price, err := extract(item)
+		if err != nil {
+			lastErr = err
+			continue
 		}
+		return price, nil
 	}
 
-	return 0, fmt.Errorf("no EBS pricing found for region %s", region)
+	return 0, fmt.Errorf("failed to extract price from pricing data: %w", lastErr)
 }
 
-// getLocationName converts AWS region to location name used in pricing API
-func getLocationName(region string) string {
+// knownLocationName looks a region up in the static map of pricing location names.
+// The map is an offline fallback; queries match on regionCode first
+func knownLocationName(region string) (string, bool) {
 	locationMap := map[string]string{
 		"us-east-1":      "US East (N. Virginia)",
 		"us-east-2":      "US East (Ohio)",
@@ -300,12 +282,15 @@ func getLocationName(region string) string {
 		"me-south-1":     "Middle East (Bahrain)",
 		"me-central-1":   "Middle East (UAE)",
 		"sa-east-1":      "South America (Sao Paulo)",
-		"us-gov-west-1": "AWS GovCloud (US-West)",
-		"us-gov-east-1": "AWS GovCloud (US-East)",
+		"ca-west-1":      "Canada West (Calgary)",
+		"il-central-1":   "Israel (Tel Aviv)",
+		"ap-southeast-5": "Asia Pacific (Malaysia)",
+		"ap-southeast-7": "Asia Pacific (Thailand)",
+		"mx-central-1":   "Mexico (Central)",
+		"us-gov-west-1":  "AWS GovCloud (US-West)",
+		"us-gov-east-1":  "AWS GovCloud (US-East)",
 	}
 
-	if location, ok := locationMap[region]; ok {
-		return location
-	}
-	return region // Return the region itself if not found in map
+	location, ok := locationMap[region]
+	return location, ok
 }
@@ -0,0 +1,224 @@
+package aws
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultPricingCacheTTL is how long a cached price is used before it is fetched again
+	DefaultPricingCacheTTL = 7 * 24 * time.Hour
+
+	// PricingCacheTTLEnv overrides the cache TTL, e.g. CLOUDDLEY_PRICING_TTL=12h
+	PricingCacheTTLEnv = "CLOUDDLEY_PRICING_TTL"
+
+	pricingCacheFile = "pricing.json"
+)
+
+// PricingCache stores Pricing API results on disk so repeated commands do not
+// query AWS for prices that rarely change
+type PricingCache struct {
+	// Path is the cache file; an empty path disables the cache
+	Path string
+	// TTL is how long an entry stays fresh
+	TTL time.Duration
+	// Refresh ignores existing entries but still stores new ones
+	Refresh bool
+
+	mu  sync.Mutex
+	now func() time.Time
+}
+
+// pricingCacheEntry is a single cached price
+type pricingCacheEntry struct {
+	Price     float64   `json:"price"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+var (
+	pricingCacheMu     sync.Mutex
+	activePricingCache *PricingCache
+)
+
+// PricingCachePath returns the cache file under the user cache directory
+func PricingCachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user cache directory: %w", err)
+	}
+	return filepath.Join(dir, "clouddley", pricingCacheFile), nil
+}
+
+// PricingCacheTTL returns the TTL from CLOUDDLEY_PRICING_TTL, or the default
+func PricingCacheTTL() (time.Duration, error) {
+	value := os.Getenv(PricingCacheTTLEnv)
+	if value == "" {
+		return DefaultPricingCacheTTL, nil
+	}
+	ttl, err := time.ParseDuration(value)
+	if err != nil || ttl < 0 {
+		return 0, fmt.Errorf("invalid %s %q: use a duration such as 12h", PricingCacheTTLEnv, value)
+	}
+	return ttl, nil
+}
+
+// ConfigurePricingCache sets up the cache used by GetInstancePricing. A zero ttl
+// uses PricingCacheTTL; refresh forces prices to be fetched again
+func ConfigurePricingCache(ttl time.Duration, refresh bool) error {
+	path, err := PricingCachePath()
+	if err != nil {
+		return err
+	}
+	if ttl == 0 {
+		if ttl, err = PricingCacheTTL(); err != nil {
+			return err
+		}
+	}
+
+	pricingCacheMu.Lock()
+	defer pricingCacheMu.Unlock()
+	activePricingCache = &PricingCache{Path: path, TTL: ttl, Refresh: refresh}
+	return nil
+}
+
+// currentPricingCache returns the configured cache, creating a default one on first use
+func currentPricingCache() *PricingCache {
+	pricingCacheMu.Lock()
+	defer pricingCacheMu.Unlock()
+
+	if activePricingCache == nil {
+		cache := &PricingCache{TTL: DefaultPricingCacheTTL}
+		if path, err := PricingCachePath(); err == nil {
+			cache.Path = path
+		}
+		if ttl, err := PricingCacheTTL(); err == nil {
+			cache.TTL = ttl
+		}
+		activePricingCache = cache
+	}
+	return activePricingCache
+}
+
+// ec2PriceCacheKey identifies the on-demand hourly price of an instance type
+func ec2PriceCacheKey(region, instanceType string) string {
+	return strings.Join([]string{"ec2", region, instanceType}, "/")
+}
+
+// reservedPriceCacheKey identifies the effective hourly price of a reserved instance
+func reservedPriceCacheKey(region, instanceType, lease string) string {
+	return strings.Join([]string{"ec2-reserved", region, instanceType, lease}, "/")
+}
+
+// ebsPriceCacheKey identifies an EBS rate, named as in ebsRateComponents
+func ebsPriceCacheKey(region, volumeType string) string {
+	return strings.Join([]string{"ebs", region, volumeType}, "/")
+}
+
+// Get returns a fresh cached price for key
+func (c *PricingCache) Get(key string) (float64, bool) {
+	if c == nil || c.Path == "" || c.Refresh || c.TTL <= 0 {
+		return 0, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries, err := c.load()
+	if err != nil {
+		return 0, false
+	}
+	entry, ok := entries[key]
+	if !ok || c.clock().Sub(entry.FetchedAt) > c.TTL {
+		return 0, false
+	}
+	return entry.Price, true
+}
+
+// Set stores a price for key
+func (c *PricingCache) Set(key string, price float64) error {
+	if c == nil || c.Path == "" {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries, err := c.load()
+	if err != nil {
+		// A corrupt cache is replaced rather than blocking pricing
+		entries = make(map[string]pricingCacheEntry)
+	}
+	entries[key] = pricingCacheEntry{Price: price, FetchedAt: c.clock().UTC()}
+	return c.save(entries)
+}
+
+// Clear deletes the cache file and returns the number of entries it held
+func (c *PricingCache) Clear() (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries, _ := c.load()
+	if err := os.Remove(c.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return 0, fmt.Errorf("failed to remove pricing cache: %w", err)
+	}
+	return len(entries), nil
+}
+
+func (c *PricingCache) clock() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+	return time.Now()
+}
+
+func (c *PricingCache) load() (map[string]pricingCacheEntry, error) {
+	entries := make(map[string]pricingCacheEntry)
+	content, err := os.ReadFile(c.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pricing cache: %w", err)
+	}
+	if err := json.Unmarshal(content, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse pricing cache: %w", err)
+	}
+	return entries, nil
+}
+
+// save writes the cache through a temp file so concurrent readers never see a partial file
+func (c *PricingCache) save(entries map[string]pricingCacheEntry) error {
+	content, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode pricing cache: %w", err)
+	}
+
+	dir := filepath.Dir(c.Path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, pricingCacheFile+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write pricing cache: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write pricing cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write pricing cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.Path); err != nil {
+		return fmt.Errorf("failed to write pricing cache: %w", err)
+	}
+	return nil
+}
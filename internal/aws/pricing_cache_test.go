@@ -0,0 +1,163 @@
+package aws
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestPricingCache(t *testing.T, now *time.Time) *PricingCache {
+	t.Helper()
+	return &PricingCache{
+		Path: filepath.Join(t.TempDir(), "clouddley", pricingCacheFile),
+		TTL:  time.Hour,
+		now:  func() time.Time { return *now },
+	}
+}
+
+func TestPricingCache_GetSet(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	cache := newTestPricingCache(t, &now)
+	key := ec2PriceCacheKey("us-east-1", "t3.micro")
+
+	if _, ok := cache.Get(key); ok {
+		t.Fatal("Get() on empty cache returned a price")
+	}
+
+	if err := cache.Set(key, 0.0104); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if price, ok := cache.Get(key); !ok || price != 0.0104 {
+		t.Errorf("Get() = %v, %v, want 0.0104, true", price, ok)
+	}
+
+	// Keys differ by region and volume type
+	if _, ok := cache.Get(ec2PriceCacheKey("eu-west-1", "t3.micro")); ok {
+		t.Error("Get() returned a price for another region")
+	}
+	if ebsPriceCacheKey("us-east-1", "gp3") == ebsPriceCacheKey("us-east-1", "gp2") {
+		t.Error("EBS cache keys do not include the volume type")
+	}
+
+	// Entries are shared through the file
+	reopened := &PricingCache{Path: cache.Path, TTL: time.Hour, now: cache.now}
+	if _, ok := reopened.Get(key); !ok {
+		t.Error("Get() on a second cache did not see the stored price")
+	}
+}
+
+func TestPricingCache_Expiry(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	cache := newTestPricingCache(t, &now)
+	key := ebsPriceCacheKey("us-east-1", "gp3")
+
+	if err := cache.Set(key, 0.08); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	now = now.Add(59 * time.Minute)
+	if _, ok := cache.Get(key); !ok {
+		t.Error("Get() missed an entry within the TTL")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, ok := cache.Get(key); ok {
+		t.Error("Get() returned an expired entry")
+	}
+}
+
+func TestPricingCache_Refresh(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	cache := newTestPricingCache(t, &now)
+	key := ec2PriceCacheKey("us-east-1", "m5.large")
+
+	if err := cache.Set(key, 0.096); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	cache.Refresh = true
+	if _, ok := cache.Get(key); ok {
+		t.Error("Get() with Refresh returned a cached price")
+	}
+
+	// Refreshed prices are still written for the next run
+	if err := cache.Set(key, 0.1); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	cache.Refresh = false
+	if price, _ := cache.Get(key); price != 0.1 {
+		t.Errorf("Get() = %v, want refreshed price 0.1", price)
+	}
+}
+
+func TestPricingCache_CorruptFile(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	cache := newTestPricingCache(t, &now)
+	key := ec2PriceCacheKey("us-east-1", "t3.small")
+
+	if err := os.MkdirAll(filepath.Dir(cache.Path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(cache.Path, []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := cache.Get(key); ok {
+		t.Error("Get() returned a price from a corrupt cache")
+	}
+	if err := cache.Set(key, 0.0208); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if _, ok := cache.Get(key); !ok {
+		t.Error("Set() did not replace the corrupt cache")
+	}
+}
+
+func TestPricingCache_Clear(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	cache := newTestPricingCache(t, &now)
+
+	removed, err := cache.Clear()
+	if err != nil || removed != 0 {
+		t.Fatalf("Clear() on missing cache = %d, %v, want 0, nil", removed, err)
+	}
+
+	cache.Set(ec2PriceCacheKey("us-east-1", "t3.micro"), 0.0104)
+	cache.Set(ebsPriceCacheKey("us-east-1", "gp3"), 0.08)
+
+	removed, err = cache.Clear()
+	if err != nil || removed != 2 {
+		t.Fatalf("Clear() = %d, %v, want 2, nil", removed, err)
+	}
+	if _, err := os.Stat(cache.Path); !os.IsNotExist(err) {
+		t.Errorf("cache file still exists after Clear(): %v", err)
+	}
+}
+
+func TestPricingCacheTTL(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "", want: DefaultPricingCacheTTL},
+		{value: "12h", want: 12 * time.Hour},
+		{value: "0s", want: 0},
+		{value: "soon", wantErr: true},
+		{value: "-1h", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv(PricingCacheTTLEnv, tt.value)
+			got, err := PricingCacheTTL()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PricingCacheTTL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("PricingCacheTTL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/pricing"
+	"github.com/aws/aws-sdk-go-v2/service/pricing/types"
+)
+
+// getProductsInRegion returns the AmazonEC2 price list items matching filters in a
+// region. Products are matched on their regionCode attribute, which the Pricing API
+// publishes for every region including new ones. The static location map is only a
+// fallback for products published without regionCode
+func getProductsInRegion(ctx context.Context, client pricing.GetProductsAPIClient, region string, maxResults int32, filters ...types.Filter) ([]string, error) {
+	priceList, err := getAllProducts(ctx, client, maxResults, append(filters, termMatch("regionCode", region)))
+	if err != nil || len(priceList) > 0 {
+		return priceList, err
+	}
+
+	location, ok := knownLocationName(region)
+	if !ok {
+		return nil, nil
+	}
+	return getAllProducts(ctx, client, maxResults, append(filters, termMatch("location", location)))
+}
+
+// getAllProducts pages through a GetProducts query
+func getAllProducts(ctx context.Context, client pricing.GetProductsAPIClient, maxResults int32, filters []types.Filter) ([]string, error) {
+	input := &pricing.GetProductsInput{
+		ServiceCode: aws.String("AmazonEC2"),
+		Filters:     filters,
+		MaxResults:  aws.Int32(maxResults),
+	}
+
+	var priceList []string
+	paginator := pricing.NewGetProductsPaginator(client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get products: %w", err)
+		}
+		priceList = append(priceList, page.PriceList...)
+	}
+	return priceList, nil
+}
+
+// termMatch is an exact match filter on a product attribute
+func termMatch(field, value string) types.Filter {
+	return types.Filter{
+		Type:  types.FilterTypeTermMatch,
+		Field: aws.String(field),
+		Value: aws.String(value),
+	}
+}
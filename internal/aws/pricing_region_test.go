@@ -0,0 +1,92 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/pricing"
+)
+
+// fakeProductsClient returns canned price lists keyed by the value of one filter field
+type fakeProductsClient struct {
+	field   string
+	results map[string][]string
+	err     error
+	queries []string
+}
+
+func (f *fakeProductsClient) GetProducts(ctx context.Context, params *pricing.GetProductsInput, optFns ...func(*pricing.Options)) (*pricing.GetProductsOutput, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	for _, filter := range params.Filters {
+		field := aws.ToString(filter.Field)
+		if field == "regionCode" || field == "location" {
+			value := aws.ToString(filter.Value)
+			f.queries = append(f.queries, field+"="+value)
+			return &pricing.GetProductsOutput{PriceList: f.results[field+"="+value]}, nil
+		}
+	}
+	return &pricing.GetProductsOutput{}, nil
+}
+
+func TestGetProductsInRegion(t *testing.T) {
+	tests := []struct {
+		name        string
+		region      string
+		results     map[string][]string
+		err         error
+		wantItems   int
+		wantQueries []string
+		wantErr     bool
+	}{
+		{
+			name:        "new region by regionCode",
+			region:      "ca-west-1",
+			results:     map[string][]string{"regionCode=ca-west-1": {"{}"}},
+			wantItems:   1,
+			wantQueries: []string{"regionCode=ca-west-1"},
+		},
+		{
+			name:        "falls back to location name",
+			region:      "us-east-1",
+			results:     map[string][]string{"location=US East (N. Virginia)": {"{}", "{}"}},
+			wantItems:   2,
+			wantQueries: []string{"regionCode=us-east-1", "location=US East (N. Virginia)"},
+		},
+		{
+			name:        "unknown region has no fallback",
+			region:      "xx-nowhere-1",
+			wantQueries: []string{"regionCode=xx-nowhere-1"},
+		},
+		{
+			name:    "api error",
+			region:  "us-east-1",
+			err:     errors.New("AccessDeniedException"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeProductsClient{results: tt.results, err: tt.err}
+			items, err := getProductsInRegion(context.Background(), client, tt.region, 10, termMatch("instanceType", "t3.micro"))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getProductsInRegion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(items) != tt.wantItems {
+				t.Errorf("getProductsInRegion() returned %d items, want %d", len(items), tt.wantItems)
+			}
+			if len(client.queries) != len(tt.wantQueries) {
+				t.Fatalf("queries = %v, want %v", client.queries, tt.wantQueries)
+			}
+			for i := range tt.wantQueries {
+				if client.queries[i] != tt.wantQueries[i] {
+					t.Errorf("query %d = %q, want %q", i, client.queries[i], tt.wantQueries[i])
+				}
+			}
+		})
+	}
+}
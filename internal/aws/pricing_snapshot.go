@@ -0,0 +1,168 @@
+package aws
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+//go:generate go run ../.. pricing snapshot --output pricing_snapshot.json
+
+//go:embed pricing_snapshot.json
+var pricingSnapshotJSON []byte
+
+// SnapshotRegions are the regions included in the embedded pricing snapshot
+var SnapshotRegions = []string{"us-east-1", "us-east-2", "us-west-2", "eu-west-1", "eu-central-1", "ap-southeast-1"}
+
+// SnapshotInstanceTypes are the instance types included in the embedded pricing snapshot
+var SnapshotInstanceTypes = []string{
+	"t3.nano", "t3.micro", "t3.small", "t3.medium", "t3.large", "t3.xlarge", "t3.2xlarge",
+	"m5.large", "m5.xlarge", "m5.2xlarge",
+	"c5.large", "c5.xlarge", "c5.2xlarge",
+}
+
+// SnapshotVolumeTypes are the EBS volume types included in the embedded pricing snapshot
+var SnapshotVolumeTypes = EBSVolumeTypes()
+
+// PricingSnapshot is a point-in-time copy of on-demand prices used when the
+// Pricing API cannot be reached. Prices from it are estimates
+type PricingSnapshot struct {
+	// GeneratedAt is the day the prices were fetched, as YYYY-MM-DD
+	GeneratedAt string `json:"generated_at"`
+	// EC2 maps region to instance type to the Linux on-demand price in USD per hour
+	EC2 map[string]map[string]float64 `json:"ec2"`
+	// EBS maps region to rate to its USD price: "<type>" per GB-month, "<type>/iops"
+	// per IOPS-month and "gp3/throughput" per MiB/s-month
+	EBS map[string]map[string]float64 `json:"ebs"`
+}
+
+var (
+	embeddedSnapshotOnce sync.Once
+	embeddedSnapshot     *PricingSnapshot
+)
+
+// EmbeddedPricingSnapshot returns the snapshot compiled into the binary
+func EmbeddedPricingSnapshot() *PricingSnapshot {
+	embeddedSnapshotOnce.Do(func() {
+		snapshot, err := ParsePricingSnapshot(pricingSnapshotJSON)
+		if err != nil {
+			// The generator and tests guarantee a valid file; fall back to no prices
+			snapshot = &PricingSnapshot{}
+		}
+		embeddedSnapshot = snapshot
+	})
+	return embeddedSnapshot
+}
+
+// ParsePricingSnapshot decodes and validates a snapshot
+func ParsePricingSnapshot(content []byte) (*PricingSnapshot, error) {
+	var snapshot PricingSnapshot
+	if err := json.Unmarshal(content, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse pricing snapshot: %w", err)
+	}
+	if _, err := time.Parse(time.DateOnly, snapshot.GeneratedAt); err != nil {
+		return nil, fmt.Errorf("invalid pricing snapshot date %q: %w", snapshot.GeneratedAt, err)
+	}
+	return &snapshot, nil
+}
+
+// EC2Price returns the hourly on-demand price of an instance type in a region
+func (s *PricingSnapshot) EC2Price(region, instanceType string) (float64, bool) {
+	price, ok := s.EC2[region][instanceType]
+	return price, ok
+}
+
+// EBSPrice returns an EBS rate in a region, named as in ebsRateComponents
+func (s *PricingSnapshot) EBSPrice(region, component string) (float64, bool) {
+	price, ok := s.EBS[region][component]
+	return price, ok
+}
+
+// MarshalIndent encodes the snapshot the way it is stored in the repository
+func (s *PricingSnapshot) MarshalIndent() ([]byte, error) {
+	content, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode pricing snapshot: %w", err)
+	}
+	return append(content, '\n'), nil
+}
+
+// GeneratePricingSnapshot fetches current prices from the Pricing API for every
+// snapshot region, instance type and volume type. Any missing price is an error
+// so a partial snapshot is never committed
+func GeneratePricingSnapshot(ctx context.Context, parallel int) (*PricingSnapshot, error) {
+	pricer, err := NewPricer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	type job struct {
+		region, instanceType, volumeType string
+	}
+	var jobs []job
+	for _, region := range SnapshotRegions {
+		for _, instanceType := range SnapshotInstanceTypes {
+			jobs = append(jobs, job{region: region, instanceType: instanceType})
+		}
+		for _, volumeType := range SnapshotVolumeTypes {
+			jobs = append(jobs, job{region: region, volumeType: volumeType})
+		}
+	}
+
+	ec2Prices := make([]float64, len(jobs))
+	ebsRates := make([]map[string]float64, len(jobs))
+	errs := make([]error, len(jobs))
+	ForEachLimited(len(jobs), parallel, func(i int) {
+		j := jobs[i]
+		if j.instanceType != "" {
+			ec2Prices[i], errs[i] = getEC2OnDemandPrice(ctx, pricer.client, j.instanceType, j.region)
+			return
+		}
+		ebsRates[i], errs[i] = getEBSRates(ctx, pricer.client, j.region, j.volumeType)
+		if errs[i] != nil {
+			return
+		}
+		for _, component := range ebsRateComponents(j.volumeType) {
+			if _, ok := ebsRates[i][component]; !ok {
+				errs[i] = fmt.Errorf("no %s rate", component)
+				return
+			}
+		}
+	})
+
+	snapshot := &PricingSnapshot{
+		GeneratedAt: time.Now().UTC().Format(time.DateOnly),
+		EC2:         make(map[string]map[string]float64),
+		EBS:         make(map[string]map[string]float64),
+	}
+	var failed []string
+	for i, j := range jobs {
+		if errs[i] != nil {
+			failed = append(failed, fmt.Sprintf("%s %s%s: %v", j.region, j.instanceType, j.volumeType, errs[i]))
+			continue
+		}
+		if j.instanceType != "" {
+			if snapshot.EC2[j.region] == nil {
+				snapshot.EC2[j.region] = make(map[string]float64)
+			}
+			snapshot.EC2[j.region][j.instanceType] = ec2Prices[i]
+			continue
+		}
+		if snapshot.EBS[j.region] == nil {
+			snapshot.EBS[j.region] = make(map[string]float64)
+		}
+		for component, price := range ebsRates[i] {
+			snapshot.EBS[j.region][component] = price
+		}
+	}
+
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return nil, fmt.Errorf("failed to fetch %d of %d prices, first: %s", len(failed), len(jobs), failed[0])
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,111 @@
+package aws
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEmbeddedPricingSnapshot(t *testing.T) {
+	snapshot, err := ParsePricingSnapshot(pricingSnapshotJSON)
+	if err != nil {
+		t.Fatalf("embedded snapshot is invalid: %v", err)
+	}
+
+	// Every region the generator covers must be complete
+	for _, region := range SnapshotRegions {
+		for _, instanceType := range SnapshotInstanceTypes {
+			if price, ok := snapshot.EC2Price(region, instanceType); !ok || price <= 0 {
+				t.Errorf("missing EC2 price for %s in %s", instanceType, region)
+			}
+		}
+		for _, volumeType := range SnapshotVolumeTypes {
+			for _, component := range ebsRateComponents(volumeType) {
+				if price, ok := snapshot.EBSPrice(region, component); !ok || price <= 0 {
+					t.Errorf("missing EBS price for %s in %s", component, region)
+				}
+			}
+		}
+	}
+
+	if EmbeddedPricingSnapshot().GeneratedAt != snapshot.GeneratedAt {
+		t.Error("EmbeddedPricingSnapshot() does not match the embedded file")
+	}
+}
+
+func TestParsePricingSnapshot(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{name: "valid", content: `{"generated_at":"2025-06-01","ec2":{"us-east-1":{"t3.micro":0.0104}},"ebs":{}}`},
+		{name: "missing date", content: `{"ec2":{}}`, wantErr: true},
+		{name: "bad date", content: `{"generated_at":"June 2025"}`, wantErr: true},
+		{name: "not json", content: `{`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParsePricingSnapshot([]byte(tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParsePricingSnapshot() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPricingSnapshot_RoundTrip(t *testing.T) {
+	snapshot := &PricingSnapshot{
+		GeneratedAt: "2025-06-01",
+		EC2:         map[string]map[string]float64{"us-east-1": {"t3.micro": 0.0104}},
+		EBS:         map[string]map[string]float64{"us-east-1": {"gp3": 0.08}},
+	}
+	content, err := snapshot.MarshalIndent()
+	if err != nil {
+		t.Fatalf("MarshalIndent() error = %v", err)
+	}
+	parsed, err := ParsePricingSnapshot(content)
+	if err != nil {
+		t.Fatalf("ParsePricingSnapshot() error = %v", err)
+	}
+	if price, _ := parsed.EC2Price("us-east-1", "t3.micro"); price != 0.0104 {
+		t.Errorf("EC2Price() = %v, want 0.0104", price)
+	}
+	if _, ok := parsed.EBSPrice("eu-west-1", "gp3"); ok {
+		t.Error("EBSPrice() found a price for a region not in the snapshot")
+	}
+}
+
+func TestPriceWithFallback(t *testing.T) {
+	apiErr := errors.New("AccessDeniedException: not authorized to perform pricing:GetProducts")
+	tests := []struct {
+		name          string
+		fetchPrice    float64
+		fetchErr      error
+		snapshotPrice float64
+		inSnapshot    bool
+		wantPrice     float64
+		wantEstimated bool
+		wantErr       bool
+	}{
+		{name: "live price", fetchPrice: 0.0104, snapshotPrice: 0.01, inSnapshot: true, wantPrice: 0.0104},
+		{name: "api failure uses snapshot", fetchErr: apiErr, snapshotPrice: 0.01, inSnapshot: true, wantPrice: 0.01, wantEstimated: true},
+		{name: "api failure without snapshot", fetchErr: apiErr, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price, estimated, err := priceWithFallback(nil, "ec2/us-east-1/t3.micro", func() (float64, error) {
+				return tt.fetchPrice, tt.fetchErr
+			}, func() (float64, bool) {
+				return tt.snapshotPrice, tt.inSnapshot
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("priceWithFallback() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if price != tt.wantPrice || estimated != tt.wantEstimated {
+				t.Errorf("priceWithFallback() = %v, %v, want %v, %v", price, estimated, tt.wantPrice, tt.wantEstimated)
+			}
+		})
+	}
+}
@@ -3,36 +3,11 @@ package aws
 import (
 	"fmt"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
-func TestGetLocationName(t *testing.T) {
-	tests := []struct {
-		region   string
-		expected string
-	}{
-		{"us-east-1", "US East (N. Virginia)"},
-		{"us-east-2", "US East (Ohio)"},
-		{"us-west-1", "US West (N. California)"},
-		{"us-west-2", "US West (Oregon)"},
-		{"ap-southeast-1", "Asia Pacific (Singapore)"},
-		{"eu-west-1", "Europe (Ireland)"},
-		{"ca-central-1", "Canada (Central)"},
-		{"eu-central-1", "Europe (Frankfurt)"},
-		{"ap-northeast-1", "Asia Pacific (Tokyo)"},
-		{"unknown-region", "unknown-region"}, // Should return the region itself
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.region, func(t *testing.T) {
-			result := getLocationName(tt.region)
-			if result != tt.expected {
-				t.Errorf("Expected %s, got %s", tt.expected, result)
-			}
-		})
-	}
-}
-
 func TestPricingInfo_Structure(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -140,37 +115,6 @@ func TestPricingInfo_FormattingEdgeCases(t *testing.T) {
 	}
 }
 
-func TestRegionLocationMapping_Coverage(t *testing.T) {
-	// Test that all major regions have location mappings
-	regions := []string{
-		"us-east-1", "us-east-2", "us-west-1", "us-west-2",
-		"ap-southeast-1", "ap-southeast-2", "ap-northeast-1", "ap-northeast-2",
-		"eu-west-1", "eu-west-2", "eu-central-1",
-		"ca-central-1", "sa-east-1",
-	}
-	
-	for _, region := range regions {
-		t.Run(region, func(t *testing.T) {
-			location := getLocationName(region)
-			
-			// Should not return the region itself for known regions
-			if location == region {
-				t.Errorf("Region %s should have a location mapping, but returned itself", region)
-			}
-			
-			// Should contain meaningful location information
-			if location == "" {
-				t.Errorf("Region %s returned empty location", region)
-			}
-			
-			// Should not contain "unknown" for known regions
-			if strings.Contains(strings.ToLower(location), "unknown") {
-				t.Errorf("Region %s returned location with 'unknown': %s", region, location)
-			}
-		})
-	}
-}
-
 func TestPricingInfo_ValidateFields(t *testing.T) {
 	// Test that PricingInfo struct has all expected fields and types
 	info := PricingInfo{
@@ -245,3 +189,46 @@ func TestMonthlyCalculation(t *testing.T) {
 		})
 	}
 }
+
+func TestForEachLimited(t *testing.T) {
+	tests := []struct {
+		name     string
+		n        int
+		parallel int
+		wantMax  int32
+	}{
+		{name: "bounded", n: 10, parallel: 3, wantMax: 3},
+		{name: "fewer items than slots", n: 2, parallel: 8, wantMax: 2},
+		{name: "zero parallelism runs serially", n: 3, parallel: 0, wantMax: 1},
+		{name: "no items", n: 0, parallel: 4, wantMax: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var running, maxRunning int32
+			done := make([]bool, tt.n)
+
+			ForEachLimited(tt.n, tt.parallel, func(i int) {
+				n := atomic.AddInt32(&running, 1)
+				defer atomic.AddInt32(&running, -1)
+				for {
+					m := atomic.LoadInt32(&maxRunning)
+					if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+						break
+					}
+				}
+				time.Sleep(5 * time.Millisecond)
+				done[i] = true
+			})
+
+			for i, ok := range done {
+				if !ok {
+					t.Errorf("index %d was not processed", i)
+				}
+			}
+			if maxRunning > tt.wantMax {
+				t.Errorf("max concurrent calls = %d, want at most %d", maxRunning, tt.wantMax)
+			}
+		})
+	}
+}
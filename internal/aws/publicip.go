@@ -0,0 +1,55 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// publicIPURL is the AWS endpoint that echoes the caller's public IPv4 address
+var publicIPURL = "https://checkip.amazonaws.com"
+
+// DetectPublicIP returns the public IP address the caller's traffic originates from
+func DetectPublicIP(ctx context.Context) (net.IP, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, publicIPURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build public IP request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect public IP: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to detect public IP: unexpected status %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public IP response: %w", err)
+	}
+
+	ip := net.ParseIP(strings.TrimSpace(string(body)))
+	if ip == nil {
+		return nil, fmt.Errorf("failed to parse public IP %q", strings.TrimSpace(string(body)))
+	}
+
+	return ip, nil
+}
+
+// HostCIDR returns the single-host CIDR for an IP (/32 for IPv4, /128 for IPv6)
+func HostCIDR(ip net.IP) string {
+	if ip.To4() != nil {
+		return ip.String() + "/32"
+	}
+	return ip.String() + "/128"
+}
@@ -0,0 +1,62 @@
+package aws
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDetectPublicIP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("203.0.113.10\n"))
+	}))
+	defer server.Close()
+
+	original := publicIPURL
+	publicIPURL = server.URL
+	defer func() { publicIPURL = original }()
+
+	ip, err := DetectPublicIP(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if ip.String() != "203.0.113.10" {
+		t.Errorf("Expected 203.0.113.10, got %s", ip)
+	}
+}
+
+func TestDetectPublicIP_InvalidResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html>blocked</html>"))
+	}))
+	defer server.Close()
+
+	original := publicIPURL
+	publicIPURL = server.URL
+	defer func() { publicIPURL = original }()
+
+	if _, err := DetectPublicIP(context.Background()); err == nil {
+		t.Fatal("Expected error for invalid response, got nil")
+	}
+}
+
+func TestHostCIDR(t *testing.T) {
+	tests := []struct {
+		ip       string
+		expected string
+	}{
+		{"203.0.113.10", "203.0.113.10/32"},
+		{"2001:db8::1", "2001:db8::1/128"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			if result := HostCIDR(net.ParseIP(tt.ip)); result != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, result)
+			}
+		})
+	}
+}
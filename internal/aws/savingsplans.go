@@ -0,0 +1,114 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/savingsplans"
+	"github.com/aws/aws-sdk-go-v2/service/savingsplans/types"
+	"github.com/clouddley/clouddley/internal/log"
+)
+
+// Savings plan terms, as durations in seconds
+const (
+	SavingsPlanTerm1Year = 31536000
+	SavingsPlanTerm3Year = 94608000
+)
+
+// SavingsPlanRatesClient is the part of the Savings Plans API needed to look up rates
+type SavingsPlanRatesClient interface {
+	DescribeSavingsPlansOfferingRates(ctx context.Context, params *savingsplans.DescribeSavingsPlansOfferingRatesInput, optFns ...func(*savingsplans.Options)) (*savingsplans.DescribeSavingsPlansOfferingRatesOutput, error)
+}
+
+// SavingsPlanRates returns the hourly Compute Savings Plan (no upfront) rate of a
+// shared tenancy Linux instance, keyed by term in seconds
+func (p *Pricer) SavingsPlanRates(ctx context.Context, instanceType string) (map[int64]float64, error) {
+	rates := make(map[int64]float64)
+	missing := false
+	for _, term := range []int64{SavingsPlanTerm1Year, SavingsPlanTerm3Year} {
+		price, ok := p.cache.Get(savingsPlanCacheKey(p.Region, instanceType, term))
+		if !ok {
+			missing = true
+			break
+		}
+		rates[term] = price
+	}
+	if !missing {
+		return rates, nil
+	}
+
+	rates, err := describeSavingsPlanRates(ctx, p.savingsPlans, p.Region, instanceType)
+	if err != nil {
+		return nil, err
+	}
+
+	for term, price := range rates {
+		if err := p.cache.Set(savingsPlanCacheKey(p.Region, instanceType, term), price); err != nil {
+			log.Debug("Failed to update pricing cache", "error", err)
+		}
+	}
+	return rates, nil
+}
+
+// savingsPlanCacheKey identifies the hourly Compute Savings Plan rate of an instance type
+func savingsPlanCacheKey(region, instanceType string, term int64) string {
+	return strings.Join([]string{"ec2-savings-plan", region, instanceType, strconv.FormatInt(term, 10)}, "/")
+}
+
+// describeSavingsPlanRates calls DescribeSavingsPlansOfferingRates for the Compute
+// Savings Plan rates of a shared tenancy Linux instance, following every page
+func describeSavingsPlanRates(ctx context.Context, client SavingsPlanRatesClient, region, instanceType string) (map[int64]float64, error) {
+	input := &savingsplans.DescribeSavingsPlansOfferingRatesInput{
+		Products:                  []types.SavingsPlanProductType{types.SavingsPlanProductTypeEc2},
+		ServiceCodes:              []types.SavingsPlanRateServiceCode{types.SavingsPlanRateServiceCodeEc2},
+		SavingsPlanTypes:          []types.SavingsPlanType{types.SavingsPlanTypeCompute},
+		SavingsPlanPaymentOptions: []types.SavingsPlanPaymentOption{types.SavingsPlanPaymentOptionNoUpfront},
+		Filters: []types.SavingsPlanOfferingRateFilterElement{
+			{Name: types.SavingsPlanRateFilterAttributeRegion, Values: []string{region}},
+			{Name: types.SavingsPlanRateFilterAttributeInstanceType, Values: []string{instanceType}},
+			{Name: types.SavingsPlanRateFilterAttributeProductDescription, Values: []string{"Linux/UNIX"}},
+			{Name: types.SavingsPlanRateFilterAttributeTenancy, Values: []string{"shared"}},
+		},
+	}
+
+	rates := make(map[int64]float64)
+	for {
+		page, err := client.DescribeSavingsPlansOfferingRates(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe savings plan rates: %w", err)
+		}
+		collectSavingsPlanRates(page.SearchResults, rates)
+
+		if aws.ToString(page.NextToken) == "" {
+			break
+		}
+		input.NextToken = page.NextToken
+	}
+
+	if len(rates) == 0 {
+		return nil, fmt.Errorf("no savings plan rates found for %s in %s", instanceType, region)
+	}
+	return rates, nil
+}
+
+// collectSavingsPlanRates keeps the on-demand usage rates (BoxUsage), skipping
+// dedicated, host and reservation usage that share the filters
+func collectSavingsPlanRates(results []types.SavingsPlanOfferingRate, rates map[int64]float64) {
+	for _, result := range results {
+		if result.SavingsPlanOffering == nil || string(result.Unit) != priceUnitHours ||
+			result.SavingsPlanOffering.Currency != types.CurrencyCodeUsd || aws.ToString(result.Operation) != "RunInstances" {
+			continue
+		}
+		if !strings.Contains(aws.ToString(result.UsageType), "BoxUsage:") {
+			continue
+		}
+		price, err := strconv.ParseFloat(aws.ToString(result.Rate), 64)
+		if err != nil {
+			continue
+		}
+		rates[result.SavingsPlanOffering.DurationSeconds] = price
+	}
+}
@@ -0,0 +1,97 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/savingsplans"
+	"github.com/aws/aws-sdk-go-v2/service/savingsplans/types"
+)
+
+// mockSavingsPlanRatesClient returns one page per call and records the requests
+type mockSavingsPlanRatesClient struct {
+	pages    []*savingsplans.DescribeSavingsPlansOfferingRatesOutput
+	err      error
+	requests []savingsplans.DescribeSavingsPlansOfferingRatesInput
+}
+
+func (m *mockSavingsPlanRatesClient) DescribeSavingsPlansOfferingRates(ctx context.Context, params *savingsplans.DescribeSavingsPlansOfferingRatesInput, optFns ...func(*savingsplans.Options)) (*savingsplans.DescribeSavingsPlansOfferingRatesOutput, error) {
+	m.requests = append(m.requests, *params)
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.pages[len(m.requests)-1], nil
+}
+
+func savingsPlanRate(term int64, rate, usageType, operation string) types.SavingsPlanOfferingRate {
+	return types.SavingsPlanOfferingRate{
+		SavingsPlanOffering: &types.ParentSavingsPlanOffering{DurationSeconds: term, Currency: types.CurrencyCodeUsd},
+		Rate:                aws.String(rate),
+		Unit:                types.SavingsPlanRateUnitHours,
+		UsageType:           aws.String(usageType),
+		Operation:           aws.String(operation),
+	}
+}
+
+func TestDescribeSavingsPlanRates(t *testing.T) {
+	client := &mockSavingsPlanRatesClient{pages: []*savingsplans.DescribeSavingsPlansOfferingRatesOutput{
+		{
+			SearchResults: []types.SavingsPlanOfferingRate{
+				savingsPlanRate(SavingsPlanTerm1Year, "0.0075", "BoxUsage:t3.micro", "RunInstances"),
+				savingsPlanRate(SavingsPlanTerm1Year, "0.0090", "DedicatedUsage:t3.micro", "RunInstances"),
+			},
+			NextToken: aws.String("page-2"),
+		},
+		{
+			SearchResults: []types.SavingsPlanOfferingRate{
+				savingsPlanRate(SavingsPlanTerm3Year, "0.0052", "USE2-BoxUsage:t3.micro", "RunInstances"),
+				savingsPlanRate(SavingsPlanTerm3Year, "0.0100", "BoxUsage:t3.micro", "RunInstances:0002"),
+			},
+		},
+	}}
+
+	rates, err := describeSavingsPlanRates(context.Background(), client, "us-east-2", "t3.micro")
+	if err != nil {
+		t.Fatalf("describeSavingsPlanRates() error = %v", err)
+	}
+
+	want := map[int64]float64{SavingsPlanTerm1Year: 0.0075, SavingsPlanTerm3Year: 0.0052}
+	if len(rates) != len(want) {
+		t.Fatalf("describeSavingsPlanRates() = %v, want %v", rates, want)
+	}
+	for term, price := range want {
+		if rates[term] != price {
+			t.Errorf("rate for term %d = %v, want %v", term, rates[term], price)
+		}
+	}
+
+	if len(client.requests) != 2 {
+		t.Fatalf("sent %d requests, want 2", len(client.requests))
+	}
+	if aws.ToString(client.requests[1].NextToken) != "page-2" {
+		t.Errorf("second request token = %q, want page-2", aws.ToString(client.requests[1].NextToken))
+	}
+	if filter := client.requests[0].Filters[0]; filter.Name != types.SavingsPlanRateFilterAttributeRegion || filter.Values[0] != "us-east-2" {
+		t.Errorf("region filter = %+v, want us-east-2", filter)
+	}
+}
+
+func TestDescribeSavingsPlanRates_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		client *mockSavingsPlanRatesClient
+	}{
+		{name: "access denied", client: &mockSavingsPlanRatesClient{err: errors.New("AccessDeniedException: not authorized")}},
+		{name: "no rates", client: &mockSavingsPlanRatesClient{pages: []*savingsplans.DescribeSavingsPlansOfferingRatesOutput{{}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := describeSavingsPlanRates(context.Background(), tt.client, "us-east-1", "t3.micro"); err == nil {
+				t.Error("describeSavingsPlanRates() error = nil, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// SpotPriceInfo holds the current spot price for an instance type
+type SpotPriceInfo struct {
+	InstanceType     string
+	AvailabilityZone string
+	HourlyPrice      float64
+}
+
+// GetSpotPrice returns the lowest current Linux spot price for an instance type
+// across the availability zones of the configured region
+func GetSpotPrice(ctx context.Context, instanceType string) (*SpotPriceInfo, error) {
+	client, err := GetEC2Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return GetSpotPriceWithClient(ctx, client, instanceType)
+}
+
+// SpotPriceHistoryClient is the part of the EC2 API needed to look up spot prices
+type SpotPriceHistoryClient interface {
+	DescribeSpotPriceHistory(ctx context.Context, params *ec2.DescribeSpotPriceHistoryInput, optFns ...func(*ec2.Options)) (*ec2.DescribeSpotPriceHistoryOutput, error)
+}
+
+// GetSpotPriceWithClient is GetSpotPrice using an existing EC2 client, so callers
+// pricing several instance types can share one
+func GetSpotPriceWithClient(ctx context.Context, client SpotPriceHistoryClient, instanceType string) (*SpotPriceInfo, error) {
+	result, err := client.DescribeSpotPriceHistory(ctx, &ec2.DescribeSpotPriceHistoryInput{
+		InstanceTypes:       []types.InstanceType{types.InstanceType(instanceType)},
+		ProductDescriptions: []string{"Linux/UNIX"},
+		StartTime:           aws.Time(time.Now()),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe spot price history: %w", err)
+	}
+
+	return lowestSpotPrice(instanceType, result.SpotPriceHistory)
+}
+
+// GetZoneSpotPriceWithClient returns the current Linux spot price for an instance
+// type in one availability zone, which is what a running spot instance there pays
+func GetZoneSpotPriceWithClient(ctx context.Context, client SpotPriceHistoryClient, instanceType, zone string) (*SpotPriceInfo, error) {
+	result, err := client.DescribeSpotPriceHistory(ctx, &ec2.DescribeSpotPriceHistoryInput{
+		InstanceTypes:       []types.InstanceType{types.InstanceType(instanceType)},
+		ProductDescriptions: []string{"Linux/UNIX"},
+		AvailabilityZone:    aws.String(zone),
+		StartTime:           aws.Time(time.Now()),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe spot price history: %w", err)
+	}
+
+	var history []types.SpotPrice
+	for _, entry := range result.SpotPriceHistory {
+		if aws.ToString(entry.AvailabilityZone) == zone {
+			history = append(history, entry)
+		}
+	}
+	return lowestSpotPrice(instanceType, history)
+}
+
+// lowestSpotPrice keeps the most recent price per availability zone and returns the cheapest one
+func lowestSpotPrice(instanceType string, history []types.SpotPrice) (*SpotPriceInfo, error) {
+	type zonePrice struct {
+		price     float64
+		timestamp time.Time
+	}
+	latest := make(map[string]zonePrice)
+
+	for _, entry := range history {
+		if entry.AvailabilityZone == nil || entry.SpotPrice == nil {
+			continue
+		}
+
+		price, err := strconv.ParseFloat(*entry.SpotPrice, 64)
+		if err != nil {
+			continue
+		}
+
+		var ts time.Time
+		if entry.Timestamp != nil {
+			ts = *entry.Timestamp
+		}
+
+		current, ok := latest[*entry.AvailabilityZone]
+		if !ok || ts.After(current.timestamp) {
+			latest[*entry.AvailabilityZone] = zonePrice{price: price, timestamp: ts}
+		}
+	}
+
+	var best *SpotPriceInfo
+	for az, zp := range latest {
+		if best == nil || zp.price < best.HourlyPrice || (zp.price == best.HourlyPrice && az < best.AvailabilityZone) {
+			best = &SpotPriceInfo{
+				InstanceType:     instanceType,
+				AvailabilityZone: az,
+				HourlyPrice:      zp.price,
+			}
+		}
+	}
+
+	if best == nil {
+		return nil, fmt.Errorf("no spot price found for instance type %s", instanceType)
+	}
+
+	return best, nil
+}
@@ -0,0 +1,46 @@
+package aws
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+func TestLowestSpotPrice(t *testing.T) {
+	now := time.Now()
+	earlier := now.Add(-time.Hour)
+
+	history := []types.SpotPrice{
+		{AvailabilityZone: aws.String("us-east-1a"), SpotPrice: aws.String("0.0040"), Timestamp: aws.Time(now)},
+		// Older, cheaper price in the same zone should be ignored
+		{AvailabilityZone: aws.String("us-east-1a"), SpotPrice: aws.String("0.0010"), Timestamp: aws.Time(earlier)},
+		{AvailabilityZone: aws.String("us-east-1b"), SpotPrice: aws.String("0.0035"), Timestamp: aws.Time(now)},
+		{AvailabilityZone: aws.String("us-east-1c"), SpotPrice: aws.String("invalid"), Timestamp: aws.Time(now)},
+	}
+
+	info, err := lowestSpotPrice("t3.micro", history)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if info.AvailabilityZone != "us-east-1b" {
+		t.Errorf("Expected availability zone us-east-1b, got %s", info.AvailabilityZone)
+	}
+
+	if info.HourlyPrice != 0.0035 {
+		t.Errorf("Expected hourly price 0.0035, got %f", info.HourlyPrice)
+	}
+
+	if info.InstanceType != "t3.micro" {
+		t.Errorf("Expected instance type t3.micro, got %s", info.InstanceType)
+	}
+}
+
+func TestLowestSpotPrice_NoHistory(t *testing.T) {
+	_, err := lowestSpotPrice("t3.micro", nil)
+	if err == nil {
+		t.Fatal("Expected error for empty spot price history, got nil")
+	}
+}
@@ -2,12 +2,25 @@ package aws
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/pem"
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/clouddley/clouddley/internal/log"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
 )
 
 // SSHKeyInfo represents SSH key information
@@ -16,7 +29,12 @@ type SSHKeyInfo struct {
 	Type string // "rsa" or "ed25519"
 }
 
-// CheckLocalSSHKeys checks for existing SSH keys and returns available options
+// defaultKeyNames are the importable public keys ssh tries by default, in preference order
+var defaultKeyNames = []string{"id_rsa.pub", "id_ed25519.pub"}
+
+// CheckLocalSSHKeys finds SSH public keys in ~/.ssh, including custom names and
+// IdentityFile entries from ~/.ssh/config, and returns available options. Only RSA
+// and ED25519 keys are returned, since EC2 cannot import other types
 func CheckLocalSSHKeys() ([]SSHKeyInfo, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
@@ -24,27 +42,170 @@ func CheckLocalSSHKeys() ([]SSHKeyInfo, error) {
 	}
 
 	sshDir := filepath.Join(homeDir, ".ssh")
+
+	// Default names first, then any other *.pub, then keys referenced from ssh config
+	var candidates []string
+	for _, name := range defaultKeyNames {
+		candidates = append(candidates, filepath.Join(sshDir, name))
+	}
+
+	others, err := filepath.Glob(filepath.Join(sshDir, "*.pub"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", sshDir, err)
+	}
+	sort.Strings(others)
+	candidates = append(candidates, others...)
+
+	if config, err := os.ReadFile(filepath.Join(sshDir, "config")); err == nil {
+		for _, identity := range parseIdentityFiles(string(config), homeDir) {
+			candidates = append(candidates, identity+".pub")
+		}
+	}
+
 	var keys []SSHKeyInfo
+	seen := make(map[string]bool)
+	for _, path := range candidates {
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		key, _, _, _, err := ssh.ParseAuthorizedKey(content)
+		if err != nil {
+			// Not a public key (e.g. a certificate bundle or a stray file)
+			continue
+		}
+		if !importableKeyAlgorithm(key.Type()) {
+			log.Debug("Skipping SSH key EC2 cannot import", "path", path, "type", keyTypeName(key.Type()))
+			continue
+		}
 
-	// Check for RSA key
-	rsaPath := filepath.Join(sshDir, "id_rsa.pub")
-	if _, err := os.Stat(rsaPath); err == nil {
 		keys = append(keys, SSHKeyInfo{
-			Path: rsaPath,
-			Type: "rsa",
+			Path: path,
+			Type: keyTypeName(key.Type()),
 		})
 	}
 
-	// Check for Ed25519 key
-	ed25519Path := filepath.Join(sshDir, "id_ed25519.pub")
-	if _, err := os.Stat(ed25519Path); err == nil {
-		keys = append(keys, SSHKeyInfo{
-			Path: ed25519Path,
-			Type: "ed25519",
+	return keys, nil
+}
+
+// parseIdentityFiles returns the IdentityFile paths in an ssh config, expanding ~ and %d
+func parseIdentityFiles(config string, homeDir string) []string {
+	var paths []string
+	for _, line := range strings.Split(config, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// Keywords are case-insensitive and may be separated by whitespace or "="
+		fields := strings.FieldsFunc(line, func(r rune) bool {
+			return r == ' ' || r == '\t' || r == '='
 		})
+		if len(fields) < 2 || !strings.EqualFold(fields[0], "IdentityFile") {
+			continue
+		}
+
+		value := strings.TrimSpace(line[len(fields[0]):])
+		value = strings.TrimSpace(strings.TrimPrefix(value, "="))
+		value = strings.Trim(value, `"`)
+		if value == "" || strings.EqualFold(value, "none") {
+			continue
+		}
+
+		value = strings.ReplaceAll(value, "%d", homeDir)
+		if value == "~" || strings.HasPrefix(value, "~/") {
+			value = filepath.Join(homeDir, strings.TrimPrefix(value, "~"))
+		}
+		paths = append(paths, filepath.Clean(value))
 	}
+	return paths
+}
 
-	return keys, nil
+// keyTypeName converts an SSH key algorithm to the short name shown to users
+func keyTypeName(algorithm string) string {
+	switch {
+	case algorithm == ssh.KeyAlgoRSA:
+		return "rsa"
+	case algorithm == ssh.KeyAlgoED25519:
+		return "ed25519"
+	case strings.HasPrefix(algorithm, "ecdsa-"):
+		return "ecdsa"
+	case algorithm == ssh.KeyAlgoSKED25519:
+		return "ed25519-sk"
+	case algorithm == ssh.KeyAlgoSKECDSA256:
+		return "ecdsa-sk"
+	default:
+		return algorithm
+	}
+}
+
+// GenerateED25519Key writes a new ed25519 key pair to path and path.pub. The private
+// key is encrypted when passphrase is not empty. Existing files are never overwritten.
+func GenerateED25519Key(path string, comment string, passphrase []byte) (*SSHKeyInfo, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate ed25519 key: %w", err)
+	}
+
+	var block *pem.Block
+	if len(passphrase) > 0 {
+		block, err = ssh.MarshalPrivateKeyWithPassphrase(privateKey, comment, passphrase)
+	} else {
+		block, err = ssh.MarshalPrivateKey(privateKey, comment)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode private key: %w", err)
+	}
+
+	sshPublicKey, err := ssh.NewPublicKey(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode public key: %w", err)
+	}
+	authorizedKey := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshPublicKey)))
+	if comment != "" {
+		authorizedKey += " " + comment
+	}
+
+	if err := writeNewFile(path, pem.EncodeToMemory(block), 0600); err != nil {
+		return nil, err
+	}
+	if err := writeNewFile(path+".pub", []byte(authorizedKey+"\n"), 0644); err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+
+	return &SSHKeyInfo{Path: path + ".pub", Type: "ed25519"}, nil
+}
+
+// writeNewFile creates path with the given permissions, failing if it already exists
+func writeNewFile(path string, data []byte, perm os.FileMode) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(path)
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	if err := f.Close(); err != nil {
+		os.Remove(path)
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return nil
 }
 
 // ReadSSHPublicKey reads the SSH public key from the given path
@@ -57,32 +218,82 @@ func ReadSSHPublicKey(path string) (string, error) {
 	return string(content), nil
 }
 
-// CheckAWSKeyPair checks if the clouddley-default-key exists in AWS
-func CheckAWSKeyPair(ctx context.Context, client *ec2.Client) (bool, error) {
-	input := &ec2.DescribeKeyPairsInput{
-		KeyNames: []string{"clouddley-default-key"},
+// DefaultKeyPairName is the AWS key pair used when no --key-name is given
+const DefaultKeyPairName = "clouddley-default-key"
+
+// KeyPairInfo describes an EC2 key pair
+type KeyPairInfo struct {
+	Name        string
+	ID          string
+	Type        string
+	Fingerprint string
+	CreatedAt   time.Time
+}
+
+// ListKeyPairs returns all key pairs in the current region sorted by name
+func ListKeyPairs(ctx context.Context, client *ec2.Client) ([]KeyPairInfo, error) {
+	result, err := client.DescribeKeyPairs(ctx, &ec2.DescribeKeyPairsInput{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list key pairs: %w", err)
+	}
+
+	keys := make([]KeyPairInfo, 0, len(result.KeyPairs))
+	for _, kp := range result.KeyPairs {
+		keys = append(keys, toKeyPairInfo(kp))
 	}
 
-	_, err := client.DescribeKeyPairs(ctx, input)
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].Name < keys[j].Name
+	})
+
+	return keys, nil
+}
+
+// GetKeyPair returns the named key pair, or nil if it does not exist
+func GetKeyPair(ctx context.Context, client *ec2.Client, name string) (*KeyPairInfo, error) {
+	result, err := client.DescribeKeyPairs(ctx, &ec2.DescribeKeyPairsInput{
+		KeyNames: []string{name},
+	})
 	if err != nil {
-		// Check if it's a "not found" error by looking at the error message
-		errMsg := fmt.Sprintf("%v", err)
-		if strings.Contains(errMsg, "InvalidKeyPair.NotFound") || 
-		   strings.Contains(errMsg, "does not exist") {
-			// Key pair doesn't exist
-			return false, nil
+		if isKeyPairNotFound(err) {
+			return nil, nil
 		}
-		return false, fmt.Errorf("failed to check key pair: %w", err)
+		return nil, fmt.Errorf("failed to check key pair: %w", err)
 	}
 
-	return true, nil
+	if len(result.KeyPairs) == 0 {
+		return nil, nil
+	}
+
+	info := toKeyPairInfo(result.KeyPairs[0])
+	return &info, nil
+}
+
+// CheckAWSKeyPair checks if the named key pair exists in AWS
+func CheckAWSKeyPair(ctx context.Context, client *ec2.Client, name string) (bool, error) {
+	info, err := GetKeyPair(ctx, client, name)
+	if err != nil {
+		return false, err
+	}
+	return info != nil, nil
 }
 
-// ImportSSHKeyPair imports the SSH public key to AWS
-func ImportSSHKeyPair(ctx context.Context, client *ec2.Client, publicKeyContent string) error {
+// ImportSSHKeyPair imports the SSH public key to AWS under the given name
+func ImportSSHKeyPair(ctx context.Context, client *ec2.Client, name string, publicKeyContent string) error {
 	input := &ec2.ImportKeyPairInput{
-		KeyName:           &[]string{"clouddley-default-key"}[0],
+		KeyName:           aws.String(name),
 		PublicKeyMaterial: []byte(publicKeyContent),
+		TagSpecifications: []types.TagSpecification{
+			{
+				ResourceType: types.ResourceTypeKeyPair,
+				Tags: []types.Tag{
+					{
+						Key:   aws.String("CreatedBy"),
+						Value: aws.String("Clouddley"),
+					},
+				},
+			},
+		},
 	}
 
 	_, err := client.ImportKeyPair(ctx, input)
@@ -92,3 +303,160 @@ func ImportSSHKeyPair(ctx context.Context, client *ec2.Client, publicKeyContent
 
 	return nil
 }
+
+// DeleteKeyPair deletes the named key pair from AWS
+func DeleteKeyPair(ctx context.Context, client *ec2.Client, name string) error {
+	_, err := client.DeleteKeyPair(ctx, &ec2.DeleteKeyPairInput{
+		KeyName: aws.String(name),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete key pair %s: %w", name, err)
+	}
+	return nil
+}
+
+// PublicKeyFingerprint returns the OpenSSH SHA256 fingerprint of an authorized_keys line
+func PublicKeyFingerprint(publicKeyContent string) (string, error) {
+	key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(publicKeyContent))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse SSH public key: %w", err)
+	}
+	return ssh.FingerprintSHA256(key), nil
+}
+
+// ValidateImportableKey checks that a public key parses and is a type EC2 can
+// import, which is RSA or ED25519 only
+func ValidateImportableKey(publicKeyContent string) error {
+	key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(publicKeyContent))
+	if err != nil {
+		return fmt.Errorf("failed to parse SSH public key: %w", err)
+	}
+	if !importableKeyAlgorithm(key.Type()) {
+		return fmt.Errorf("EC2 only imports rsa and ed25519 keys, not %s", keyTypeName(key.Type()))
+	}
+	return nil
+}
+
+func importableKeyAlgorithm(algorithm string) bool {
+	return algorithm == ssh.KeyAlgoRSA || algorithm == ssh.KeyAlgoED25519
+}
+
+// AWSKeyFingerprint returns the fingerprint EC2 reports for an imported public key:
+// the MD5 fingerprint for RSA keys and the padded base64 SHA256 digest otherwise
+func AWSKeyFingerprint(publicKeyContent string) (string, error) {
+	key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(publicKeyContent))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse SSH public key: %w", err)
+	}
+
+	if key.Type() == ssh.KeyAlgoRSA {
+		return strings.TrimPrefix(ssh.FingerprintLegacyMD5(key), "MD5:"), nil
+	}
+
+	sum := sha256.Sum256(key.Marshal())
+	return base64.StdEncoding.EncodeToString(sum[:]), nil
+}
+
+// PublicKeySource is a public key found on this machine and where it came from
+type PublicKeySource struct {
+	Source  string // file path or "ssh-agent (<comment>)"
+	Content string // authorized_keys line
+}
+
+// LocalPublicKeys returns the public keys in ~/.ssh and the identities loaded in ssh-agent
+func LocalPublicKeys() ([]PublicKeySource, error) {
+	localKeys, err := CheckLocalSSHKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	var sources []PublicKeySource
+	for _, key := range localKeys {
+		content, err := ReadSSHPublicKey(key.Path)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, PublicKeySource{Source: key.Path, Content: content})
+	}
+
+	agentKeys, err := AgentPublicKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	return append(sources, agentKeys...), nil
+}
+
+// AgentPublicKeys returns the identities loaded in ssh-agent, or none when no agent is running
+func AgentPublicKeys() ([]PublicKeySource, error) {
+	socket := os.Getenv("SSH_AUTH_SOCK")
+	if socket == "" {
+		return nil, nil
+	}
+
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		// A stale SSH_AUTH_SOCK is common and not worth failing over
+		return nil, nil
+	}
+	defer conn.Close()
+
+	identities, err := agent.NewClient(conn).List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ssh-agent identities: %w", err)
+	}
+
+	sources := make([]PublicKeySource, 0, len(identities))
+	for _, identity := range identities {
+		sources = append(sources, PublicKeySource{
+			Source:  fmt.Sprintf("ssh-agent (%s)", identity.Comment),
+			Content: string(ssh.MarshalAuthorizedKey(identity)),
+		})
+	}
+	return sources, nil
+}
+
+// MatchKeyFingerprint returns the first public key whose EC2 fingerprint equals awsFingerprint
+func MatchKeyFingerprint(awsFingerprint string, keys []PublicKeySource) (PublicKeySource, bool) {
+	for _, key := range keys {
+		fingerprint, err := AWSKeyFingerprint(key.Content)
+		if err != nil {
+			continue
+		}
+		if FingerprintsEqual(fingerprint, awsFingerprint) {
+			return key, true
+		}
+	}
+	return PublicKeySource{}, false
+}
+
+// FingerprintsEqual compares fingerprints ignoring the "SHA256:"/"MD5:" prefixes and base64 padding
+func FingerprintsEqual(a, b string) bool {
+	normalize := func(fp string) string {
+		fp = strings.TrimSpace(fp)
+		fp = strings.TrimPrefix(fp, "SHA256:")
+		fp = strings.TrimPrefix(fp, "MD5:")
+		return strings.TrimRight(fp, "=")
+	}
+	return normalize(a) == normalize(b)
+}
+
+func toKeyPairInfo(kp types.KeyPairInfo) KeyPairInfo {
+	info := KeyPairInfo{
+		Name:        aws.ToString(kp.KeyName),
+		ID:          aws.ToString(kp.KeyPairId),
+		Type:        string(kp.KeyType),
+		Fingerprint: aws.ToString(kp.KeyFingerprint),
+	}
+	if kp.CreateTime != nil {
+		info.CreatedAt = *kp.CreateTime
+	}
+	return info
+}
+
+func isKeyPairNotFound(err error) bool {
+	// Check if it's a "not found" error by looking at the error message
+	errMsg := fmt.Sprintf("%v", err)
+	return strings.Contains(errMsg, "InvalidKeyPair.NotFound") ||
+		strings.Contains(errMsg, "does not exist")
+}
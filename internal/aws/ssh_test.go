@@ -0,0 +1,336 @@
+package aws
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	testED25519Key = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAICmyl5Coa4PmyE2iaauW/gTEXsGHXdExi8Ij0Ak89xnf test"
+	testRSAKey     = "ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAAAgQC0zwgvQdDk33sUVxVJjQqfc0i8s2ur3pXz1ShYjGdJQ80SGi3QIUN9Adbrcpd92b0bnWY+IGrFkSCSyIPSGNSiFkZEVb7brr/mMg04dKb9K3cMw853XExafBPZMyOdQx7rWWPC9VlwzDJhe0DQLhWcg4ho/4XeU9oXHsoDJEqKoQ== test"
+)
+
+func TestPublicKeyFingerprint(t *testing.T) {
+	tests := []struct {
+		name        string
+		key         string
+		expected    string
+		expectError bool
+	}{
+		{"ed25519", testED25519Key, "SHA256:x7M3xfUFb4DumQfhHqibPW0MaBQZ3Rztzjfn63tSQyw", false},
+		{"rsa", testRSAKey, "SHA256:hrb//OQKPzA/fkRHPdMQbSE7kymYEO9bBQTGicrb5HA", false},
+		{"invalid", "not a key", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := PublicKeyFingerprint(tt.key)
+
+			if tt.expectError {
+				if err == nil {
+					t.Fatalf("Expected error, got %s", result)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestAWSKeyFingerprint(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      string
+		expected string
+	}{
+		// EC2 reports MD5 for imported RSA keys
+		{"rsa", testRSAKey, "b0:08:b9:01:90:a4:b8:d2:a4:ba:43:a5:51:4f:57:5e"},
+		// and padded base64 SHA256 for imported ED25519 keys
+		{"ed25519", testED25519Key, "x7M3xfUFb4DumQfhHqibPW0MaBQZ3Rztzjfn63tSQyw="},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := AWSKeyFingerprint(tt.key)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestMatchKeyFingerprint(t *testing.T) {
+	keys := []PublicKeySource{
+		{Source: "/home/alice/.ssh/broken.pub", Content: "garbage"},
+		{Source: "/home/alice/.ssh/id_rsa.pub", Content: testRSAKey},
+		{Source: "ssh-agent (test)", Content: testED25519Key},
+	}
+
+	tests := []struct {
+		name           string
+		fingerprint    string
+		expectedSource string
+		expectMatch    bool
+	}{
+		{"RSA MD5", "b0:08:b9:01:90:a4:b8:d2:a4:ba:43:a5:51:4f:57:5e", "/home/alice/.ssh/id_rsa.pub", true},
+		{"ED25519 padded", "x7M3xfUFb4DumQfhHqibPW0MaBQZ3Rztzjfn63tSQyw=", "ssh-agent (test)", true},
+		{"ED25519 OpenSSH format", "SHA256:x7M3xfUFb4DumQfhHqibPW0MaBQZ3Rztzjfn63tSQyw", "ssh-agent (test)", true},
+		{"teammate key", "00:11:22:33:44:55:66:77:88:99:aa:bb:cc:dd:ee:ff", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, ok := MatchKeyFingerprint(tt.fingerprint, keys)
+			if ok != tt.expectMatch {
+				t.Fatalf("Expected match=%v, got %v", tt.expectMatch, ok)
+			}
+			if match.Source != tt.expectedSource {
+				t.Errorf("Expected source %q, got %q", tt.expectedSource, match.Source)
+			}
+		})
+	}
+}
+
+func TestParseIdentityFiles(t *testing.T) {
+	config := `# personal
+Host github.com
+    IdentityFile ~/.ssh/github_ed25519
+Host work-*
+    identityfile=%d/.ssh/work_key
+    IdentityFile "/opt/keys/deploy key"
+Host legacy
+    IdentityFile none
+    # IdentityFile ~/.ssh/commented
+`
+
+	expected := []string{
+		"/home/alice/.ssh/github_ed25519",
+		"/home/alice/.ssh/work_key",
+		"/opt/keys/deploy key",
+	}
+
+	result := parseIdentityFiles(config, "/home/alice")
+
+	if len(result) != len(expected) {
+		t.Fatalf("Expected %d paths, got %d: %v", len(expected), len(result), result)
+	}
+	for i := range expected {
+		if result[i] != expected[i] {
+			t.Errorf("Expected %s, got %s", expected[i], result[i])
+		}
+	}
+}
+
+func TestKeyTypeName(t *testing.T) {
+	tests := []struct {
+		algorithm string
+		expected  string
+	}{
+		{"ssh-rsa", "rsa"},
+		{"ssh-ed25519", "ed25519"},
+		{"ecdsa-sha2-nistp256", "ecdsa"},
+		{"ecdsa-sha2-nistp521", "ecdsa"},
+		{"sk-ssh-ed25519@openssh.com", "ed25519-sk"},
+		{"ssh-dss", "ssh-dss"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.algorithm, func(t *testing.T) {
+			if result := keyTypeName(tt.algorithm); result != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestCheckLocalSSHKeys(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	sshDir := filepath.Join(home, ".ssh")
+	keysDir := filepath.Join(home, "keys")
+	for _, dir := range []string{sshDir, keysDir} {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files := map[string]string{
+		filepath.Join(sshDir, "id_ed25519.pub"): testED25519Key,
+		filepath.Join(sshDir, "custom.pub"):     testRSAKey,
+		filepath.Join(sshDir, "notes.pub"):      "not a key",
+		filepath.Join(sshDir, "id_ecdsa.pub"):   testECDSAKey(t),
+		filepath.Join(keysDir, "deploy.pub"):    testED25519Key,
+		filepath.Join(sshDir, "config"):         "Host *\n  IdentityFile ~/keys/deploy\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	keys, err := CheckLocalSSHKeys()
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expected := []SSHKeyInfo{
+		{Path: filepath.Join(sshDir, "id_ed25519.pub"), Type: "ed25519"},
+		{Path: filepath.Join(sshDir, "custom.pub"), Type: "rsa"},
+		{Path: filepath.Join(keysDir, "deploy.pub"), Type: "ed25519"},
+	}
+
+	if len(keys) != len(expected) {
+		t.Fatalf("Expected %d keys, got %d: %+v", len(expected), len(keys), keys)
+	}
+	for i := range expected {
+		if keys[i] != expected[i] {
+			t.Errorf("Expected %+v, got %+v", expected[i], keys[i])
+		}
+	}
+}
+
+func TestGenerateED25519Key(t *testing.T) {
+	t.Run("without passphrase", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), ".ssh", "id_ed25519")
+
+		key, err := GenerateED25519Key(path, "alice@laptop", nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		if key.Path != path+".pub" || key.Type != "ed25519" {
+			t.Errorf("Unexpected key info: %+v", key)
+		}
+
+		assertMode(t, filepath.Dir(path), 0700)
+		assertMode(t, path, 0600)
+		assertMode(t, path+".pub", 0644)
+
+		privateKey, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signer, err := ssh.ParsePrivateKey(privateKey)
+		if err != nil {
+			t.Fatalf("Expected a valid private key, got: %v", err)
+		}
+
+		publicKey, err := os.ReadFile(path + ".pub")
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed, comment, _, _, err := ssh.ParseAuthorizedKey(publicKey)
+		if err != nil {
+			t.Fatalf("Expected a valid public key, got: %v", err)
+		}
+		if comment != "alice@laptop" {
+			t.Errorf("Expected comment alice@laptop, got %q", comment)
+		}
+		if ssh.FingerprintSHA256(parsed) != ssh.FingerprintSHA256(signer.PublicKey()) {
+			t.Error("Public key does not match private key")
+		}
+	})
+
+	t.Run("with passphrase", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "id_ed25519")
+
+		if _, err := GenerateED25519Key(path, "", []byte("secret")); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		privateKey, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var missing *ssh.PassphraseMissingError
+		if _, err := ssh.ParsePrivateKey(privateKey); !errors.As(err, &missing) {
+			t.Errorf("Expected an encrypted key, got: %v", err)
+		}
+		if _, err := ssh.ParsePrivateKeyWithPassphrase(privateKey, []byte("secret")); err != nil {
+			t.Errorf("Expected key to decrypt, got: %v", err)
+		}
+	})
+
+	t.Run("refuses to overwrite", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "id_ed25519")
+		if err := os.WriteFile(path, []byte("existing"), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := GenerateED25519Key(path, "", nil); err == nil {
+			t.Fatal("Expected error when the key already exists")
+		}
+
+		content, _ := os.ReadFile(path)
+		if string(content) != "existing" {
+			t.Error("Existing key was modified")
+		}
+	})
+}
+
+func assertMode(t *testing.T, path string, expected os.FileMode) {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != expected {
+		t.Errorf("Expected %s to have mode %o, got %o", path, expected, info.Mode().Perm())
+	}
+}
+
+// testECDSAKey returns a new ECDSA authorized_keys line, a type EC2 cannot import
+func testECDSAKey(t *testing.T) string {
+	t.Helper()
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecdsaPublic, err := ssh.NewPublicKey(&ecdsaKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(ssh.MarshalAuthorizedKey(ecdsaPublic))
+}
+
+func TestValidateImportableKey(t *testing.T) {
+	tests := []struct {
+		name        string
+		key         string
+		expectError bool
+	}{
+		{"rsa", testRSAKey, false},
+		{"ed25519", testED25519Key, false},
+		{"ecdsa", testECDSAKey(t), true},
+		{"invalid", "not a key", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateImportableKey(tt.key)
+			if (err != nil) != tt.expectError {
+				t.Errorf("Expected error=%v, got: %v", tt.expectError, err)
+			}
+		})
+	}
+}
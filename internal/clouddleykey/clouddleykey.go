@@ -0,0 +1,189 @@
+// Package clouddleykey owns the SSH public keys the Clouddley Platform uses to reach
+// servers, their fingerprints and their rotation schedule.
+package clouddleykey
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// URLEnv overrides the URL the current key is fetched from
+const URLEnv = "CLOUDDLEY_KEY_URL"
+
+// maxFetchSize bounds the response read from the key URL
+const maxFetchSize = 64 * 1024
+
+// Key is a Clouddley public key and the window in which servers should trust it.
+// A zero NotBefore or NotAfter leaves that side of the window open.
+type Key struct {
+	PublicKey string
+	NotBefore time.Time
+	NotAfter  time.Time
+}
+
+// keys lists every Clouddley key, oldest first. To rotate, add the new key with a
+// NotBefore and give the old one a NotAfter so both are trusted during the overlap.
+var keys = []Key{
+	{
+		PublicKey: `ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAACAQCYDppnNM+F+GtFWaVJXsvobX/i/2uZuLch9386ZEyVtGE1QmRjRkvwEHwFM23STuzbtmqTrYjEnmv3Xkywk7wE0r+OoJxTBIwJP+scg9rAu//3N6CoAKH0Ra1XgdRj8QzqF/1mm4T/Pxtzz3JSpSKwpzW3GtU4NcHuaPAAHavCpahCnZqpPMU90FgRCS9lSmw0EPQcU8kxxeEpFjifip4JBBx/WQuh/8KkBAX/DnWSAO9ynGzPMvOvWPTtQMi7IA7Y8vRWeThfpC/fnU8Tub+99w5h2Y1TnWtUrM49ZMa9WSLtP/+4xKieQPObq0JuX6itNFuuwbb/WHLgOYeZqQTdSeMc6GlSkqniYiAUAv7olBUERHf7QkD7hPOlaw9S/0MCU8DcuujZG2i6UvIkQ60dikvsX8rCiPvfN4Nw1mWh0a1rf9vUxTyCCb+7hh1iPV6RwMx6T4nBjFNjBglHFkYIE5kevLyX2vREJJen+GfZO2GVcnHaNRHBvXZVVEbwt1xRWhAOS+FFtcKUNV+54JsKTaZUEYvfwe/KNjEeOxucljkiK9IYw0IGXB9dtueOTKcirLhpGE9t6LqDhWE05kr0fl/hmnT/g9fHeZDm4jOF71iHogsrZtU5pH8QtTNhaffMkW4EJc+4W0a+boE+/S5Xracbr7D1WBhGC2epXkUWHw== "clouddley-triggr-public-key"`,
+	},
+}
+
+// upcomingFingerprints pins keys that may be published at the key URL before a
+// release embeds them
+var upcomingFingerprints = []string{}
+
+// ActiveAt reports whether the key should be trusted at t
+func (k Key) ActiveAt(t time.Time) bool {
+	if !k.NotBefore.IsZero() && t.Before(k.NotBefore) {
+		return false
+	}
+	if !k.NotAfter.IsZero() && !t.Before(k.NotAfter) {
+		return false
+	}
+	return true
+}
+
+// Fingerprint returns the SHA256 fingerprint of the key
+func (k Key) Fingerprint() string {
+	fingerprint, err := Fingerprint(k.PublicKey)
+	if err != nil {
+		// Embedded keys are checked by the package tests
+		panic(fmt.Sprintf("invalid embedded Clouddley key: %v", err))
+	}
+	return fingerprint
+}
+
+// Active returns the keys trusted at t, oldest first
+func Active(t time.Time) []Key {
+	var active []Key
+	for _, key := range keys {
+		if key.ActiveAt(t) {
+			active = append(active, key)
+		}
+	}
+	return active
+}
+
+// PublicKeys returns the authorized_keys lines of the keys trusted now
+func PublicKeys() []string {
+	var lines []string
+	for _, key := range Active(time.Now()) {
+		lines = append(lines, key.PublicKey)
+	}
+	return lines
+}
+
+// PublicKey returns the newest key trusted now
+func PublicKey() string {
+	active := PublicKeys()
+	if len(active) == 0 {
+		// Never leave callers without a key because of a stale rotation schedule
+		return keys[len(keys)-1].PublicKey
+	}
+	return active[len(active)-1]
+}
+
+// Fingerprints returns the fingerprints of every known key, including expired ones,
+// so that revoking access also removes keys that have since been rotated out
+func Fingerprints() []string {
+	fingerprints := make([]string, len(keys))
+	for i, key := range keys {
+		fingerprints[i] = key.Fingerprint()
+	}
+	return fingerprints
+}
+
+// Fingerprint returns the SHA256 fingerprint of an authorized_keys line
+func Fingerprint(publicKey string) (string, error) {
+	key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(publicKey))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse SSH public key: %w", err)
+	}
+	return ssh.FingerprintSHA256(key), nil
+}
+
+// pinned reports whether the fingerprint belongs to an embedded or upcoming key
+func pinned(fingerprint string) bool {
+	for _, fp := range append(Fingerprints(), upcomingFingerprints...) {
+		if fp == fingerprint {
+			return true
+		}
+	}
+	return false
+}
+
+// Fetch downloads the current key from an HTTPS URL serving authorized_keys lines and
+// returns the last line whose fingerprint is pinned. Unpinned keys are rejected so a
+// compromised or spoofed endpoint cannot grant access to anyone else.
+func Fetch(ctx context.Context, keyURL string) (string, error) {
+	parsed, err := url.Parse(keyURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid key URL %q: %w", keyURL, err)
+	}
+	if parsed.Scheme != "https" {
+		return "", fmt.Errorf("key URL must use https, got %q", keyURL)
+	}
+
+	return fetch(ctx, keyURL, http.DefaultClient)
+}
+
+func fetch(ctx context.Context, keyURL string, client *http.Client) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, keyURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch Clouddley key: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to fetch Clouddley key: %s", resp.Status)
+	}
+
+	var found string
+	var rejected []string
+	scanner := bufio.NewScanner(io.LimitReader(resp.Body, maxFetchSize))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fingerprint, err := Fingerprint(line)
+		if err != nil {
+			continue
+		}
+		if !pinned(fingerprint) {
+			rejected = append(rejected, fingerprint)
+			continue
+		}
+		found = line
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read Clouddley key: %w", err)
+	}
+
+	if found == "" {
+		if len(rejected) > 0 {
+			return "", fmt.Errorf("no pinned Clouddley key at %s (rejected %s)", keyURL, strings.Join(rejected, ", "))
+		}
+		return "", fmt.Errorf("no SSH public key found at %s", keyURL)
+	}
+
+	return found, nil
+}
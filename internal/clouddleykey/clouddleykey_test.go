@@ -0,0 +1,139 @@
+package clouddleykey
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	oldKey = "ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAAAgQC0zwgvQdDk33sUVxVJjQqfc0i8s2ur3pXz1ShYjGdJQ80SGi3QIUN9Adbrcpd92b0bnWY+IGrFkSCSyIPSGNSiFkZEVb7brr/mMg04dKb9K3cMw853XExafBPZMyOdQx7rWWPC9VlwzDJhe0DQLhWcg4ho/4XeU9oXHsoDJEqKoQ== old"
+	newKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAICmyl5Coa4PmyE2iaauW/gTEXsGHXdExi8Ij0Ak89xnf new"
+)
+
+// withKeys swaps the embedded key list for the duration of a test
+func withKeys(t *testing.T, replacement []Key, upcoming []string) {
+	t.Helper()
+	originalKeys, originalUpcoming := keys, upcomingFingerprints
+	keys, upcomingFingerprints = replacement, upcoming
+	t.Cleanup(func() {
+		keys, upcomingFingerprints = originalKeys, originalUpcoming
+	})
+}
+
+func TestEmbeddedKeys(t *testing.T) {
+	if len(keys) == 0 {
+		t.Fatal("Expected at least one embedded key")
+	}
+
+	for _, key := range keys {
+		fingerprint, err := Fingerprint(key.PublicKey)
+		if err != nil {
+			t.Fatalf("Embedded key does not parse: %v", err)
+		}
+		if !strings.HasPrefix(fingerprint, "SHA256:") {
+			t.Errorf("Unexpected fingerprint %s", fingerprint)
+		}
+	}
+
+	if PublicKey() == "" {
+		t.Error("Expected a current public key")
+	}
+}
+
+func TestRotation(t *testing.T) {
+	cutover := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	withKeys(t, []Key{
+		{PublicKey: oldKey, NotAfter: cutover.Add(30 * 24 * time.Hour)},
+		{PublicKey: newKey, NotBefore: cutover},
+	}, nil)
+
+	tests := []struct {
+		name     string
+		at       time.Time
+		expected []string
+	}{
+		{"before rotation", cutover.Add(-time.Hour), []string{oldKey}},
+		{"during overlap", cutover.Add(time.Hour), []string{oldKey, newKey}},
+		{"after old key expires", cutover.Add(31 * 24 * time.Hour), []string{newKey}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			active := Active(tt.at)
+			if len(active) != len(tt.expected) {
+				t.Fatalf("Expected %d active keys, got %d", len(tt.expected), len(active))
+			}
+			for i, key := range active {
+				if key.PublicKey != tt.expected[i] {
+					t.Errorf("Expected %s, got %s", tt.expected[i], key.PublicKey)
+				}
+			}
+		})
+	}
+
+	// Revocation must cover rotated-out keys as well
+	if len(Fingerprints()) != 2 {
+		t.Errorf("Expected fingerprints for both keys, got %v", Fingerprints())
+	}
+}
+
+func TestFetch(t *testing.T) {
+	newFingerprint, err := Fingerprint(newKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	withKeys(t, []Key{{PublicKey: oldKey}}, []string{newFingerprint})
+
+	tests := []struct {
+		name        string
+		body        string
+		status      int
+		expected    string
+		expectError bool
+	}{
+		{"pinned upcoming key", "# current key\n" + newKey + "\n", http.StatusOK, newKey, false},
+		{"embedded key", oldKey + "\n", http.StatusOK, oldKey, false},
+		{"unpinned key", "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIAIL+wJQmUbtVehgqiOExzdvL7MvvvSZW7OIOQiXhqX2 attacker\n", http.StatusOK, "", true},
+		{"not found", "", http.StatusNotFound, "", true},
+		{"no keys", "<html></html>", http.StatusOK, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			result, err := fetch(context.Background(), server.URL, server.Client())
+
+			if tt.expectError {
+				if err == nil {
+					t.Fatalf("Expected error, got %s", result)
+				}
+				if tt.name == "unpinned key" && !strings.Contains(err.Error(), "rejected") {
+					t.Errorf("Expected the unpinned key to be rejected, got: %v", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestFetch_RequiresHTTPS(t *testing.T) {
+	if _, err := Fetch(context.Background(), "http://example.com/key.pub"); err == nil {
+		t.Fatal("Expected error for plain HTTP URL")
+	}
+}
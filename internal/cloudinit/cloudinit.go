@@ -0,0 +1,174 @@
+package cloudinit
+
+import (
+	"bytes"
+	"embed"
+	"encoding/base64"
+	"fmt"
+	"mime/multipart"
+	"net/textproto"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// MaxUserDataSize is the EC2 limit for user data in raw form, before base64 encoding
+const MaxUserDataSize = 16 * 1024
+
+// TemplateNone renders no software, only the optional Clouddley key
+const TemplateNone = "none"
+
+//go:embed templates/*.yaml
+var templateFS embed.FS
+
+// Options controls how a cloud-init document is rendered
+type Options struct {
+	Template      string   // one of Templates()
+	UserData      []byte   // optional user supplied user data (cloud-config or shell script)
+	ClouddleyKeys []string // public keys added to the default user's authorized_keys at boot
+}
+
+// Templates returns the names of the built-in templates
+func Templates() []string {
+	entries, err := templateFS.ReadDir("templates")
+	if err != nil {
+		return nil
+	}
+
+	var names []string
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".yaml"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsValidTemplate reports whether name is a built-in template
+func IsValidTemplate(name string) bool {
+	for _, t := range Templates() {
+		if t == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Render builds the user data document for the given options. When user data is
+// supplied it is combined with the template as a MIME multi-part archive so both
+// are processed by cloud-init. An empty result means no user data is needed.
+func Render(opts Options) ([]byte, error) {
+	if opts.Template == "" {
+		opts.Template = TemplateNone
+	}
+	if !IsValidTemplate(opts.Template) {
+		return nil, fmt.Errorf("unknown template %q (available: %s)", opts.Template, strings.Join(Templates(), ", "))
+	}
+
+	if opts.Template == TemplateNone && len(opts.ClouddleyKeys) == 0 && len(opts.UserData) == 0 {
+		return nil, nil
+	}
+
+	cloudConfig, err := renderTemplate(opts.Template, opts.ClouddleyKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(opts.UserData) == 0 {
+		return cloudConfig, nil
+	}
+
+	userContentType, err := detectContentType(opts.UserData)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildMultipart([]part{
+		{contentType: "text/cloud-config", filename: "clouddley.cfg", body: cloudConfig},
+		{contentType: userContentType, filename: "user-data", body: opts.UserData},
+	})
+}
+
+// Encode validates the size of the user data and returns it base64 encoded as
+// expected by RunInstances
+func Encode(userData []byte) (string, error) {
+	if len(userData) > MaxUserDataSize {
+		return "", fmt.Errorf("user data is %d bytes, which exceeds the EC2 limit of %d bytes", len(userData), MaxUserDataSize)
+	}
+	return base64.StdEncoding.EncodeToString(userData), nil
+}
+
+func renderTemplate(name string, clouddleyKeys []string) ([]byte, error) {
+	tmpl, err := template.ParseFS(templateFS, "templates/"+name+".yaml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template %s: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	data := struct{ ClouddleyKeys []string }{ClouddleyKeys: clouddleyKeys}
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render template %s: %w", name, err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// detectContentType maps the first line of a user data document to its cloud-init MIME type
+func detectContentType(userData []byte) (string, error) {
+	firstLine := strings.TrimSpace(strings.SplitN(string(userData), "\n", 2)[0])
+
+	switch {
+	case strings.HasPrefix(firstLine, "#cloud-config"):
+		return "text/cloud-config", nil
+	case strings.HasPrefix(firstLine, "#!"):
+		return "text/x-shellscript", nil
+	case strings.HasPrefix(firstLine, "#include"):
+		return "text/x-include-url", nil
+	case strings.HasPrefix(firstLine, "#cloud-boothook"):
+		return "text/cloud-boothook", nil
+	default:
+		return "", fmt.Errorf("unsupported user data format: must start with #cloud-config, #!, #include or #cloud-boothook")
+	}
+}
+
+// mergeType makes cloud-init append lists and keep existing keys when it merges a
+// part into the configuration so far. The default, dict(replace), would let a user
+// cloud-config setting runcmd or ssh_authorized_keys drop the template's entries
+const mergeType = "list(append)+dict(no_replace,recurse_list)+str()"
+
+type part struct {
+	contentType string
+	filename    string
+	body        []byte
+}
+
+func buildMultipart(parts []part) ([]byte, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	for _, p := range parts {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", fmt.Sprintf("%s; charset=\"utf-8\"", p.contentType))
+		header.Set("MIME-Version", "1.0")
+		header.Set("Content-Transfer-Encoding", "7bit")
+		header.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", p.filename))
+		header.Set("Merge-Type", mergeType)
+
+		w, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create user data part: %w", err)
+		}
+		if _, err := w.Write(p.body); err != nil {
+			return nil, fmt.Errorf("failed to write user data part: %w", err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finalize user data: %w", err)
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "Content-Type: multipart/mixed; boundary=%q\n", writer.Boundary())
+	out.WriteString("MIME-Version: 1.0\n\n")
+	out.Write(body.Bytes())
+	return out.Bytes(), nil
+}
@@ -0,0 +1,219 @@
+package cloudinit
+
+import (
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"strings"
+	"testing"
+)
+
+const testKey = `ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQC7 "clouddley-triggr-public-key"`
+
+func TestTemplates(t *testing.T) {
+	expected := []string{"caddy", "docker", "none", "triggr"}
+	templates := Templates()
+
+	if len(templates) != len(expected) {
+		t.Fatalf("Expected %d templates, got %d: %v", len(expected), len(templates), templates)
+	}
+
+	for i, name := range expected {
+		if templates[i] != name {
+			t.Errorf("Expected template %s at index %d, got %s", name, i, templates[i])
+		}
+	}
+}
+
+func TestRender_Templates(t *testing.T) {
+	tests := []struct {
+		template string
+		contains []string
+	}{
+		{"none", []string{"#cloud-config", "ssh_authorized_keys", testKey}},
+		{"docker", []string{"#cloud-config", "get.docker.com", testKey}},
+		{"triggr", []string{"get.docker.com", "docker swarm init", testKey}},
+		{"caddy", []string{"apt-get install -y caddy", testKey}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			out, err := Render(Options{Template: tt.template, ClouddleyKeys: []string{testKey}})
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			for _, s := range tt.contains {
+				if !strings.Contains(string(out), s) {
+					t.Errorf("Expected rendered template to contain %q, got:\n%s", s, out)
+				}
+			}
+		})
+	}
+}
+
+func TestRender_TriggrDoesNotInstallCLI(t *testing.T) {
+	out, err := Render(Options{Template: "triggr"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if strings.Contains(string(out), "install.sh") {
+		t.Errorf("Expected triggr template not to run an unpinned install script, got:\n%s", out)
+	}
+}
+
+func TestRender_NoneWithoutKeyIsEmpty(t *testing.T) {
+	out, err := Render(Options{Template: TemplateNone})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(out) != 0 {
+		t.Errorf("Expected empty user data, got: %s", out)
+	}
+}
+
+func TestRender_UnknownTemplate(t *testing.T) {
+	_, err := Render(Options{Template: "kubernetes"})
+	if err == nil {
+		t.Fatal("Expected error for unknown template, got nil")
+	}
+
+	if !strings.Contains(err.Error(), "unknown template") {
+		t.Errorf("Expected 'unknown template' error, got: %v", err)
+	}
+}
+
+func TestRender_WithUserData(t *testing.T) {
+	tests := []struct {
+		name        string
+		userData    string
+		contentType string
+		expectError bool
+	}{
+		{"Shell script", "#!/bin/bash\necho hello\n", "text/x-shellscript", false},
+		{"Cloud config", "#cloud-config\npackages:\n  - htop\n", "text/cloud-config", false},
+		{"Unsupported", "echo hello\n", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := Render(Options{
+				Template:      "docker",
+				UserData:      []byte(tt.userData),
+				ClouddleyKeys: []string{testKey},
+			})
+
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("Expected error, got nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			doc := string(out)
+			if !strings.HasPrefix(doc, "Content-Type: multipart/mixed") {
+				t.Errorf("Expected multipart document, got:\n%s", doc)
+			}
+			if !strings.Contains(doc, tt.contentType) {
+				t.Errorf("Expected content type %s in document", tt.contentType)
+			}
+			if !strings.Contains(doc, tt.userData) {
+				t.Error("Expected user data to be included in document")
+			}
+			if !strings.Contains(doc, testKey) {
+				t.Error("Expected Clouddley key to be included in document")
+			}
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	data := []byte("#cloud-config\n")
+
+	encoded, err := Encode(data)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		t.Fatalf("Expected valid base64, got: %v", err)
+	}
+
+	if string(decoded) != string(data) {
+		t.Errorf("Expected %q, got %q", data, decoded)
+	}
+}
+
+func TestEncode_TooLarge(t *testing.T) {
+	data := make([]byte, MaxUserDataSize+1)
+
+	_, err := Encode(data)
+	if err == nil {
+		t.Fatal("Expected error for oversized user data, got nil")
+	}
+
+	if !strings.Contains(err.Error(), "exceeds the EC2 limit") {
+		t.Errorf("Expected size limit error, got: %v", err)
+	}
+}
+
+func TestRender_UserCloudConfigMergesWithTemplate(t *testing.T) {
+	userData := "#cloud-config\nruncmd:\n  - echo user\nssh_authorized_keys:\n  - ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIUser user@laptop\n"
+
+	out, err := Render(Options{
+		Template:      "triggr",
+		UserData:      []byte(userData),
+		ClouddleyKeys: []string{testKey},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	header, body, found := strings.Cut(string(out), "\n\n")
+	if !found {
+		t.Fatalf("Expected a MIME header, got:\n%s", out)
+	}
+	_, params, err := mime.ParseMediaType(strings.TrimPrefix(strings.SplitN(header, "\n", 2)[0], "Content-Type: "))
+	if err != nil {
+		t.Fatalf("Failed to parse content type: %v", err)
+	}
+
+	reader := multipart.NewReader(strings.NewReader(body), params["boundary"])
+	var parts []string
+	for {
+		p, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Failed to read part: %v", err)
+		}
+		if got := p.Header.Get("Merge-Type"); got != mergeType {
+			t.Errorf("Part %s has Merge-Type %q, want %q", p.FileName(), got, mergeType)
+		}
+		content, err := io.ReadAll(p)
+		if err != nil {
+			t.Fatalf("Failed to read part: %v", err)
+		}
+		parts = append(parts, string(content))
+	}
+
+	if len(parts) != 2 {
+		t.Fatalf("Expected 2 parts, got %d", len(parts))
+	}
+	// Both runcmd lists reach cloud-init, which appends the user's to the template's
+	if !strings.Contains(parts[0], "runcmd:") || !strings.Contains(parts[0], "docker swarm init") || !strings.Contains(parts[0], testKey) {
+		t.Errorf("Expected the template part to keep its runcmd and key, got:\n%s", parts[0])
+	}
+	if parts[1] != userData {
+		t.Errorf("Expected the user part unchanged, got:\n%s", parts[1])
+	}
+}
@@ -40,7 +40,10 @@ type InstanceType struct {
 	VCPUs       string
 	Memory      string
 	Disk        string
+	HourlyCost  string
 	MonthlyCost string
+	SpotCost    string // empty when spot pricing was not requested
+	Estimated   bool   // MonthlyCost comes from the offline pricing snapshot
 }
 
 // EnvironmentModel for selecting development vs production
@@ -124,7 +127,20 @@ func NewInstanceSelectionModel(instances []InstanceType, title string) InstanceS
 		{Title: "vCPUs", Width: 8},
 		{Title: "Memory", Width: 10},
 		{Title: "Disk", Width: 10},
-		{Title: "Monthly Cost", Width: 15},
+		{Title: "Compute/hr", Width: 18},
+		{Title: "Monthly Cost", Width: 18},
+	}
+
+	// Only show the spot column when spot prices were fetched
+	showSpot := false
+	for _, instance := range instances {
+		if instance.SpotCost != "" {
+			showSpot = true
+			break
+		}
+	}
+	if showSpot {
+		columns = append(columns, table.Column{Title: "Spot (est.)", Width: 15})
 	}
 
 	rows := make([]table.Row, len(instances))
@@ -134,8 +150,12 @@ func NewInstanceSelectionModel(instances []InstanceType, title string) InstanceS
 			instance.VCPUs,
 			instance.Memory,
 			instance.Disk,
+			instance.HourlyCost,
 			instance.MonthlyCost,
 		}
+		if showSpot {
+			rows[i] = append(rows[i], instance.SpotCost)
+		}
 	}
 
 	t := table.New(
@@ -194,24 +214,27 @@ func (m InstanceSelectionModel) Selected() int {
 	return -1
 }
 
-// SSHKeySelectionModel for selecting SSH keys
-type SSHKeySelectionModel struct {
+// ListSelectionModel for selecting one item from a titled list
+type ListSelectionModel struct {
+	title    string
 	choices  []string
 	cursor   int
 	selected bool
 }
 
-func NewSSHKeySelectionModel(keyTypes []string) SSHKeySelectionModel {
-	return SSHKeySelectionModel{
-		choices: keyTypes,
+// NewListSelectionModel creates a list selection model with the given title
+func NewListSelectionModel(title string, choices []string) ListSelectionModel {
+	return ListSelectionModel{
+		title:   title,
+		choices: choices,
 	}
 }
 
-func (m SSHKeySelectionModel) Init() tea.Cmd {
+func (m ListSelectionModel) Init() tea.Cmd {
 	return nil
 }
 
-func (m SSHKeySelectionModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+func (m ListSelectionModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch msg.String() {
@@ -234,17 +257,15 @@ func (m SSHKeySelectionModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-func (m SSHKeySelectionModel) View() string {
-	s := titleStyle.Render("Multiple SSH Keys Found - Select One") + "\n\n"
+func (m ListSelectionModel) View() string {
+	s := titleStyle.Render(m.title) + "\n\n"
 
 	for i, choice := range m.choices {
 		cursor := " "
-		text := choice
+		text := normalStyle.Render(choice)
 		if m.cursor == i {
 			cursor = ">"
 			text = selectedStyle.Render(choice)
-		} else {
-			text = normalStyle.Render(choice)
 		}
 
 		s += fmt.Sprintf("%s %s\n", cursor, text)
@@ -254,7 +275,7 @@ func (m SSHKeySelectionModel) View() string {
 	return s
 }
 
-func (m SSHKeySelectionModel) Selected() int {
+func (m ListSelectionModel) Selected() int {
 	if m.selected {
 		return m.cursor
 	}